@@ -0,0 +1,283 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"trading-engine/clock"
+	"trading-engine/config"
+	"trading-engine/engine"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+// Config holds the parameters of a single backtest run
+type Config struct {
+	Symbol          string                  `json:"symbol"`
+	Interval        string                  `json:"interval"`
+	TrendTimeframe  string                  `json:"trendTimeframe,omitempty"` // Aggregated from the candles, empty disables the trend filter
+	StartingBalance float64                 `json:"startingBalance"`
+	FeeRate         float64                 `json:"feeRate"`
+	SlippageBps     float64                 `json:"slippageBps"`
+	Settings        *models.TradingSettings `json:"settings,omitempty"`
+}
+
+// EquityPoint is the account value at the close of a candle
+type EquityPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Equity    float64   `json:"equity"`
+}
+
+// Result holds the trades and performance statistics of a backtest
+type Result struct {
+	Symbol          string                       `json:"symbol"`
+	Interval        string                       `json:"interval"`
+	Start           time.Time                    `json:"start"`
+	End             time.Time                    `json:"end"`
+	Candles         int                          `json:"candles"`
+	StartingBalance float64                      `json:"startingBalance"`
+	FinalEquity     float64                      `json:"finalEquity"`
+	TotalPnL        float64                      `json:"totalPnL"`
+	TotalFees       float64                      `json:"totalFees"`
+	ReturnPct       float64                      `json:"returnPct"`
+	TotalTrades     int                          `json:"totalTrades"`
+	WinningTrades   int                          `json:"winningTrades"`
+	LosingTrades    int                          `json:"losingTrades"`
+	WinRate         float64                      `json:"winRate"`
+	MaxDrawdown     float64                      `json:"maxDrawdown"`
+	SharpeRatio     float64                      `json:"sharpeRatio"`
+	Strategies      []models.StrategyPerformance `json:"strategies"`
+	Trades          []models.Trade               `json:"trades"`
+	EquityCurve     []EquityPoint                `json:"equityCurve"`
+}
+
+// Runner replays historical candles through the trading engine
+type Runner struct {
+	config *config.Config
+	logger *logger.Logger
+}
+
+// NewRunner creates a new backtest runner
+func NewRunner(cfg *config.Config, log *logger.Logger) *Runner {
+	return &Runner{
+		config: cfg,
+		logger: log,
+	}
+}
+
+// Run replays the candles through a paper trading engine on a simulated clock
+func (r *Runner) Run(ctx context.Context, bt *Config, candles []models.Candle) (*Result, error) {
+	if len(candles) < 2 {
+		return nil, fmt.Errorf("at least 2 candles are required, got %d", len(candles))
+	}
+
+	sorted := make([]models.Candle, len(candles))
+	copy(sorted, candles)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	interval := candleInterval(sorted)
+	if interval <= 0 {
+		return nil, fmt.Errorf("could not determine the candle interval")
+	}
+
+	// Run against a paper venue regardless of the configured mode
+	engineCfg := *r.config
+	engineCfg.Trading.Mode = config.TradingModePaper
+	engineCfg.Trading.ExchangeFilters = false // Replays run offline, without exchangeInfo
+
+	// The candles drive the signal timeframe and the trend timeframe is aggregated from them
+	if bt.TrendTimeframe != "" && bt.Interval == "" {
+		return nil, fmt.Errorf("the candle interval is required with a trend timeframe")
+	}
+	if bt.Interval != "" {
+		engineCfg.Trading.KlineInterval = bt.Interval
+	}
+	engineCfg.Trading.Timeframes = []string{engineCfg.Trading.KlineInterval}
+	engineCfg.Trading.TrendTimeframe = bt.TrendTimeframe
+	if bt.StartingBalance > 0 {
+		engineCfg.Trading.Paper.StartingBalance = bt.StartingBalance
+	}
+	if bt.FeeRate > 0 {
+		engineCfg.Trading.Paper.FeeRate = bt.FeeRate
+	}
+	if bt.SlippageBps > 0 {
+		engineCfg.Trading.Paper.SlippageBps = bt.SlippageBps
+	}
+
+	sim := clock.NewSimulated(sorted[0].Timestamp)
+	eng, err := engine.NewEngineWithClock(&engineCfg, r.logger, sim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backtest engine: %w", err)
+	}
+
+	eng.WatchSymbols([]string{bt.Symbol})
+	if bt.Settings != nil {
+		if err := eng.UpdateSettings(*bt.Settings); err != nil {
+			return nil, fmt.Errorf("invalid backtest settings: %w", err)
+		}
+	}
+	if err := eng.StartReplay(ctx); err != nil {
+		return nil, err
+	}
+	eng.EnableTrading()
+
+	r.logger.WithFields(map[string]interface{}{
+		"symbol":   bt.Symbol,
+		"interval": interval.String(),
+		"candles":  len(sorted),
+	}).Info("Starting backtest")
+
+	startingBalance := engineCfg.Trading.Paper.StartingBalance
+	equityCurve := make([]EquityPoint, 0, len(sorted)+1)
+	equityCurve = append(equityCurve, EquityPoint{Timestamp: sorted[0].Timestamp, Equity: startingBalance})
+
+	for _, candle := range sorted {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		candle.Symbol = bt.Symbol
+
+		// Stops and targets are hit inside the candle, not only at its close
+		eng.TriggerExits(candle)
+		eng.ApplyCandle(candle)
+
+		// Decisions are made at the candle close
+		sim.Set(candle.Timestamp.Add(interval))
+		eng.RunCycle(ctx)
+
+		equityCurve = append(equityCurve, EquityPoint{Timestamp: sim.Now(), Equity: equity(eng.GetTradingState())})
+	}
+
+	eng.CloseAllPositions("BACKTEST_END")
+	eng.DisableTrading()
+
+	state := eng.GetTradingState()
+	finalEquity := equity(state)
+	equityCurve[len(equityCurve)-1].Equity = finalEquity
+
+	result := &Result{
+		Symbol:          bt.Symbol,
+		Interval:        bt.Interval,
+		Start:           sorted[0].Timestamp,
+		End:             sim.Now(),
+		Candles:         len(sorted),
+		StartingBalance: startingBalance,
+		FinalEquity:     finalEquity,
+		TotalPnL:        finalEquity - startingBalance,
+		ReturnPct:       (finalEquity - startingBalance) / startingBalance * 100,
+		Strategies:      eng.StrategyPerformance(),
+		Trades:          state.Trades,
+		EquityCurve:     equityCurve,
+		MaxDrawdown:     maxDrawdown(equityCurve),
+		SharpeRatio:     sharpeRatio(equityCurve, interval),
+	}
+	if result.Interval == "" {
+		result.Interval = interval.String()
+	}
+
+	for _, trade := range state.Trades {
+		result.TotalFees += trade.Fee
+		if trade.PnL == nil {
+			continue
+		}
+
+		result.TotalTrades++
+		if *trade.PnL > 0 {
+			result.WinningTrades++
+		} else {
+			result.LosingTrades++
+		}
+	}
+	if result.TotalTrades > 0 {
+		result.WinRate = float64(result.WinningTrades) / float64(result.TotalTrades) * 100
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"symbol":       bt.Symbol,
+		"trades":       result.TotalTrades,
+		"win_rate":     result.WinRate,
+		"total_pnl":    result.TotalPnL,
+		"max_drawdown": result.MaxDrawdown,
+		"sharpe":       result.SharpeRatio,
+	}).Info("Backtest completed")
+
+	return result, nil
+}
+
+// equity values the account as free balance plus committed capital and open P&L
+func equity(state *models.TradingState) float64 {
+	total := state.AvailableBalance
+	for _, position := range state.Positions {
+		total += math.Abs(position.Quantity)*position.AvgBuyPrice + position.UnrealizedPnL
+	}
+	return total
+}
+
+// candleInterval returns the smallest gap between consecutive candles
+func candleInterval(candles []models.Candle) time.Duration {
+	var interval time.Duration
+	for i := 1; i < len(candles); i++ {
+		gap := candles[i].Timestamp.Sub(candles[i-1].Timestamp)
+		if gap > 0 && (interval == 0 || gap < interval) {
+			interval = gap
+		}
+	}
+	return interval
+}
+
+// maxDrawdown returns the largest peak-to-trough decline of the equity curve in percent
+func maxDrawdown(curve []EquityPoint) float64 {
+	var peak, drawdown float64
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if peak > 0 {
+			drawdown = math.Max(drawdown, (peak-point.Equity)/peak*100)
+		}
+	}
+	return drawdown
+}
+
+// sharpeRatio returns the annualized Sharpe ratio of per-candle returns, assuming a zero risk-free rate
+func sharpeRatio(curve []EquityPoint, interval time.Duration) float64 {
+	if len(curve) < 3 {
+		return 0
+	}
+
+	returns := make([]float64, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Equity == 0 {
+			continue
+		}
+		returns = append(returns, (curve[i].Equity-curve[i-1].Equity)/curve[i-1].Equity)
+	}
+
+	var mean float64
+	for _, ret := range returns {
+		mean += ret
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, ret := range returns {
+		variance += math.Pow(ret-mean, 2)
+	}
+	variance /= float64(len(returns))
+
+	if variance == 0 {
+		return 0
+	}
+
+	// Crypto trades around the clock
+	periodsPerYear := float64(365*24*time.Hour) / float64(interval)
+	return mean / math.Sqrt(variance) * math.Sqrt(periodsPerYear)
+}
@@ -0,0 +1,186 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+	"trading-engine/strategy"
+)
+
+// enterOnceName is the test strategy opening a single long on the first candle analyzed
+const enterOnceName = "test-enter-once"
+
+// enterOnce opens one long position and leaves its exits to the engine
+type enterOnce struct {
+	entered bool
+}
+
+func (s *enterOnce) Name() string { return enterOnceName }
+
+func (s *enterOnce) OnCandle(market strategy.Market) []strategy.Intent {
+	if s.entered {
+		return nil
+	}
+	s.entered = true
+	return []strategy.Intent{{Action: strategy.ActionOpenLong, Symbol: market.Symbol, Reason: "TEST"}}
+}
+
+func (s *enterOnce) OnTick(tick strategy.Tick) []strategy.Intent { return nil }
+
+func (s *enterOnce) OnFill(trade models.Trade) []strategy.Intent { return nil }
+
+// newTestRunner creates a runner trading the test strategy with indicators short enough to
+// be analyzed from the third candle, paying a 0.1% fee without slippage
+func newTestRunner(t *testing.T) *Runner {
+	t.Helper()
+
+	strategy.Register(enterOnceName, func() strategy.Strategy { return &enterOnce{} })
+
+	log, err := logger.NewLogger("backtest-test", logger.ERROR, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	cfg := &config.Config{
+		Trading: config.TradingConfig{
+			QuoteAsset:      "USDT",
+			MaxPositions:    5,
+			PositionTimeout: 30,
+			PriceBufferSize: 1000,
+			KlineInterval:   "1m",
+			Strategy:        enterOnceName,
+			Paper:           config.PaperConfig{StartingBalance: 10000, FeeRate: 0.001},
+		},
+	}
+	cfg.Trading.TechnicalPeriods.RSI = 2
+	cfg.Trading.TechnicalPeriods.EMA9 = 2
+	cfg.Trading.TechnicalPeriods.EMA21 = 2
+	cfg.Trading.TechnicalPeriods.EMA50 = 3
+	cfg.Trading.TechnicalPeriods.EMA200 = 3
+
+	return NewRunner(cfg, log)
+}
+
+// testSettings size each entry to 5000 USDT, with the stop 1% and the target 2% away
+var testSettings = models.TradingSettings{
+	MaxPositionSize:   5000,
+	RiskPerTrade:      1,
+	MaxDailyLoss:      1000,
+	MaxPositions:      5,
+	StopLossPercent:   1,
+	TakeProfitPercent: 2,
+	StopMode:          models.StopModePercent,
+	TrailingMode:      models.TrailingModeOff,
+	ScalingFactor:     1,
+	MaxHoldTime:       30,
+}
+
+func TestRunExitsInsideCandle(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candle := func(minute int, open, high, low, closePrice float64) models.Candle {
+		at := start.Add(time.Duration(minute) * time.Minute)
+		return models.Candle{Open: open, High: high, Low: low, Close: closePrice, Volume: 1e6, Timestamp: at, Time: at.Unix()}
+	}
+
+	// 50 units are bought at 100 on the close of the third candle for 5 USDT of fees, the
+	// fourth candle exits, the fifth holds the price
+	tests := []struct {
+		name         string
+		exit         models.Candle
+		wantReason   string
+		wantPrice    float64
+		wantFees     float64
+		wantEquity   float64
+		wantDrawdown float64
+	}{
+		{
+			name:         "stop loss",
+			exit:         candle(3, 100, 100.5, 98.5, 99.5),
+			wantReason:   "STOP_LOSS",
+			wantPrice:    99,
+			wantFees:     9.95,
+			wantEquity:   9940.05,
+			wantDrawdown: 0.5995,
+		},
+		{
+			name:         "take profit",
+			exit:         candle(3, 100, 102.5, 99.5, 102.2),
+			wantReason:   "TAKE_PROFIT",
+			wantPrice:    102,
+			wantFees:     10.1,
+			wantEquity:   10089.9,
+			wantDrawdown: 0.05,
+		},
+		{
+			name:         "stop taken first when both levels are in range",
+			exit:         candle(3, 100, 102.5, 98.5, 102.2),
+			wantReason:   "STOP_LOSS",
+			wantPrice:    99,
+			wantFees:     9.95,
+			wantEquity:   9940.05,
+			wantDrawdown: 0.5995,
+		},
+		{
+			name:         "gap through the stop fills at the open",
+			exit:         candle(3, 97, 97.5, 96, 97),
+			wantReason:   "STOP_LOSS",
+			wantPrice:    97,
+			wantFees:     9.85,
+			wantEquity:   9840.15,
+			wantDrawdown: 1.5985,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := []models.Candle{
+				candle(0, 100, 100, 100, 100),
+				candle(1, 100, 100, 100, 100),
+				candle(2, 100, 100, 100, 100),
+				tt.exit,
+				candle(4, tt.exit.Close, tt.exit.Close, tt.exit.Close, tt.exit.Close),
+			}
+
+			settings := testSettings
+			result, err := newTestRunner(t).Run(context.Background(),
+				&Config{Symbol: "BTCUSDT", Interval: "1m", Settings: &settings}, candles)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if len(result.Trades) != 2 || result.TotalTrades != 1 {
+				t.Fatalf("trades = %+v, want one entry and one exit", result.Trades)
+			}
+			exit := result.Trades[1]
+			if exit.Signal != tt.wantReason || exit.Price != tt.wantPrice || !exit.Timestamp.Equal(tt.exit.Timestamp) {
+				t.Errorf("exit = %s at %v on %s, want %s at %v on %s", exit.Signal, exit.Price, exit.Timestamp,
+					tt.wantReason, tt.wantPrice, tt.exit.Timestamp)
+			}
+
+			if !almostEqual(result.TotalFees, tt.wantFees) {
+				t.Errorf("total fees = %v, want %v", result.TotalFees, tt.wantFees)
+			}
+			if !almostEqual(result.FinalEquity, tt.wantEquity) {
+				t.Errorf("final equity = %v, want %v", result.FinalEquity, tt.wantEquity)
+			}
+			if !almostEqual(result.MaxDrawdown, tt.wantDrawdown) {
+				t.Errorf("max drawdown = %v, want %v", result.MaxDrawdown, tt.wantDrawdown)
+			}
+
+			// The fees are those the P&L was charged
+			if pnl := *exit.PnL - result.TotalFees; !almostEqual(result.TotalPnL, pnl) {
+				t.Errorf("total P&L = %v, want the trade P&L net of fees %v", result.TotalPnL, pnl)
+			}
+		})
+	}
+}
+
+// almostEqual reports whether two amounts match up to rounding
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
@@ -0,0 +1,68 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// LoadCSV reads candles from a CSV file in Binance kline export format:
+// open time (ms), open, high, low, close, volume, followed by optional columns.
+// A header row is skipped if present.
+func LoadCSV(path, symbol string) ([]models.Candle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	candles := make([]models.Candle, 0)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+		if len(record) < 6 {
+			return nil, fmt.Errorf("CSV line %d has %d columns, need at least 6", line, len(record))
+		}
+
+		openTime, err := utils.ParseInt(record[0])
+		if err != nil {
+			if line == 1 {
+				continue // Header row
+			}
+			return nil, fmt.Errorf("invalid open time on CSV line %d: %w", line, err)
+		}
+
+		var values [5]float64
+		for i := range values {
+			if values[i], err = utils.ParseFloat(record[i+1]); err != nil {
+				return nil, fmt.Errorf("invalid value on CSV line %d: %w", line, err)
+			}
+		}
+
+		candles = append(candles, models.Candle{
+			Open:      values[0],
+			High:      values[1],
+			Low:       values[2],
+			Close:     values[3],
+			Volume:    values[4],
+			Time:      openTime / 1000,
+			Timestamp: time.UnixMilli(openTime),
+			Symbol:    symbol,
+		})
+	}
+
+	return candles, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"trading-engine/backtest"
+	"trading-engine/binance"
+	"trading-engine/config"
+	"trading-engine/database"
+	"trading-engine/history"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+// backtestRequest describes a backtest run and where its candles come from
+type backtestRequest struct {
+	backtest.Config
+	CSVPath   string    `json:"-"`
+	Download  bool      `json:"download"`
+	Limit     int       `json:"limit"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// loadBacktestCandles loads candles from a CSV file, the market_data table when a
+// time range is given (backfilled from Binance first if requested), or else the most
+// recent klines from Binance
+func loadBacktestCandles(ctx context.Context, cfg *config.Config, log *logger.Logger, db *database.DB, req *backtestRequest) ([]models.Candle, error) {
+	if req.CSVPath != "" {
+		return backtest.LoadCSV(req.CSVPath, req.Symbol)
+	}
+
+	if !req.StartTime.IsZero() {
+		if db == nil {
+			return nil, fmt.Errorf("a database is required to backtest a time range")
+		}
+		endTime := req.EndTime
+		if endTime.IsZero() {
+			endTime = time.Now()
+		}
+		if req.Download {
+			downloader := history.NewDownloader(binance.NewClient(&cfg.Binance, log), db, log)
+			if _, err := downloader.Download(ctx, req.Symbol, req.Interval, req.StartTime, endTime); err != nil {
+				return nil, fmt.Errorf("failed to download klines: %w", err)
+			}
+		}
+		return db.GetMarketData(req.Symbol, req.Interval, req.StartTime, endTime)
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	return binance.NewClient(&cfg.Binance, log).FetchHistoricalKlines(ctx, req.Symbol, req.Interval, limit)
+}
+
+// runBacktestCommand runs a backtest from the command line and prints the result as JSON
+func runBacktestCommand(args []string) int {
+	flags := flag.NewFlagSet("backtest", flag.ExitOnError)
+	symbol := flags.String("symbol", "BTCUSDT", "symbol to backtest")
+	interval := flags.String("interval", "5m", "kline interval")
+	trend := flags.String("trend", "", "higher timeframe whose trend entries must follow, aggregated from the klines")
+	csvPath := flags.String("csv", "", "CSV file of klines to replay")
+	from := flags.String("from", "", "start of the market_data range to replay (RFC3339)")
+	to := flags.String("to", "", "end of the market_data range to replay (RFC3339)")
+	download := flags.Bool("download", false, "backfill missing candles of the -from/-to range from Binance first")
+	limit := flags.Int("limit", 1000, "number of recent klines to fetch from Binance")
+	balance := flags.Float64("balance", 0, "starting balance (defaults to PAPER_STARTING_BALANCE)")
+	feeRate := flags.Float64("fee-rate", 0, "fee rate per fill (defaults to PAPER_FEE_RATE)")
+	slippage := flags.Float64("slippage-bps", 0, "slippage in basis points (defaults to PAPER_SLIPPAGE_BPS)")
+	out := flags.String("out", "", "file to write the JSON result to (defaults to stdout)")
+	flags.Parse(args)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	log, err := logger.NewLogger("backtest", logger.INFO, "./logs")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return 1
+	}
+	defer log.Close()
+
+	req := &backtestRequest{
+		Config: backtest.Config{
+			Symbol:          *symbol,
+			Interval:        *interval,
+			TrendTimeframe:  *trend,
+			StartingBalance: *balance,
+			FeeRate:         *feeRate,
+			SlippageBps:     *slippage,
+		},
+		CSVPath:  *csvPath,
+		Download: *download,
+		Limit:    *limit,
+	}
+
+	var db *database.DB
+	if *from != "" {
+		if req.StartTime, err = time.Parse(time.RFC3339, *from); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -from time: %v\n", err)
+			return 1
+		}
+		if *to != "" {
+			if req.EndTime, err = time.Parse(time.RFC3339, *to); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid -to time: %v\n", err)
+				return 1
+			}
+		}
+
+		db, err = database.NewDB(&database.Config{
+			Host:     cfg.Database.Host,
+			Port:     cfg.Database.Port,
+			User:     cfg.Database.User,
+			Password: cfg.Database.Password,
+			DBName:   cfg.Database.Name,
+			SSLMode:  cfg.Database.SSLMode,
+		}, log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+			return 1
+		}
+		defer db.Close()
+	}
+
+	ctx := context.Background()
+
+	candles, err := loadBacktestCandles(ctx, cfg, log, db, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load candles: %v\n", err)
+		return 1
+	}
+
+	result, err := backtest.NewRunner(cfg, log).Run(ctx, &req.Config, candles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Backtest failed: %v\n", err)
+		return 1
+	}
+
+	output := os.Stdout
+	if *out != "" {
+		if output, err = os.Create(*out); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer output.Close()
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode result: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
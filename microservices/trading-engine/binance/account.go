@@ -0,0 +1,340 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// errCodeInvalidTimestamp is returned by Binance when the request timestamp
+// falls outside of recvWindow relative to server time
+const errCodeInvalidTimestamp = -1021
+
+// APIError represents an error returned by the Binance REST API
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+	Class      ErrorClass
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: status=%d, code=%d, msg=%s", e.StatusCode, e.Code, e.Message)
+}
+
+// SyncServerTime measures the offset between local and Binance server time
+func (c *Client) SyncServerTime(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.config.APIBaseURL+"/api/v3/time", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create server time request: %w", err)
+	}
+
+	requestTime := time.Now()
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch server time: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	var serverTime struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&serverTime); err != nil {
+		return fmt.Errorf("failed to parse server time: %w", err)
+	}
+
+	// Assume the server stamped the response halfway through the round trip
+	localTime := requestTime.Add(time.Since(requestTime) / 2)
+	offset := time.UnixMilli(serverTime.ServerTime).Sub(localTime)
+
+	c.mu.Lock()
+	c.timeOffset = offset
+	c.mu.Unlock()
+
+	c.logger.WithFields(map[string]interface{}{
+		"offset_ms": offset.Milliseconds(),
+	}).Info("Synchronized Binance server time")
+
+	return nil
+}
+
+// serverTimestamp returns the current time in milliseconds adjusted to server time
+func (c *Client) serverTimestamp() int64 {
+	c.mu.RLock()
+	offset := c.timeOffset
+	c.mu.RUnlock()
+
+	return time.Now().Add(offset).UnixMilli()
+}
+
+// sign returns the HMAC-SHA256 signature of the payload using the secret key
+func (c *Client) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(c.config.SecretKey))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedRequest performs a request against a SIGNED endpoint and returns the response body
+func (c *Client) signedRequest(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
+	body, err := c.request(ctx, method, path, params, true)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == errCodeInvalidTimestamp {
+		// Clock drifted outside recvWindow, resync once and retry
+		if syncErr := c.SyncServerTime(ctx); syncErr != nil {
+			return nil, fmt.Errorf("failed to resync server time: %w", syncErr)
+		}
+		return c.request(ctx, method, path, params, true)
+	}
+	return body, err
+}
+
+// PlaceOrder submits a new order to Binance
+func (c *Client) PlaceOrder(ctx context.Context, order *models.OrderRequest) (*models.Order, error) {
+	params := url.Values{}
+	params.Set("symbol", order.Symbol)
+	params.Set("side", order.Side)
+	params.Set("type", order.Type)
+	params.Set("quantity", strconv.FormatFloat(order.Quantity, 'f', -1, 64))
+	params.Set("newOrderRespType", "FULL")
+
+	if order.Type == "LIMIT" {
+		timeInForce := order.TimeInForce
+		if timeInForce == "" {
+			timeInForce = "GTC"
+		}
+		params.Set("price", strconv.FormatFloat(order.Price, 'f', -1, 64))
+		params.Set("timeInForce", timeInForce)
+	}
+	if order.ClientOrderID != "" {
+		params.Set("newClientOrderId", order.ClientOrderID)
+	}
+
+	// Orders with a side effect borrow or repay on the cross margin account
+	margin := order.SideEffectType != ""
+	if margin {
+		params.Set("sideEffectType", order.SideEffectType)
+	}
+
+	body, err := c.signedRequest(ctx, "POST", orderPath(margin), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place order: %w", err)
+	}
+
+	var resp models.BinanceOrderResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse order response: %w", err)
+	}
+
+	result := convertOrder(&resp)
+	result.SideEffectType = order.SideEffectType
+
+	c.logger.WithFields(map[string]interface{}{
+		"symbol":       result.Symbol,
+		"side":         result.Side,
+		"type":         result.Type,
+		"side_effect":  order.SideEffectType,
+		"order_id":     result.OrderID,
+		"status":       result.Status,
+		"executed_qty": result.ExecutedQty,
+		"avg_price":    result.AvgPrice,
+	}).Info("Order placed on Binance")
+
+	return result, nil
+}
+
+// orderPath returns the order endpoint of the spot or the cross margin account
+func orderPath(margin bool) string {
+	if margin {
+		return "/sapi/v1/margin/order"
+	}
+	return "/api/v3/order"
+}
+
+// CancelOrder cancels an active order on the spot or the cross margin account
+func (c *Client) CancelOrder(ctx context.Context, symbol string, orderID int64, margin bool) (*models.Order, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", strconv.FormatInt(orderID, 10))
+
+	body, err := c.signedRequest(ctx, "DELETE", orderPath(margin), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	var resp models.BinanceOrderResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse cancel response: %w", err)
+	}
+
+	return convertOrder(&resp), nil
+}
+
+// GetOrder queries the current state of an order on the spot or the cross margin account
+func (c *Client) GetOrder(ctx context.Context, symbol string, orderID int64, margin bool) (*models.Order, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", strconv.FormatInt(orderID, 10))
+
+	body, err := c.signedRequest(ctx, "GET", orderPath(margin), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order: %w", err)
+	}
+
+	var resp models.BinanceOrderResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse order response: %w", err)
+	}
+
+	return convertOrder(&resp), nil
+}
+
+// GetOpenOrders returns open orders of the spot or the cross margin account, for all symbols
+// when symbol is empty
+func (c *Client) GetOpenOrders(ctx context.Context, symbol string, margin bool) ([]models.Order, error) {
+	params := url.Values{}
+	if symbol != "" {
+		params.Set("symbol", symbol)
+	}
+
+	path := "/api/v3/openOrders"
+	if margin {
+		path = "/sapi/v1/margin/openOrders"
+	}
+
+	body, err := c.signedRequest(ctx, "GET", path, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+
+	var resp []models.BinanceOrderResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse open orders response: %w", err)
+	}
+
+	orders := make([]models.Order, 0, len(resp))
+	for i := range resp {
+		orders = append(orders, *convertOrder(&resp[i]))
+	}
+
+	return orders, nil
+}
+
+// GetAccountBalances returns all non-zero asset balances of the account
+func (c *Client) GetAccountBalances(ctx context.Context) ([]models.Balance, error) {
+	body, err := c.signedRequest(ctx, "GET", "/api/v3/account", url.Values{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+
+	var resp models.BinanceAccountResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse account response: %w", err)
+	}
+
+	balances := make([]models.Balance, 0)
+	for _, balance := range resp.Balances {
+		free, _ := utils.ParseFloat(balance.Free)
+		locked, _ := utils.ParseFloat(balance.Locked)
+		if free == 0 && locked == 0 {
+			continue
+		}
+
+		balances = append(balances, models.Balance{
+			Asset:  balance.Asset,
+			Free:   free,
+			Locked: locked,
+		})
+	}
+
+	return balances, nil
+}
+
+// GetMarginBalances returns all non-zero asset balances of the cross margin account
+func (c *Client) GetMarginBalances(ctx context.Context) ([]models.Balance, error) {
+	body, err := c.signedRequest(ctx, "GET", "/sapi/v1/margin/account", url.Values{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch margin account: %w", err)
+	}
+
+	var resp models.BinanceMarginAccountResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse margin account response: %w", err)
+	}
+
+	balances := make([]models.Balance, 0)
+	for _, asset := range resp.UserAssets {
+		free, _ := utils.ParseFloat(asset.Free)
+		locked, _ := utils.ParseFloat(asset.Locked)
+		if free == 0 && locked == 0 {
+			continue
+		}
+
+		balances = append(balances, models.Balance{
+			Asset:  asset.Asset,
+			Free:   free,
+			Locked: locked,
+		})
+	}
+
+	return balances, nil
+}
+
+// convertOrder converts a raw Binance order response into an Order
+func convertOrder(resp *models.BinanceOrderResponse) *models.Order {
+	price, _ := utils.ParseFloat(resp.Price)
+	quantity, _ := utils.ParseFloat(resp.OrigQty)
+	executedQty, _ := utils.ParseFloat(resp.ExecutedQty)
+	quoteQty, _ := utils.ParseFloat(resp.CummulativeQuoteQty)
+
+	order := &models.Order{
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.ClientOrderID,
+		Symbol:        resp.Symbol,
+		Side:          resp.Side,
+		Type:          resp.Type,
+		Status:        resp.Status,
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      price,
+	}
+
+	if executedQty > 0 && quoteQty > 0 {
+		order.AvgPrice = quoteQty / executedQty
+	}
+
+	for _, fill := range resp.Fills {
+		commission, _ := utils.ParseFloat(fill.Commission)
+		order.Commission += commission
+		order.CommissionAsset = fill.CommissionAsset
+	}
+
+	switch {
+	case resp.TransactTime > 0:
+		order.Timestamp = time.UnixMilli(resp.TransactTime)
+	case resp.UpdateTime > 0:
+		order.Timestamp = time.UnixMilli(resp.UpdateTime)
+	default:
+		order.Timestamp = time.UnixMilli(resp.Time)
+	}
+
+	return order
+}
@@ -0,0 +1,272 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+const (
+	testAPIKey    = "test-key"
+	testSecretKey = "test-secret"
+)
+
+// newTestClient creates a client sending its requests to a test server
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+
+	log, err := logger.NewLogger("binance-test", logger.ERROR, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	return NewClient(&config.BinanceConfig{
+		APIKey:           testAPIKey,
+		SecretKey:        testSecretKey,
+		APIBaseURL:       baseURL,
+		RateLimit:        6000,
+		RetryAttempts:    1,
+		RecvWindow:       5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	}, log)
+}
+
+// verifySignature checks the API key header and the HMAC-SHA256 signature of a signed request
+func verifySignature(r *http.Request) error {
+	if key := r.Header.Get("X-MBX-APIKEY"); key != testAPIKey {
+		return fmt.Errorf("api key header = %q", key)
+	}
+
+	query := r.URL.RawQuery
+	index := strings.LastIndex(query, "&signature=")
+	if index < 0 {
+		return fmt.Errorf("missing signature in %q", query)
+	}
+
+	mac := hmac.New(sha256.New, []byte(testSecretKey))
+	mac.Write([]byte(query[:index]))
+	if expected := hex.EncodeToString(mac.Sum(nil)); query[index+len("&signature="):] != expected {
+		return fmt.Errorf("signature does not match the payload %q", query[:index])
+	}
+	return nil
+}
+
+const filledOrderResponse = `{"symbol":"BTCUSDT","orderId":42,"clientOrderId":"abc","transactTime":1700000000000,
+	"price":"0","origQty":"0.5","executedQty":"0.5","cummulativeQuoteQty":"15000","status":"FILLED",
+	"type":"MARKET","side":"BUY","fills":[{"price":"30000","qty":"0.5","commission":"0.0005","commissionAsset":"BNB"}]}`
+
+func TestPlaceOrderSignsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v3/order" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := verifySignature(r); err != nil {
+			t.Error(err)
+		}
+
+		query := r.URL.Query()
+		expected := map[string]string{
+			"symbol":           "BTCUSDT",
+			"side":             "BUY",
+			"type":             "MARKET",
+			"quantity":         "0.5",
+			"newOrderRespType": "FULL",
+			"recvWindow":       "5000",
+		}
+		for key, value := range expected {
+			if got := query.Get(key); got != value {
+				t.Errorf("%s = %q, want %q", key, got, value)
+			}
+		}
+		if _, err := strconv.ParseInt(query.Get("timestamp"), 10, 64); err != nil {
+			t.Errorf("invalid timestamp %q", query.Get("timestamp"))
+		}
+
+		fmt.Fprint(w, filledOrderResponse)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	order, err := client.PlaceOrder(context.Background(), &models.OrderRequest{
+		Symbol: "BTCUSDT", Side: "BUY", Type: "MARKET", Quantity: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if order.OrderID != 42 || order.ExecutedQty != 0.5 || order.AvgPrice != 30000 {
+		t.Errorf("order = %+v, want ID 42 filled 0.5 at 30000", order)
+	}
+}
+
+func TestSignedRequestResyncsOnInvalidTimestamp(t *testing.T) {
+	serverOffset := 10 * time.Second
+
+	var mu sync.Mutex
+	var orderTimestamps []int64
+	timeRequests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/v3/time":
+			timeRequests++
+			fmt.Fprintf(w, `{"serverTime":%d}`, time.Now().Add(serverOffset).UnixMilli())
+		case "/api/v3/order":
+			if err := verifySignature(r); err != nil {
+				t.Error(err)
+			}
+			timestamp, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+			orderTimestamps = append(orderTimestamps, timestamp)
+
+			// The first attempt is stamped with local time, outside recvWindow of the server
+			if len(orderTimestamps) == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`)
+				return
+			}
+			fmt.Fprint(w, filledOrderResponse)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	if _, err := client.PlaceOrder(context.Background(), &models.OrderRequest{
+		Symbol: "BTCUSDT", Side: "BUY", Type: "MARKET", Quantity: 0.5,
+	}); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if timeRequests != 1 {
+		t.Errorf("server time requested %d times, want 1", timeRequests)
+	}
+	if len(orderTimestamps) != 2 {
+		t.Fatalf("order sent %d times, want 2", len(orderTimestamps))
+	}
+
+	// The retry is stamped with server time
+	shift := time.Duration(orderTimestamps[1]-orderTimestamps[0]) * time.Millisecond
+	if shift < serverOffset-time.Second || shift > serverOffset+time.Second {
+		t.Errorf("retry timestamp moved by %s, want about %s", shift, serverOffset)
+	}
+}
+
+func TestSignedRequestGivesUpAfterOneResync(t *testing.T) {
+	orderRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/time" {
+			fmt.Fprintf(w, `{"serverTime":%d}`, time.Now().UnixMilli())
+			return
+		}
+		orderRequests++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	_, err := client.PlaceOrder(context.Background(), &models.OrderRequest{
+		Symbol: "BTCUSDT", Side: "BUY", Type: "MARKET", Quantity: 0.5,
+	})
+	if err == nil {
+		t.Fatal("PlaceOrder succeeded, want the -1021 error")
+	}
+	if orderRequests != 2 {
+		t.Errorf("order sent %d times, want 2", orderRequests)
+	}
+}
+
+func TestMarginRequestsUseMarginEndpoints(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/sapi/v1/margin/order":
+			if r.Method == http.MethodPost && r.URL.Query().Get("sideEffectType") != models.SideEffectMarginBuy {
+				t.Errorf("sideEffectType = %q", r.URL.Query().Get("sideEffectType"))
+			}
+			fmt.Fprint(w, filledOrderResponse)
+		case "/sapi/v1/margin/openOrders":
+			fmt.Fprint(w, `[]`)
+		case "/sapi/v1/margin/account":
+			fmt.Fprint(w, `{"tradeEnabled":true,"userAssets":[{"asset":"USDT","free":"100","locked":"0",
+				"borrowed":"0","interest":"0","netAsset":"100"},{"asset":"BTC","free":"0","locked":"0",
+				"borrowed":"0","interest":"0","netAsset":"0"}]}`)
+		case "/sapi/v1/userDataStream":
+			fmt.Fprint(w, `{"listenKey":"margin-key"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, server.URL)
+
+	if _, err := client.PlaceOrder(ctx, &models.OrderRequest{
+		Symbol: "BTCUSDT", Side: "SELL", Type: "MARKET", Quantity: 0.5, SideEffectType: models.SideEffectMarginBuy,
+	}); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if _, err := client.GetOrder(ctx, "BTCUSDT", 42, true); err != nil {
+		t.Fatalf("GetOrder failed: %v", err)
+	}
+	if _, err := client.CancelOrder(ctx, "BTCUSDT", 42, true); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	if _, err := client.GetOpenOrders(ctx, "BTCUSDT", true); err != nil {
+		t.Fatalf("GetOpenOrders failed: %v", err)
+	}
+
+	balances, err := client.GetMarginBalances(ctx)
+	if err != nil {
+		t.Fatalf("GetMarginBalances failed: %v", err)
+	}
+	if len(balances) != 1 || balances[0].Asset != "USDT" || balances[0].Free != 100 {
+		t.Errorf("balances = %+v, want 100 free USDT only", balances)
+	}
+
+	listenKey, err := client.CreateListenKey(ctx, true)
+	if err != nil || listenKey != "margin-key" {
+		t.Fatalf("CreateListenKey = %q, %v", listenKey, err)
+	}
+
+	expected := []string{
+		"POST /sapi/v1/margin/order",
+		"GET /sapi/v1/margin/order",
+		"DELETE /sapi/v1/margin/order",
+		"GET /sapi/v1/margin/openOrders",
+		"GET /sapi/v1/margin/account",
+		"POST /sapi/v1/userDataStream",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(paths, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("requests = %v, want %v", paths, expected)
+	}
+}
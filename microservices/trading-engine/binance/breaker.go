@@ -0,0 +1,116 @@
+package binance
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"trading-engine/logger"
+)
+
+// ErrCircuitOpen is returned without contacting Binance while the exchange is considered unhealthy
+var ErrCircuitOpen = errors.New("circuit breaker open: Binance is unhealthy")
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "CLOSED"
+	CircuitOpen     = "OPEN"
+	CircuitHalfOpen = "HALF_OPEN"
+)
+
+// CircuitBreaker stops requests after consecutive network or server failures, then lets a
+// single probe through once the cooldown has elapsed to decide whether to close again
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	logger    *logger.Logger
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker creates a closed circuit breaker that opens after threshold consecutive failures
+func NewCircuitBreaker(threshold int, cooldown time.Duration, log *logger.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    log,
+		state:     CircuitClosed,
+	}
+}
+
+// Allow returns ErrCircuitOpen if a request may not be sent now
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.cooldown {
+			return ErrCircuitOpen
+		}
+		cb.transition(CircuitHalfOpen)
+		cb.probing = true
+		return nil
+	case CircuitHalfOpen:
+		// Only one probe is in flight at a time
+		if cb.probing {
+			return ErrCircuitOpen
+		}
+		cb.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed request, healthy unless it failed on the network or with a server error
+func (cb *CircuitBreaker) Record(healthy bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+	if healthy {
+		cb.failures = 0
+		if cb.state != CircuitClosed {
+			cb.transition(CircuitClosed)
+		}
+		return
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || (cb.state == CircuitClosed && cb.failures >= cb.threshold) {
+		cb.openedAt = time.Now()
+		cb.transition(CircuitOpen)
+	}
+}
+
+// Release ends an allowed request whose outcome says nothing about exchange health
+func (cb *CircuitBreaker) Release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// transition changes the state and logs it, the caller must hold mu
+func (cb *CircuitBreaker) transition(state string) {
+	fields := cb.logger.WithFields(map[string]interface{}{
+		"from":     cb.state,
+		"to":       state,
+		"failures": cb.failures,
+	})
+	cb.state = state
+
+	if state == CircuitOpen {
+		fields.Warn("Binance circuit breaker opened, pausing requests for %s", cb.cooldown)
+	} else {
+		fields.Info("Binance circuit breaker %s", state)
+	}
+}
@@ -0,0 +1,55 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckClosesBreakerAfterCooldown(t *testing.T) {
+	var healthy atomic.Bool
+	var pings atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	client.breaker = NewCircuitBreaker(2, 50*time.Millisecond, client.logger)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := client.HealthCheck(ctx); err == nil {
+			t.Fatal("health check succeeded against a failing server")
+		}
+	}
+	if state := client.CircuitState(); state != CircuitOpen {
+		t.Fatalf("breaker %s after consecutive server errors, want %s", state, CircuitOpen)
+	}
+
+	// Probes during the cooldown are refused without reaching Binance
+	healthy.Store(true)
+	if err := client.HealthCheck(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("health check during cooldown = %v, want %v", err, ErrCircuitOpen)
+	}
+	if n := pings.Load(); n != 2 {
+		t.Errorf("server pinged %d times, want 2", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := client.HealthCheck(ctx); err != nil {
+		t.Fatalf("probe after cooldown failed: %v", err)
+	}
+	if !client.ExchangeHealthy() {
+		t.Errorf("breaker %s after a successful probe, want %s", client.CircuitState(), CircuitClosed)
+	}
+}
@@ -4,14 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
+	neturl "net/url"
+	"strconv"
 	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
-
 	"trading-engine/config"
 	"trading-engine/logger"
 	"trading-engine/models"
@@ -24,48 +22,14 @@ type Client struct {
 	httpClient  *http.Client
 	logger      *logger.Logger
 	rateLimiter *RateLimiter
+	breaker     *CircuitBreaker
+	timeOffset  time.Duration
 	mu          sync.RWMutex
-}
-
-// RateLimiter implements token bucket rate limiting
-type RateLimiter struct {
-	tokens     int
-	maxTokens  int
-	refillRate time.Duration
-	lastRefill time.Time
-	mu         sync.Mutex
-}
-
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(maxTokens int, refillRate time.Duration) *RateLimiter {
-	return &RateLimiter{
-		tokens:     maxTokens,
-		maxTokens:  maxTokens,
-		refillRate: refillRate,
-		lastRefill: time.Now(),
-	}
-}
-
-// Allow checks if a request is allowed
-func (rl *RateLimiter) Allow() bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	elapsed := now.Sub(rl.lastRefill)
 
-	// Refill tokens based on elapsed time
-	tokensToAdd := int(elapsed / rl.refillRate)
-	if tokensToAdd > 0 {
-		rl.tokens = int(utils.MinFloat64(float64(rl.tokens+tokensToAdd), float64(rl.maxTokens)))
-		rl.lastRefill = now
-	}
-
-	if rl.tokens > 0 {
-		rl.tokens--
-		return true
-	}
-	return false
+	// Trading rules cached from exchangeInfo
+	symbolFilters   map[string]models.SymbolFilters
+	filtersLoadedAt time.Time
+	filtersMu       sync.Mutex
 }
 
 // NewClient creates a new Binance client
@@ -79,44 +43,35 @@ func NewClient(cfg *config.BinanceConfig, log *logger.Logger) *Client {
 		},
 	}
 
-	rateLimiter := NewRateLimiter(cfg.RateLimit, time.Minute)
+	// RateLimit is the request weight allowed per minute
+	rateLimiter := NewRateLimiter(cfg.RateLimit)
 
 	return &Client{
 		config:      cfg,
 		httpClient:  httpClient,
 		logger:      log,
 		rateLimiter: rateLimiter,
+		breaker:     NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown, log),
 	}
 }
 
 // FetchPrices fetches current prices for multiple symbols
 func (c *Client) FetchPrices(ctx context.Context, symbols []string) (map[string]models.BinancePriceData, error) {
-	if !c.rateLimiter.Allow() {
-		return nil, fmt.Errorf("rate limit exceeded")
-	}
-
-	url := c.config.APIBaseURL + "/api/v3/ticker/24hr"
+	params := neturl.Values{}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Requesting only the needed symbols costs far less weight than the full ticker list
+	if len(symbols) > 0 && len(symbols) <= 100 {
+		encoded, err := json.Marshal(symbols)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode symbols: %w", err)
+		}
+		params.Set("symbols", string(encoded))
 	}
 
-	resp, err := c.httpClient.Do(req)
+	body, err := c.request(ctx, http.MethodGet, "/api/v3/ticker/24hr", params, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch prices: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: status=%d, body=%s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
 
 	var tickers []models.BinanceTickerResponse
 	if err := json.Unmarshal(body, &tickers); err != nil {
@@ -156,60 +111,19 @@ func (c *Client) FetchPrices(ctx context.Context, symbols []string) (map[string]
 
 // FetchHistoricalKlines fetches historical candlestick data
 func (c *Client) FetchHistoricalKlines(ctx context.Context, symbol, interval string, limit int) ([]models.Candle, error) {
-	if !c.rateLimiter.Allow() {
-		return nil, fmt.Errorf("rate limit exceeded")
-	}
+	params := neturl.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", interval)
+	params.Set("limit", strconv.Itoa(limit))
 
-	url := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&limit=%d",
-		c.config.APIBaseURL, symbol, interval, limit)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	body, err := c.request(ctx, http.MethodGet, "/api/v3/klines", params, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch klines: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: status=%d, body=%s", resp.StatusCode, string(body))
-	}
 
-	body, err := io.ReadAll(resp.Body)
+	candles, err := parseKlines(body, symbol)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var klineData [][]interface{}
-	if err := json.Unmarshal(body, &klineData); err != nil {
-		return nil, fmt.Errorf("failed to parse klines response: %w", err)
-	}
-
-	candles := make([]models.Candle, 0, len(klineData))
-	for _, kline := range klineData {
-		if len(kline) >= 6 {
-			open, _ := utils.ParseFloat(kline[1].(string))
-			high, _ := utils.ParseFloat(kline[2].(string))
-			low, _ := utils.ParseFloat(kline[3].(string))
-			closePrice, _ := utils.ParseFloat(kline[4].(string))
-			volume, _ := utils.ParseFloat(kline[5].(string))
-			timestamp, _ := utils.ParseInt(fmt.Sprintf("%.0f", kline[0].(float64)))
-
-			candles = append(candles, models.Candle{
-				Open:      open,
-				High:      high,
-				Low:       low,
-				Close:     closePrice,
-				Volume:    volume,
-				Time:      timestamp / 1000, // Convert to seconds
-				Timestamp: time.Unix(timestamp/1000, 0),
-				Symbol:    symbol,
-			})
-		}
+		return nil, err
 	}
 
 	c.logger.WithFields(map[string]interface{}{
@@ -221,188 +135,62 @@ func (c *Client) FetchHistoricalKlines(ctx context.Context, symbol, interval str
 	return candles, nil
 }
 
-// WebSocketClient represents a WebSocket connection to Binance
-type WebSocketClient struct {
-	config      *config.BinanceConfig
-	logger      *logger.Logger
-	connections map[string]*websocket.Conn
-	subscribers map[string][]chan models.LiveTicker
-	mu          sync.RWMutex
-}
+// FetchDepthSnapshot fetches an order book snapshot, returned as an update ending at its lastUpdateId
+func (c *Client) FetchDepthSnapshot(ctx context.Context, symbol string, limit int) (*models.DepthUpdate, error) {
+	params := neturl.Values{}
+	params.Set("symbol", symbol)
+	params.Set("limit", strconv.Itoa(limit))
 
-// NewWebSocketClient creates a new WebSocket client
-func NewWebSocketClient(cfg *config.BinanceConfig, log *logger.Logger) *WebSocketClient {
-	return &WebSocketClient{
-		config:      cfg,
-		logger:      log,
-		connections: make(map[string]*websocket.Conn),
-		subscribers: make(map[string][]chan models.LiveTicker),
-	}
-}
-
-// Subscribe subscribes to a symbol's ticker stream
-func (wsc *WebSocketClient) Subscribe(symbol string) error {
-	wsc.mu.Lock()
-	defer wsc.mu.Unlock()
-
-	// Check if already connected
-	if _, exists := wsc.connections[symbol]; exists {
-		wsc.logger.WithFields(map[string]interface{}{
-			"symbol": symbol,
-		}).Info("Already subscribed to symbol")
-		return nil
-	}
-
-	wsURL := fmt.Sprintf("%s/%s@ticker", wsc.config.WSURL, strings.ToLower(symbol))
-
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	body, err := c.request(ctx, http.MethodGet, "/api/v3/depth", params, false)
 	if err != nil {
-		return fmt.Errorf("failed to connect to WebSocket for %s: %w", symbol, err)
+		return nil, fmt.Errorf("failed to fetch depth: %w", err)
 	}
 
-	wsc.connections[symbol] = conn
-
-	// Start listening for messages
-	go wsc.handleMessages(symbol, conn)
-
-	wsc.logger.WithFields(map[string]interface{}{
-		"symbol": symbol,
-		"url":    wsURL,
-	}).Info("Successfully subscribed to WebSocket stream")
-
-	return nil
-}
-
-// Unsubscribe unsubscribes from a symbol's ticker stream
-func (wsc *WebSocketClient) Unsubscribe(symbol string) error {
-	wsc.mu.Lock()
-	defer wsc.mu.Unlock()
-
-	conn, exists := wsc.connections[symbol]
-	if !exists {
-		return nil
+	var snapshot models.BinanceDepthSnapshot
+	if err := json.Unmarshal(body, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse depth response: %w", err)
 	}
 
-	conn.Close()
-	delete(wsc.connections, symbol)
-	delete(wsc.subscribers, symbol)
-
-	wsc.logger.WithFields(map[string]interface{}{
-		"symbol": symbol,
-	}).Info("Unsubscribed from WebSocket stream")
-
-	return nil
-}
-
-// AddSubscriber adds a subscriber channel for a symbol
-func (wsc *WebSocketClient) AddSubscriber(symbol string, ch chan models.LiveTicker) {
-	wsc.mu.Lock()
-	defer wsc.mu.Unlock()
-
-	if wsc.subscribers[symbol] == nil {
-		wsc.subscribers[symbol] = make([]chan models.LiveTicker, 0)
-	}
-	wsc.subscribers[symbol] = append(wsc.subscribers[symbol], ch)
-}
-
-// RemoveSubscriber removes a subscriber channel for a symbol
-func (wsc *WebSocketClient) RemoveSubscriber(symbol string, ch chan models.LiveTicker) {
-	wsc.mu.Lock()
-	defer wsc.mu.Unlock()
-
-	subscribers := wsc.subscribers[symbol]
-	for i, subscriber := range subscribers {
-		if subscriber == ch {
-			wsc.subscribers[symbol] = append(subscribers[:i], subscribers[i+1:]...)
-			break
+	c.logger.WithFields(map[string]interface{}{
+		"symbol":         symbol,
+		"bids":           len(snapshot.Bids),
+		"asks":           len(snapshot.Asks),
+		"last_update_id": snapshot.LastUpdateID,
+	}).Debug("Fetched order book snapshot")
+
+	return &models.DepthUpdate{
+		Symbol:        symbol,
+		FinalUpdateID: snapshot.LastUpdateID,
+		Bids:          parsePriceLevels(snapshot.Bids),
+		Asks:          parsePriceLevels(snapshot.Asks),
+	}, nil
+}
+
+// parsePriceLevels converts [price, quantity] string pairs into price levels
+func parsePriceLevels(raw [][]string) []models.PriceLevel {
+	levels := make([]models.PriceLevel, 0, len(raw))
+	for _, level := range raw {
+		if len(level) < 2 {
+			continue
 		}
-	}
-}
-
-// handleMessages handles incoming WebSocket messages
-func (wsc *WebSocketClient) handleMessages(symbol string, conn *websocket.Conn) {
-	defer func() {
-		wsc.mu.Lock()
-		delete(wsc.connections, symbol)
-		delete(wsc.subscribers, symbol)
-		wsc.mu.Unlock()
-		conn.Close()
-	}()
-
-	for {
-		var tickerData models.BinanceStreamTickerData
-		err := conn.ReadJSON(&tickerData)
+		price, err := utils.ParseFloat(level[0])
 		if err != nil {
-			wsc.logger.WithFields(map[string]interface{}{
-				"symbol": symbol,
-				"error":  err.Error(),
-			}).Error("WebSocket read error")
-			break
-		}
-
-		// Create live ticker
-		ticker := models.LiveTicker{
-			Type:   "ticker",
-			Symbol: symbol,
-			Price:  tickerData.Data.C,
-			Volume: tickerData.Data.V,
-			Change: tickerData.Data.P,
+			continue
 		}
-
-		// Notify subscribers
-		wsc.mu.RLock()
-		subscribers := wsc.subscribers[symbol]
-		for _, ch := range subscribers {
-			select {
-			case ch <- ticker:
-			default:
-				// Channel is full, skip
-			}
+		quantity, err := utils.ParseFloat(level[1])
+		if err != nil {
+			continue
 		}
-		wsc.mu.RUnlock()
+		levels = append(levels, models.PriceLevel{Price: price, Quantity: quantity})
 	}
-}
-
-// Close closes all WebSocket connections
-func (wsc *WebSocketClient) Close() error {
-	wsc.mu.Lock()
-	defer wsc.mu.Unlock()
-
-	for symbol, conn := range wsc.connections {
-		conn.Close()
-		wsc.logger.WithFields(map[string]interface{}{
-			"symbol": symbol,
-		}).Info("Closed WebSocket connection")
-	}
-
-	wsc.connections = make(map[string]*websocket.Conn)
-	wsc.subscribers = make(map[string][]chan models.LiveTicker)
-
-	return nil
+	return levels
 }
 
 // HealthCheck performs a health check on the Binance API
 func (c *Client) HealthCheck(ctx context.Context) error {
-	if !c.rateLimiter.Allow() {
-		return fmt.Errorf("rate limit exceeded")
-	}
-
-	url := c.config.APIBaseURL + "/api/v3/ping"
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create health check request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	if _, err := c.request(ctx, http.MethodGet, "/api/v3/ping", nil, false); err != nil {
 		return fmt.Errorf("health check failed: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("health check failed: status=%d", resp.StatusCode)
-	}
 
 	return nil
 }
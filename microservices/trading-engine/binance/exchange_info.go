@@ -0,0 +1,107 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// exchangeInfoTTL is how long cached trading rules are used before they are refetched
+const exchangeInfoTTL = time.Hour
+
+// FetchExchangeInfo fetches the trading rules of every symbol, keyed by symbol
+func (c *Client) FetchExchangeInfo(ctx context.Context) (map[string]models.SymbolFilters, error) {
+	body, err := c.request(ctx, http.MethodGet, "/api/v3/exchangeInfo", nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange info: %w", err)
+	}
+
+	var info models.BinanceExchangeInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange info response: %w", err)
+	}
+
+	filters := make(map[string]models.SymbolFilters, len(info.Symbols))
+	for _, symbol := range info.Symbols {
+		symbolFilters := models.SymbolFilters{
+			Symbol:     symbol.Symbol,
+			Status:     symbol.Status,
+			BaseAsset:  symbol.BaseAsset,
+			QuoteAsset: symbol.QuoteAsset,
+		}
+		for _, filter := range symbol.Filters {
+			applyFilter(&symbolFilters, filter)
+		}
+		filters[symbol.Symbol] = symbolFilters
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"symbols": len(filters),
+	}).Info("Successfully fetched exchange info")
+
+	return filters, nil
+}
+
+// SymbolFilters returns the trading rules of a symbol, refreshing the cached exchange info
+// once it expires. Stale rules are served if the refresh fails.
+func (c *Client) SymbolFilters(ctx context.Context, symbol string) (models.SymbolFilters, error) {
+	c.filtersMu.Lock()
+	defer c.filtersMu.Unlock()
+
+	if c.symbolFilters == nil || time.Since(c.filtersLoadedAt) > exchangeInfoTTL {
+		filters, err := c.FetchExchangeInfo(ctx)
+		switch {
+		case err == nil:
+			c.symbolFilters = filters
+			c.filtersLoadedAt = time.Now()
+		case c.symbolFilters == nil:
+			return models.SymbolFilters{}, err
+		default:
+			c.logger.Warn("Failed to refresh exchange info, using cached filters: %v", err)
+		}
+	}
+
+	filters, exists := c.symbolFilters[symbol]
+	if !exists {
+		return models.SymbolFilters{}, fmt.Errorf("symbol %s is not listed on the exchange", symbol)
+	}
+	return filters, nil
+}
+
+// applyFilter copies the limits of a single exchangeInfo filter into the symbol's rules
+func applyFilter(filters *models.SymbolFilters, filter map[string]interface{}) {
+	switch filter["filterType"] {
+	case "PRICE_FILTER":
+		filters.TickSize = filterFloat(filter, "tickSize")
+		filters.MinPrice = filterFloat(filter, "minPrice")
+		filters.MaxPrice = filterFloat(filter, "maxPrice")
+	case "LOT_SIZE":
+		filters.StepSize = filterFloat(filter, "stepSize")
+		filters.MinQty = filterFloat(filter, "minQty")
+		filters.MaxQty = filterFloat(filter, "maxQty")
+	case "MARKET_LOT_SIZE":
+		filters.MarketStepSize = filterFloat(filter, "stepSize")
+		filters.MarketMinQty = filterFloat(filter, "minQty")
+		filters.MarketMaxQty = filterFloat(filter, "maxQty")
+	case "MIN_NOTIONAL":
+		filters.MinNotional = filterFloat(filter, "minNotional")
+		filters.MinNotionalMarket, _ = filter["applyToMarket"].(bool)
+	case "NOTIONAL":
+		filters.MinNotional = filterFloat(filter, "minNotional")
+		filters.MinNotionalMarket, _ = filter["applyMinToMarket"].(bool)
+		filters.MaxNotional = filterFloat(filter, "maxNotional")
+		filters.MaxNotionalMarket, _ = filter["applyMaxToMarket"].(bool)
+	}
+}
+
+// filterFloat parses a decimal string field of a filter
+func filterFloat(filter map[string]interface{}, key string) float64 {
+	raw, _ := filter[key].(string)
+	value, _ := utils.ParseFloat(raw)
+	return value
+}
@@ -0,0 +1,119 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// MaxKlinesPerRequest is the largest page of candles the klines endpoint returns
+const MaxKlinesPerRequest = 1000
+
+// FetchKlines fetches up to limit candles opening between start and end inclusive, oldest first
+func (c *Client) FetchKlines(ctx context.Context, symbol, interval string, start, end time.Time, limit int) ([]models.Candle, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", interval)
+	params.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+	params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+
+	body, err := c.request(ctx, http.MethodGet, "/api/v3/klines", params, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch klines: %w", err)
+	}
+
+	return parseKlines(body, symbol)
+}
+
+// parseKlines converts a klines response into candles
+func parseKlines(body []byte, symbol string) ([]models.Candle, error) {
+	var klineData [][]interface{}
+	if err := json.Unmarshal(body, &klineData); err != nil {
+		return nil, fmt.Errorf("failed to parse klines response: %w", err)
+	}
+
+	candles := make([]models.Candle, 0, len(klineData))
+	for _, kline := range klineData {
+		if len(kline) >= 6 {
+			open, _ := utils.ParseFloat(kline[1].(string))
+			high, _ := utils.ParseFloat(kline[2].(string))
+			low, _ := utils.ParseFloat(kline[3].(string))
+			closePrice, _ := utils.ParseFloat(kline[4].(string))
+			volume, _ := utils.ParseFloat(kline[5].(string))
+			timestamp, _ := utils.ParseInt(fmt.Sprintf("%.0f", kline[0].(float64)))
+
+			candles = append(candles, models.Candle{
+				Open:      open,
+				High:      high,
+				Low:       low,
+				Close:     closePrice,
+				Volume:    volume,
+				Time:      timestamp / 1000, // Convert to seconds
+				Timestamp: time.Unix(timestamp/1000, 0),
+				Symbol:    symbol,
+			})
+		}
+	}
+
+	return candles, nil
+}
+
+// IntervalDuration returns the length of a fixed kline interval such as 1m, 4h or 1w.
+// Monthly candles vary in length and are not supported. Klines open at IntervalOpen.
+func IntervalDuration(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid kline interval: %q", interval)
+	}
+
+	count, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("invalid kline interval: %q", interval)
+	}
+
+	var unit time.Duration
+	switch interval[len(interval)-1] {
+	case 's':
+		unit = time.Second
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("unsupported kline interval: %q", interval)
+	}
+	return time.Duration(count) * unit, nil
+}
+
+// week is the length of a weekly kline
+const week = 7 * 24 * time.Hour
+
+// firstMonday is the open time of the first weekly kline after the Unix epoch, a Thursday
+var firstMonday = time.Unix(0, 0).UTC().AddDate(0, 0, 4)
+
+// IntervalOpen returns the open time of the kline of length step containing t. Binance counts
+// klines from the Unix epoch, except weekly ones, which open on Monday.
+func IntervalOpen(t time.Time, step time.Duration) time.Time {
+	origin := time.Unix(0, 0).UTC()
+	if step%week == 0 {
+		origin = firstMonday
+	}
+
+	elapsed := t.Sub(origin)
+	offset := elapsed % step
+	if offset < 0 {
+		offset += step
+	}
+	return t.Add(-offset).UTC()
+}
@@ -0,0 +1,46 @@
+package binance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalOpen(t *testing.T) {
+	at := time.Date(2024, 5, 16, 13, 47, 12, 0, time.UTC) // A Thursday
+
+	tests := []struct {
+		interval string
+		want     time.Time
+	}{
+		{"1m", time.Date(2024, 5, 16, 13, 47, 0, 0, time.UTC)},
+		{"15m", time.Date(2024, 5, 16, 13, 45, 0, 0, time.UTC)},
+		{"4h", time.Date(2024, 5, 16, 12, 0, 0, 0, time.UTC)},
+		{"1d", time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)},
+		{"3d", time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)}, // Day 19857 since the Unix epoch
+		{"1w", time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)}, // Monday
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			step, err := IntervalDuration(tt.interval)
+			if err != nil {
+				t.Fatalf("IntervalDuration(%q) failed: %v", tt.interval, err)
+			}
+			if got := IntervalOpen(at, step); !got.Equal(tt.want) {
+				t.Errorf("IntervalOpen = %s, want %s", got, tt.want)
+			}
+			if got := IntervalOpen(tt.want, step); !got.Equal(tt.want) {
+				t.Errorf("IntervalOpen of an open time = %s, want it unchanged", got)
+			}
+		})
+	}
+}
+
+func TestIntervalOpenBeforeFirstMonday(t *testing.T) {
+	// 1970-01-03 was a Saturday, in the week opened on Monday 1969-12-29
+	at := time.Date(1970, 1, 3, 6, 0, 0, 0, time.UTC)
+	want := time.Date(1969, 12, 29, 0, 0, 0, 0, time.UTC)
+	if got := IntervalOpen(at, week); !got.Equal(want) {
+		t.Errorf("IntervalOpen = %s, want %s", got, want)
+	}
+}
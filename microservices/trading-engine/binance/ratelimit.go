@@ -0,0 +1,270 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Order count limits of a Binance account
+	maxOrdersPer10s = 50
+	maxOrdersPerDay = 160000
+
+	// maxSAPIWeight is the request weight per minute an IP may spend on /sapi endpoints,
+	// limited apart from the /api weight
+	maxSAPIWeight = 12000
+
+	// Pauses applied when a 429 or 418 response carries no Retry-After
+	defaultRateLimitBackoff = time.Minute
+	defaultIPBanBackoff     = 2 * time.Minute
+)
+
+// RateLimiter tracks request weight and order counts against the Binance limits,
+// blocking callers until capacity is available. Local accounting is resynced from
+// the usage headers returned with every response. The /api and /sapi endpoints
+// each have their own weight budget.
+type RateLimiter struct {
+	maxWeight    int
+	usedWeight   int
+	sapiWeight   int       // Weight used on /sapi endpoints
+	weightWindow time.Time // Start of the current minute, shared by both weights
+	orders10s    int
+	ordersWindow time.Time // Start of the current 10 second window
+	ordersDay    int
+	dayWindow    time.Time // Start of the current UTC day
+	pausedUntil  time.Time // Set after 429 and 418 responses
+	mu           sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter allowing maxWeight of request weight per minute
+func NewRateLimiter(maxWeight int) *RateLimiter {
+	return &RateLimiter{maxWeight: maxWeight}
+}
+
+// Wait blocks until the weight, and an order slot if isOrder is set, are available or ctx is done.
+// With sapi set the weight is taken from the /sapi budget.
+func (rl *RateLimiter) Wait(ctx context.Context, weight int, isOrder, sapi bool) error {
+	for {
+		delay := rl.reserve(weight, isOrder, sapi)
+		if delay <= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// reserve consumes the capacity of a request if it is available, otherwise it returns how long to wait
+func (rl *RateLimiter) reserve(weight int, isOrder, sapi bool) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(rl.pausedUntil) {
+		return rl.pausedUntil.Sub(now)
+	}
+	rl.roll(now)
+
+	// A request heavier than the whole budget is let through on an idle window
+	used, limit := &rl.usedWeight, rl.maxWeight
+	if sapi {
+		used, limit = &rl.sapiWeight, maxSAPIWeight
+	}
+	if *used > 0 && *used+weight > limit {
+		return rl.weightWindow.Add(time.Minute).Sub(now)
+	}
+
+	if isOrder {
+		if rl.ordersDay >= maxOrdersPerDay {
+			return rl.dayWindow.Add(24 * time.Hour).Sub(now)
+		}
+		if rl.orders10s >= maxOrdersPer10s {
+			return rl.ordersWindow.Add(10 * time.Second).Sub(now)
+		}
+		rl.orders10s++
+		rl.ordersDay++
+	}
+
+	*used += weight
+	return 0
+}
+
+// roll resets the counters of windows that have ended, the caller must hold mu.
+// Binance windows are aligned to the clock rather than to the first request.
+func (rl *RateLimiter) roll(now time.Time) {
+	if window := now.Truncate(time.Minute); window.After(rl.weightWindow) {
+		rl.weightWindow = window
+		rl.usedWeight = 0
+		rl.sapiWeight = 0
+	}
+	if window := now.Truncate(10 * time.Second); window.After(rl.ordersWindow) {
+		rl.ordersWindow = window
+		rl.orders10s = 0
+	}
+	if window := now.UTC().Truncate(24 * time.Hour); window.After(rl.dayWindow) {
+		rl.dayWindow = window
+		rl.ordersDay = 0
+	}
+}
+
+// Update resyncs usage from the X-MBX-USED-WEIGHT-1M, X-SAPI-USED-IP-WEIGHT-1M and
+// X-MBX-ORDER-COUNT-* headers.
+// Usage is only raised, so requests still in flight are not forgotten.
+func (rl *RateLimiter) Update(header http.Header) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.roll(time.Now())
+	if used, ok := headerInt(header, "X-MBX-USED-WEIGHT-1M"); ok && used > rl.usedWeight {
+		rl.usedWeight = used
+	}
+	if used, ok := headerInt(header, "X-SAPI-USED-IP-WEIGHT-1M"); ok && used > rl.sapiWeight {
+		rl.sapiWeight = used
+	}
+	if count, ok := headerInt(header, "X-MBX-ORDER-COUNT-10S"); ok && count > rl.orders10s {
+		rl.orders10s = count
+	}
+	if count, ok := headerInt(header, "X-MBX-ORDER-COUNT-1D"); ok && count > rl.ordersDay {
+		rl.ordersDay = count
+	}
+}
+
+// Backoff pauses all requests after a 429 (rate limited) or 418 (IP banned) response
+// for the Retry-After seconds, and returns the pause
+func (rl *RateLimiter) Backoff(statusCode int, retryAfter string) time.Duration {
+	delay := defaultRateLimitBackoff
+	if statusCode == http.StatusTeapot {
+		delay = defaultIPBanBackoff
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if until := time.Now().Add(delay); until.After(rl.pausedUntil) {
+		rl.pausedUntil = until
+	}
+	return delay
+}
+
+// UsedWeight returns the request weight used on /api endpoints in the current minute
+func (rl *RateLimiter) UsedWeight() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.roll(time.Now())
+	return rl.usedWeight
+}
+
+// headerInt parses an integer response header
+func headerInt(header http.Header, key string) (int, bool) {
+	value := header.Get(key)
+	if value == "" {
+		return 0, false
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// requestWeight returns the request weight of an endpoint and whether it places an order
+func requestWeight(method, path string, query url.Values) (int, bool) {
+	switch path {
+	case "/api/v3/ping", "/api/v3/time":
+		return 1, false
+	case "/api/v3/klines", "/api/v3/userDataStream":
+		return 2, false
+	case "/api/v3/exchangeInfo", "/api/v3/account":
+		return 20, false
+	case "/api/v3/depth":
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		switch {
+		case limit <= 100:
+			return 5, false
+		case limit <= 500:
+			return 25, false
+		case limit <= 1000:
+			return 50, false
+		default:
+			return 250, false
+		}
+	case "/api/v3/ticker/24hr":
+		if query.Get("symbol") != "" {
+			return 2, false
+		}
+		if symbols := query.Get("symbols"); symbols != "" {
+			switch count := strings.Count(symbols, ",") + 1; {
+			case count <= 20:
+				return 2, false
+			case count <= 100:
+				return 40, false
+			}
+		}
+		return 80, false
+	case "/api/v3/openOrders":
+		if query.Get("symbol") != "" {
+			return 6, false
+		}
+		return 80, false
+	case "/api/v3/order":
+		switch method {
+		case http.MethodPost:
+			return 1, true
+		case http.MethodGet:
+			return 4, false
+		}
+		return 1, false
+	case "/sapi/v1/margin/order":
+		// Margin orders count against the account order limits
+		if method == http.MethodPost {
+			return 1, true
+		}
+		return 10, false
+	case "/sapi/v1/margin/openOrders", "/sapi/v1/margin/account":
+		return 10, false
+	case "/sapi/v1/userDataStream":
+		return 1, false
+	}
+	return 1, false
+}
+
+// do sends a request once the rate limiter admits it and records the usage Binance reports.
+// Rate limited responses pause later requests but are still returned to the caller.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	weight, isOrder := requestWeight(req.Method, req.URL.Path, req.URL.Query())
+	sapi := strings.HasPrefix(req.URL.Path, "/sapi/")
+	if err := c.rateLimiter.Wait(req.Context(), weight, isOrder, sapi); err != nil {
+		return nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	c.rateLimiter.Update(resp.Header)
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		delay := c.rateLimiter.Backoff(resp.StatusCode, resp.Header.Get("Retry-After"))
+		c.logger.WithFields(map[string]interface{}{
+			"status":      resp.StatusCode,
+			"path":        req.URL.Path,
+			"retry_after": delay.String(),
+		}).Warn("Binance rate limit exceeded, pausing requests")
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,45 @@
+package binance
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestSAPIWeightHasItsOwnBudget(t *testing.T) {
+	rl := NewRateLimiter(100)
+
+	if delay := rl.reserve(100, false, false); delay > 0 {
+		t.Fatalf("first /api request waits %s", delay)
+	}
+	if delay := rl.reserve(1, false, false); delay <= 0 {
+		t.Error("/api request over the budget was let through")
+	}
+
+	// The spent /api budget does not hold back margin requests
+	weight, isOrder := requestWeight(http.MethodGet, "/sapi/v1/margin/account", url.Values{})
+	if delay := rl.reserve(weight, isOrder, true); delay > 0 {
+		t.Errorf("/sapi request waits %s on the /api budget", delay)
+	}
+	if used := rl.UsedWeight(); used != 100 {
+		t.Errorf("/api weight = %d, want 100", used)
+	}
+}
+
+func TestUpdateResyncsSAPIWeight(t *testing.T) {
+	rl := NewRateLimiter(6000)
+
+	header := http.Header{}
+	header.Set("X-SAPI-USED-IP-WEIGHT-1M", "11995")
+	rl.Update(header)
+
+	if used := rl.UsedWeight(); used != 0 {
+		t.Errorf("/api weight = %d, want 0", used)
+	}
+	if delay := rl.reserve(10, false, true); delay <= 0 {
+		t.Error("/sapi request over the resynced budget was let through")
+	}
+	if delay := rl.reserve(10, false, false); delay > 0 {
+		t.Errorf("/api request waits %s on the /sapi budget", delay)
+	}
+}
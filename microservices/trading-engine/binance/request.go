@@ -0,0 +1,221 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"trading-engine/models"
+)
+
+// ErrorClass describes why a request failed, which decides whether it is retried
+type ErrorClass string
+
+// Request error classes
+const (
+	ErrorClassNetwork   ErrorClass = "network"    // No response was received
+	ErrorClassServer    ErrorClass = "server"     // 5xx or a Binance internal error
+	ErrorClassRateLimit ErrorClass = "rate_limit" // 429 or 418
+	ErrorClassRequest   ErrorClass = "request"    // 4xx business error, retrying cannot help
+)
+
+// Binance error codes reporting a backend failure rather than a bad request
+const (
+	errCodeDisconnected = -1001
+	errCodeTimeout      = -1007
+)
+
+// NetworkError wraps a transport failure where no response was received
+type NetworkError struct {
+	Err error
+}
+
+// Error implements the error interface
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error: %v", e.Err)
+}
+
+// Unwrap returns the underlying transport error
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Classify returns the class of a request error, or an empty class for errors that never
+// reached Binance such as cancelled contexts and an open circuit breaker
+func Classify(err error) ErrorClass {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Class
+	}
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		return ErrorClassNetwork
+	}
+	return ""
+}
+
+// classifyResponse returns the class of a failed response
+func classifyResponse(statusCode, code int) ErrorClass {
+	switch {
+	case statusCode == http.StatusTooManyRequests || statusCode == http.StatusTeapot:
+		return ErrorClassRateLimit
+	case statusCode >= 500, statusCode == http.StatusRequestTimeout:
+		return ErrorClassServer
+	case code == errCodeDisconnected || code == errCodeTimeout:
+		return ErrorClassServer
+	default:
+		return ErrorClassRequest
+	}
+}
+
+// isIdempotent reports whether a request may be sent again without side effects.
+// Cancelling twice only fails with an unknown order error on the second attempt.
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+}
+
+// request sends a REST request through the shared pipeline: circuit breaker, rate limiter,
+// signing, error classification and retries with exponential backoff. Only idempotent
+// requests failing on the network, with a server error or a rate limit are retried.
+// It returns the body of a successful response.
+func (c *Client) request(ctx context.Context, method, path string, params url.Values, signed bool) ([]byte, error) {
+	attempts := c.config.RetryAttempts
+	if attempts < 1 || !isIdempotent(method) {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := c.config.RetryDelay * time.Duration(1<<uint(attempt-2))
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		var body []byte
+		body, err = c.attempt(ctx, method, path, params, signed)
+		if err == nil {
+			return body, nil
+		}
+
+		class := Classify(err)
+		if class == "" || class == ErrorClassRequest {
+			return nil, err
+		}
+
+		if attempt < attempts {
+			c.logger.WithFields(map[string]interface{}{
+				"method":  method,
+				"path":    path,
+				"class":   string(class),
+				"attempt": attempt,
+				"error":   err.Error(),
+			}).Warn("Binance request failed, retrying")
+		}
+	}
+	return nil, err
+}
+
+// attempt sends a request once and records its outcome on the circuit breaker
+func (c *Client) attempt(ctx context.Context, method, path string, params url.Values, signed bool) ([]byte, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, method, path, params, signed)
+	if err != nil {
+		c.breaker.Release()
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.breaker.Release()
+			return nil, ctx.Err()
+		}
+		c.breaker.Record(false)
+		return nil, &NetworkError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.breaker.Record(false)
+		return nil, &NetworkError{Err: fmt.Errorf("failed to read response body: %w", err)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		var payload models.BinanceAPIError
+		if json.Unmarshal(body, &payload) == nil && payload.Code != 0 {
+			apiErr.Code = payload.Code
+			apiErr.Message = payload.Msg
+		}
+		apiErr.Class = classifyResponse(apiErr.StatusCode, apiErr.Code)
+
+		switch apiErr.Class {
+		case ErrorClassServer:
+			c.breaker.Record(false)
+		case ErrorClassRateLimit:
+			// Throttling is handled by the rate limiter and says nothing about exchange health
+			c.breaker.Release()
+		default:
+			c.breaker.Record(true)
+		}
+		return nil, apiErr
+	}
+
+	c.breaker.Record(true)
+	return body, nil
+}
+
+// newRequest builds a request, adding the timestamp and signature to signed requests.
+// The API key is sent whenever it is configured, as user data stream endpoints need it unsigned.
+func (c *Client) newRequest(ctx context.Context, method, path string, params url.Values, signed bool) (*http.Request, error) {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+
+	requestURL := c.config.APIBaseURL + path
+	if signed {
+		// The timestamp is taken per attempt so retries stay within recvWindow
+		query.Set("timestamp", strconv.FormatInt(c.serverTimestamp(), 10))
+		if c.config.RecvWindow > 0 {
+			query.Set("recvWindow", strconv.FormatInt(c.config.RecvWindow.Milliseconds(), 10))
+		}
+		payload := query.Encode()
+		requestURL += "?" + payload + "&signature=" + c.sign(payload)
+	} else if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set("X-MBX-APIKEY", c.config.APIKey)
+	}
+	return req, nil
+}
+
+// ExchangeHealthy reports whether the circuit breaker considers Binance healthy
+func (c *Client) ExchangeHealthy() bool {
+	return c.breaker.State() == CircuitClosed
+}
+
+// CircuitState returns the state of the circuit breaker guarding REST calls
+func (c *Client) CircuitState() string {
+	return c.breaker.State()
+}
@@ -0,0 +1,434 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+const (
+	// listenKeyKeepAlive refreshes the listen key well within its 60 minute validity
+	listenKeyKeepAlive = 30 * time.Minute
+
+	// errCodeInvalidListenKey is returned when a listen key has expired or was closed
+	errCodeInvalidListenKey = -1125
+)
+
+// errListenKeyExpired reports a stream ended by a listenKeyExpired event
+var errListenKeyExpired = errors.New("listen key expired")
+
+// listenKeyPath returns the user data stream endpoint of the spot or the cross margin account
+func listenKeyPath(margin bool) string {
+	if margin {
+		return "/sapi/v1/userDataStream"
+	}
+	return "/api/v3/userDataStream"
+}
+
+// CreateListenKey starts a user data stream of the spot or the cross margin account and
+// returns its listen key
+func (c *Client) CreateListenKey(ctx context.Context, margin bool) (string, error) {
+	body, err := c.request(ctx, http.MethodPost, listenKeyPath(margin), nil, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to create listen key: %w", err)
+	}
+
+	var resp struct {
+		ListenKey string `json:"listenKey"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse listen key response: %w", err)
+	}
+	return resp.ListenKey, nil
+}
+
+// KeepAliveListenKey extends the validity of a listen key by 60 minutes
+func (c *Client) KeepAliveListenKey(ctx context.Context, listenKey string, margin bool) error {
+	params := url.Values{}
+	params.Set("listenKey", listenKey)
+
+	if _, err := c.request(ctx, http.MethodPut, listenKeyPath(margin), params, false); err != nil {
+		return fmt.Errorf("failed to keep listen key alive: %w", err)
+	}
+	return nil
+}
+
+// CloseListenKey closes a user data stream
+func (c *Client) CloseListenKey(ctx context.Context, listenKey string, margin bool) error {
+	params := url.Values{}
+	params.Set("listenKey", listenKey)
+
+	if _, err := c.request(ctx, http.MethodDelete, listenKeyPath(margin), params, false); err != nil {
+		return fmt.Errorf("failed to close listen key: %w", err)
+	}
+	return nil
+}
+
+// UserDataStream follows the account's user data stream, delivering order updates from
+// executionReport events and balance changes from outboundAccountPosition events.
+// The listen key is kept alive while the stream runs and replaced once it expires.
+// The spot and the cross margin account each have their own stream.
+type UserDataStream struct {
+	client             *Client
+	config             *config.BinanceConfig
+	logger             *logger.Logger
+	margin             bool
+	orderSubscribers   []chan models.ExecutionReport
+	balanceSubscribers []chan models.AccountUpdate
+	listenKey          string
+	conn               *websocket.Conn
+	closed             bool
+	done               chan struct{}
+	mu                 sync.Mutex
+}
+
+// NewUserDataStream creates a user data stream for the account of the client
+func NewUserDataStream(client *Client, cfg *config.BinanceConfig, log *logger.Logger) *UserDataStream {
+	return &UserDataStream{
+		client: client,
+		config: cfg,
+		logger: log,
+		done:   make(chan struct{}),
+	}
+}
+
+// NewMarginUserDataStream creates a user data stream for the cross margin account of the client
+func NewMarginUserDataStream(client *Client, cfg *config.BinanceConfig, log *logger.Logger) *UserDataStream {
+	stream := NewUserDataStream(client, cfg, log)
+	stream.margin = true
+	return stream
+}
+
+// account returns the name of the account followed, used in logs
+func (s *UserDataStream) account() string {
+	if s.margin {
+		return "margin"
+	}
+	return "spot"
+}
+
+// AddOrderSubscriber adds a channel receiving order updates
+func (s *UserDataStream) AddOrderSubscriber(ch chan models.ExecutionReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orderSubscribers = append(s.orderSubscribers, ch)
+}
+
+// AddBalanceSubscriber adds a channel receiving balance changes
+func (s *UserDataStream) AddBalanceSubscriber(ch chan models.AccountUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balanceSubscribers = append(s.balanceSubscribers, ch)
+}
+
+// Start creates a listen key and connects to the stream, then follows it in the background
+// until Close is called. Later failures are retried with backoff.
+func (s *UserDataStream) Start(ctx context.Context) error {
+	conn, err := s.connect(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	go s.run(ctx, conn)
+	go s.keepListenKeyAlive(ctx)
+	return nil
+}
+
+// connect opens the stream, creating a new listen key unless the current one can be reused
+func (s *UserDataStream) connect(ctx context.Context, reuse bool) (*websocket.Conn, error) {
+	s.mu.Lock()
+	listenKey := s.listenKey
+	s.mu.Unlock()
+
+	if reuse && listenKey != "" {
+		if err := s.client.KeepAliveListenKey(ctx, listenKey, s.margin); err != nil {
+			s.logger.Warn("Listen key could not be reused, creating a new one: %v", err)
+			listenKey = ""
+		}
+	} else {
+		listenKey = ""
+	}
+
+	if listenKey == "" {
+		created, err := s.client.CreateListenKey(ctx, s.margin)
+		if err != nil {
+			return nil, err
+		}
+		listenKey = created
+	}
+
+	streamURL := strings.TrimSuffix(s.config.WSURL, "/") + "/" + listenKey
+	conn, _, err := websocket.DefaultDialer.Dial(streamURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to user data stream: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		conn.Close()
+		return nil, errors.New("user data stream closed")
+	}
+	s.listenKey = listenKey
+	s.conn = conn
+
+	s.logger.WithFields(map[string]interface{}{
+		"account": s.account(),
+	}).Info("Connected to user data stream")
+	return conn, nil
+}
+
+// run reads the stream and reconnects with backoff whenever the connection drops
+func (s *UserDataStream) run(ctx context.Context, conn *websocket.Conn) {
+	for {
+		err := s.readMessages(conn)
+		conn.Close()
+
+		for attempt := 1; ; attempt++ {
+			select {
+			case <-s.done:
+				return
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			if attempt == 1 {
+				s.logger.Warn("User data stream interrupted, reconnecting: %v", err)
+			}
+
+			select {
+			case <-s.done:
+				return
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay(attempt)):
+			}
+
+			// An expired listen key closes the connection, so it is only reused after network failures
+			next, dialErr := s.connect(ctx, !errors.Is(err, errListenKeyExpired))
+			if dialErr == nil {
+				conn = next
+				break
+			}
+			s.logger.WithFields(map[string]interface{}{
+				"attempt": attempt,
+			}).Error("Failed to reconnect user data stream: %v", dialErr)
+		}
+	}
+}
+
+// readMessages dispatches stream events until the connection fails
+func (s *UserDataStream) readMessages(conn *websocket.Conn) error {
+	setReadDeadlines(conn)
+
+	stopPing := make(chan struct{})
+	defer close(stopPing)
+	go keepAlive(conn, stopPing, s.logger)
+
+	for {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var header struct {
+			EventType string `json:"e"`
+			EventTime int64  `json:"E"`
+		}
+		if err := json.Unmarshal(payload, &header); err != nil {
+			s.logger.Warn("Failed to parse user data event: %v", err)
+			continue
+		}
+
+		switch header.EventType {
+		case "executionReport":
+			var event models.BinanceExecutionReport
+			if err := json.Unmarshal(payload, &event); err != nil {
+				s.logger.Warn("Failed to parse execution report: %v", err)
+				continue
+			}
+			report := convertExecutionReport(&event)
+
+			s.mu.Lock()
+			for _, ch := range s.orderSubscribers {
+				select {
+				case ch <- report:
+				default:
+					s.logger.Warn("Order update channel full, dropping update for order %d", report.Order.OrderID)
+				}
+			}
+			s.mu.Unlock()
+		case "outboundAccountPosition":
+			var event models.BinanceAccountPosition
+			if err := json.Unmarshal(payload, &event); err != nil {
+				s.logger.Warn("Failed to parse account position: %v", err)
+				continue
+			}
+			update := convertAccountPosition(&event)
+			update.Margin = s.margin
+
+			s.mu.Lock()
+			for _, ch := range s.balanceSubscribers {
+				select {
+				case ch <- update:
+				default:
+					s.logger.Warn("Balance update channel full, dropping update")
+				}
+			}
+			s.mu.Unlock()
+		case "listenKeyExpired":
+			return errListenKeyExpired
+		}
+	}
+}
+
+// keepListenKeyAlive refreshes the listen key until the stream is closed. An invalid key
+// forces a reconnect, which creates a new one.
+func (s *UserDataStream) keepListenKeyAlive(ctx context.Context) {
+	ticker := time.NewTicker(listenKeyKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			listenKey := s.listenKey
+			conn := s.conn
+			s.mu.Unlock()
+
+			err := s.client.KeepAliveListenKey(ctx, listenKey, s.margin)
+			if err == nil {
+				s.logger.Debug("Refreshed user data listen key")
+				continue
+			}
+
+			s.logger.Warn("Failed to refresh user data listen key: %v", err)
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.Code == errCodeInvalidListenKey {
+				s.mu.Lock()
+				s.listenKey = ""
+				s.mu.Unlock()
+				if conn != nil {
+					conn.Close()
+				}
+			}
+		}
+	}
+}
+
+// Close stops the stream and closes its listen key
+func (s *UserDataStream) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+
+	listenKey := s.listenKey
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.mu.Unlock()
+
+	if listenKey == "" {
+		return nil
+	}
+
+	ctx, cancel := utils.TimeoutContext(10 * time.Second)
+	defer cancel()
+	if err := s.client.CloseListenKey(ctx, listenKey, s.margin); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"account": s.account(),
+	}).Info("Closed user data stream")
+	return nil
+}
+
+// convertExecutionReport converts a raw executionReport event into an ExecutionReport
+func convertExecutionReport(event *models.BinanceExecutionReport) models.ExecutionReport {
+	price, _ := utils.ParseFloat(event.Price)
+	quantity, _ := utils.ParseFloat(event.Quantity)
+	executedQty, _ := utils.ParseFloat(event.CumulativeQty)
+	quoteQty, _ := utils.ParseFloat(event.CumulativeQuoteQty)
+	lastQty, _ := utils.ParseFloat(event.LastQty)
+	lastPrice, _ := utils.ParseFloat(event.LastPrice)
+	commission, _ := utils.ParseFloat(event.Commission)
+
+	report := models.ExecutionReport{
+		Order: models.Order{
+			OrderID:       event.OrderID,
+			ClientOrderID: event.ClientOrderID,
+			Symbol:        event.Symbol,
+			Side:          event.Side,
+			Type:          event.OrderType,
+			Status:        event.Status,
+			Price:         price,
+			Quantity:      quantity,
+			ExecutedQty:   executedQty,
+			AvgPrice:      price,
+			Timestamp:     time.UnixMilli(event.TransactTime),
+		},
+		ExecutionType:  event.ExecutionType,
+		TradeID:        event.TradeID,
+		LastQty:        lastQty,
+		LastPrice:      lastPrice,
+		LastCommission: commission,
+	}
+
+	// Cancellations carry the ID of the cancel request, the order keeps its original one
+	if event.OrigClientOrderID != "" {
+		report.Order.ClientOrderID = event.OrigClientOrderID
+	}
+	if event.RejectReason != "NONE" {
+		report.RejectReason = event.RejectReason
+	}
+	if event.CommissionAsset != nil {
+		report.CommissionAsset = *event.CommissionAsset
+	}
+	if executedQty > 0 && quoteQty > 0 {
+		report.Order.AvgPrice = quoteQty / executedQty
+	}
+
+	return report
+}
+
+// convertAccountPosition converts a raw outboundAccountPosition event into an AccountUpdate
+func convertAccountPosition(event *models.BinanceAccountPosition) models.AccountUpdate {
+	update := models.AccountUpdate{
+		Balances:  make([]models.Balance, 0, len(event.Balances)),
+		Timestamp: time.UnixMilli(event.EventTime),
+	}
+	for _, balance := range event.Balances {
+		free, _ := utils.ParseFloat(balance.Free)
+		locked, _ := utils.ParseFloat(balance.Locked)
+		update.Balances = append(update.Balances, models.Balance{
+			Asset:  balance.Asset,
+			Free:   free,
+			Locked: locked,
+		})
+	}
+	return update
+}
@@ -0,0 +1,850 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+const (
+	// maxStreamsPerConnection is the Binance limit of streams on one combined connection
+	maxStreamsPerConnection = 1024
+
+	// controlMessageInterval keeps SUBSCRIBE/UNSUBSCRIBE under 5 messages per second per connection
+	controlMessageInterval = 250 * time.Millisecond
+
+	// pongWait is how long a connection may stay silent before it is considered dead
+	pongWait = 60 * time.Second
+
+	// pingInterval keeps idle connections alive and detects half-open sockets
+	pingInterval = 20 * time.Second
+
+	// writeWait bounds the time spent writing a frame
+	writeWait = 10 * time.Second
+
+	// connectionLifetime rotates connections ahead of the Binance 24 hour forced disconnect
+	connectionLifetime = 23*time.Hour + 30*time.Minute
+
+	// Reconnect backoff bounds
+	reconnectBaseDelay = time.Second
+	reconnectMaxDelay  = time.Minute
+)
+
+// errConnectionExpired reports a connection closed on purpose at the end of its lifetime
+var errConnectionExpired = errors.New("connection reached its maximum lifetime")
+
+// WebSocketClient multiplexes Binance market streams over a small pool of
+// combined-stream connections, fanning each stream out to its subscribers.
+// Dropped connections are re-established with backoff and their streams restored.
+type WebSocketClient struct {
+	config           *config.BinanceConfig
+	logger           *logger.Logger
+	connections      []*streamConnection
+	streams          map[string]*streamConnection
+	subscribers      map[string][]chan models.LiveTicker
+	klineSubscribers map[string][]chan models.KlineUpdate
+	depthSubscribers map[string][]chan models.DepthUpdate
+	stateSubscribers []chan models.StreamConnectionEvent
+	nextRequestID    int64
+	nextConnectionID int
+	closed           bool
+	done             chan struct{}
+	mu               sync.RWMutex
+}
+
+// streamConnection is a single combined-stream connection and the streams it carries.
+// Everything but lastControl is guarded by the client mutex.
+type streamConnection struct {
+	id          int
+	conn        *websocket.Conn // Nil while reconnecting
+	streams     map[string]bool
+	state       string
+	connectedAt time.Time
+	reconnects  int
+	lastError   string
+	removed     bool
+	lastControl time.Time
+	writeMu     sync.Mutex
+}
+
+// streamControl is a live SUBSCRIBE or UNSUBSCRIBE request
+type streamControl struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
+// streamMessage is a combined-stream payload or a response to a control request
+type streamMessage struct {
+	Stream string                  `json:"stream"`
+	Data   json.RawMessage         `json:"data"`
+	ID     *int64                  `json:"id"`
+	Error  *models.BinanceAPIError `json:"error"`
+}
+
+// NewWebSocketClient creates a new WebSocket client
+func NewWebSocketClient(cfg *config.BinanceConfig, log *logger.Logger) *WebSocketClient {
+	return &WebSocketClient{
+		config:           cfg,
+		logger:           log,
+		streams:          make(map[string]*streamConnection),
+		subscribers:      make(map[string][]chan models.LiveTicker),
+		klineSubscribers: make(map[string][]chan models.KlineUpdate),
+		depthSubscribers: make(map[string][]chan models.DepthUpdate),
+		done:             make(chan struct{}),
+	}
+}
+
+// tickerStream returns the stream name of a symbol's 24hr ticker
+func tickerStream(symbol string) string {
+	return strings.ToLower(symbol) + "@ticker"
+}
+
+// klineStream returns the stream name of a symbol's klines at an interval
+func klineStream(symbol, interval string) string {
+	return fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), interval)
+}
+
+// depthStream returns the stream name of a symbol's order book diffs
+func depthStream(symbol string) string {
+	return strings.ToLower(symbol) + "@depth@100ms"
+}
+
+// combinedStreamURL returns the combined-stream endpoint for the configured raw stream URL
+func (wsc *WebSocketClient) combinedStreamURL() string {
+	return strings.TrimSuffix(strings.TrimSuffix(wsc.config.WSURL, "/"), "/ws") + "/stream"
+}
+
+// dial opens a combined-stream connection already subscribed to the given streams
+func (wsc *WebSocketClient) dial(streams []string) (*websocket.Conn, error) {
+	url := wsc.combinedStreamURL()
+	if len(streams) > 0 {
+		url += "?streams=" + strings.Join(streams, "/")
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to combined stream: %w", err)
+	}
+	return conn, nil
+}
+
+// Subscribe subscribes to a symbol's ticker stream
+func (wsc *WebSocketClient) Subscribe(symbol string) error {
+	if err := wsc.subscribeStream(tickerStream(symbol)); err != nil {
+		return fmt.Errorf("failed to subscribe to ticker stream for %s: %w", symbol, err)
+	}
+	return nil
+}
+
+// Unsubscribe unsubscribes from a symbol's ticker stream
+func (wsc *WebSocketClient) Unsubscribe(symbol string) error {
+	stream := tickerStream(symbol)
+
+	wsc.mu.Lock()
+	delete(wsc.subscribers, stream)
+	wsc.mu.Unlock()
+
+	return wsc.unsubscribeStream(stream)
+}
+
+// AddSubscriber adds a subscriber channel for a symbol's ticker
+func (wsc *WebSocketClient) AddSubscriber(symbol string, ch chan models.LiveTicker) {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+
+	stream := tickerStream(symbol)
+	wsc.subscribers[stream] = append(wsc.subscribers[stream], ch)
+}
+
+// RemoveSubscriber removes a subscriber channel for a symbol's ticker
+func (wsc *WebSocketClient) RemoveSubscriber(symbol string, ch chan models.LiveTicker) {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+
+	stream := tickerStream(symbol)
+	subscribers := wsc.subscribers[stream]
+	for i, subscriber := range subscribers {
+		if subscriber == ch {
+			wsc.subscribers[stream] = append(subscribers[:i], subscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+// SubscribeKlines subscribes a channel to a symbol's kline stream
+func (wsc *WebSocketClient) SubscribeKlines(symbol, interval string, ch chan models.KlineUpdate) error {
+	stream := klineStream(symbol, interval)
+
+	wsc.mu.Lock()
+	wsc.klineSubscribers[stream] = append(wsc.klineSubscribers[stream], ch)
+	wsc.mu.Unlock()
+
+	if err := wsc.subscribeStream(stream); err != nil {
+		// Other subscribers of the stream keep their channels
+		wsc.mu.Lock()
+		subscribers := wsc.klineSubscribers[stream]
+		for i, subscriber := range subscribers {
+			if subscriber == ch {
+				wsc.klineSubscribers[stream] = append(subscribers[:i], subscribers[i+1:]...)
+				break
+			}
+		}
+		if len(wsc.klineSubscribers[stream]) == 0 {
+			delete(wsc.klineSubscribers, stream)
+		}
+		wsc.mu.Unlock()
+		return fmt.Errorf("failed to subscribe to kline stream for %s: %w", symbol, err)
+	}
+	return nil
+}
+
+// UnsubscribeKlines unsubscribes from a symbol's kline stream
+func (wsc *WebSocketClient) UnsubscribeKlines(symbol, interval string) error {
+	stream := klineStream(symbol, interval)
+
+	wsc.mu.Lock()
+	delete(wsc.klineSubscribers, stream)
+	wsc.mu.Unlock()
+
+	return wsc.unsubscribeStream(stream)
+}
+
+// SubscribeDepth subscribes a channel to a symbol's order book diff stream
+func (wsc *WebSocketClient) SubscribeDepth(symbol string, ch chan models.DepthUpdate) error {
+	stream := depthStream(symbol)
+
+	wsc.mu.Lock()
+	wsc.depthSubscribers[stream] = append(wsc.depthSubscribers[stream], ch)
+	wsc.mu.Unlock()
+
+	if err := wsc.subscribeStream(stream); err != nil {
+		// Other subscribers of the stream keep their channels
+		wsc.mu.Lock()
+		subscribers := wsc.depthSubscribers[stream]
+		for i, subscriber := range subscribers {
+			if subscriber == ch {
+				wsc.depthSubscribers[stream] = append(subscribers[:i], subscribers[i+1:]...)
+				break
+			}
+		}
+		if len(wsc.depthSubscribers[stream]) == 0 {
+			delete(wsc.depthSubscribers, stream)
+		}
+		wsc.mu.Unlock()
+		return fmt.Errorf("failed to subscribe to depth stream for %s: %w", symbol, err)
+	}
+	return nil
+}
+
+// UnsubscribeDepth unsubscribes from a symbol's order book diff stream
+func (wsc *WebSocketClient) UnsubscribeDepth(symbol string) error {
+	stream := depthStream(symbol)
+
+	wsc.mu.Lock()
+	delete(wsc.depthSubscribers, stream)
+	wsc.mu.Unlock()
+
+	return wsc.unsubscribeStream(stream)
+}
+
+// AddStateSubscriber adds a channel notified of connection state changes
+func (wsc *WebSocketClient) AddStateSubscriber(ch chan models.StreamConnectionEvent) {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+
+	wsc.stateSubscribers = append(wsc.stateSubscribers, ch)
+}
+
+// ConnectionStatus returns the state of every pooled connection
+func (wsc *WebSocketClient) ConnectionStatus() []models.StreamConnectionStatus {
+	wsc.mu.RLock()
+	defer wsc.mu.RUnlock()
+
+	statuses := make([]models.StreamConnectionStatus, 0, len(wsc.connections))
+	for _, sc := range wsc.connections {
+		statuses = append(statuses, models.StreamConnectionStatus{
+			ConnectionID: sc.id,
+			State:        sc.state,
+			Streams:      len(sc.streams),
+			ConnectedAt:  sc.connectedAt,
+			Reconnects:   sc.reconnects,
+			LastError:    sc.lastError,
+		})
+	}
+	return statuses
+}
+
+// notifyState publishes a connection's current state, the caller must hold mu
+func (wsc *WebSocketClient) notifyState(sc *streamConnection, attempt int, err error) {
+	event := models.StreamConnectionEvent{
+		ConnectionID: sc.id,
+		State:        sc.state,
+		Streams:      len(sc.streams),
+		Attempt:      attempt,
+		Timestamp:    time.Now(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	for _, ch := range wsc.stateSubscribers {
+		select {
+		case ch <- event:
+		default:
+			// Channel is full, skip
+		}
+	}
+}
+
+// subscribeStream adds a stream to a pooled connection, dialing a new one when all are full.
+// The dial happens without holding mu, so the pool is checked again once it is done.
+func (wsc *WebSocketClient) subscribeStream(stream string) error {
+	var dialed *websocket.Conn
+	var sc *streamConnection
+
+	wsc.mu.Lock()
+	for {
+		if wsc.closed {
+			wsc.mu.Unlock()
+			if dialed != nil {
+				dialed.Close()
+			}
+			return fmt.Errorf("WebSocket client is closed")
+		}
+		if _, exists := wsc.streams[stream]; exists {
+			wsc.mu.Unlock()
+			if dialed != nil {
+				dialed.Close()
+			}
+			return nil
+		}
+
+		for _, candidate := range wsc.connections {
+			if len(candidate.streams) < maxStreamsPerConnection {
+				sc = candidate
+				break
+			}
+		}
+		if sc != nil || dialed != nil {
+			break
+		}
+
+		wsc.mu.Unlock()
+		conn, err := wsc.dial(nil)
+		if err != nil {
+			return err
+		}
+		dialed = conn
+		wsc.mu.Lock()
+	}
+
+	// A connection opened by a concurrent subscription has room, so the dialed one is not needed
+	if sc != nil && dialed != nil {
+		dialed.Close()
+	}
+
+	if sc == nil {
+		wsc.nextConnectionID++
+		sc = &streamConnection{
+			id:          wsc.nextConnectionID,
+			conn:        dialed,
+			streams:     make(map[string]bool),
+			state:       models.StreamConnected,
+			connectedAt: time.Now(),
+		}
+		wsc.connections = append(wsc.connections, sc)
+		wsc.notifyState(sc, 0, nil)
+		go wsc.maintainConnection(sc, dialed)
+
+		wsc.logger.WithFields(map[string]interface{}{
+			"url":           wsc.combinedStreamURL(),
+			"connection_id": sc.id,
+			"connections":   len(wsc.connections),
+		}).Info("Opened combined stream connection")
+	}
+
+	sc.streams[stream] = true
+	wsc.streams[stream] = sc
+
+	// Streams added while reconnecting are restored with the rest once the connection is back
+	if sc.state != models.StreamConnected {
+		wsc.mu.Unlock()
+		wsc.logger.Debug("Stream %s queued until connection %d reconnects", stream, sc.id)
+		return nil
+	}
+
+	conn := sc.conn
+	wsc.nextRequestID++
+	request := streamControl{Method: "SUBSCRIBE", Params: []string{stream}, ID: wsc.nextRequestID}
+	wsc.mu.Unlock()
+
+	if err := sc.writeControl(conn, request); err != nil {
+		// A failed write means the connection is broken, closing it hands the stream to the reconnect
+		wsc.logger.Warn("Failed to subscribe to %s, it will be restored on reconnect: %v", stream, err)
+		conn.Close()
+		return nil
+	}
+
+	wsc.logger.WithFields(map[string]interface{}{
+		"stream":        stream,
+		"connection_id": sc.id,
+		"request_id":    request.ID,
+	}).Info("Subscribed to stream")
+
+	return nil
+}
+
+// unsubscribeStream removes a stream from its connection, closing the connection once it carries none
+func (wsc *WebSocketClient) unsubscribeStream(stream string) error {
+	wsc.mu.Lock()
+	sc, exists := wsc.streams[stream]
+	if !exists {
+		wsc.mu.Unlock()
+		return nil
+	}
+
+	delete(sc.streams, stream)
+	delete(wsc.streams, stream)
+
+	if len(sc.streams) == 0 {
+		sc.removed = true
+		wsc.removeConnection(sc)
+		conn := sc.conn
+		wsc.mu.Unlock()
+		if conn != nil {
+			conn.Close()
+		}
+		return nil
+	}
+
+	// Streams dropped while reconnecting are simply not restored
+	if sc.state != models.StreamConnected {
+		wsc.mu.Unlock()
+		return nil
+	}
+
+	conn := sc.conn
+	wsc.nextRequestID++
+	request := streamControl{Method: "UNSUBSCRIBE", Params: []string{stream}, ID: wsc.nextRequestID}
+	wsc.mu.Unlock()
+
+	if err := sc.writeControl(conn, request); err != nil {
+		return err
+	}
+
+	wsc.logger.WithFields(map[string]interface{}{
+		"stream":        stream,
+		"connection_id": sc.id,
+	}).Info("Unsubscribed from stream")
+
+	return nil
+}
+
+// removeConnection drops a connection from the pool, the caller must hold mu
+func (wsc *WebSocketClient) removeConnection(sc *streamConnection) {
+	for i, candidate := range wsc.connections {
+		if candidate == sc {
+			wsc.connections = append(wsc.connections[:i], wsc.connections[i+1:]...)
+			break
+		}
+	}
+}
+
+// writeControl sends a control request, spacing requests to respect the message rate limit
+func (sc *streamConnection) writeControl(conn *websocket.Conn, request streamControl) error {
+	sc.writeMu.Lock()
+	defer sc.writeMu.Unlock()
+
+	if wait := controlMessageInterval - time.Since(sc.lastControl); wait > 0 {
+		time.Sleep(wait)
+	}
+	sc.lastControl = time.Now()
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := conn.WriteJSON(request); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", request.Method, err)
+	}
+	return nil
+}
+
+// maintainConnection reads a connection until it drops, then reconnects it until the
+// connection is removed from the pool or the client is closed
+func (wsc *WebSocketClient) maintainConnection(sc *streamConnection, conn *websocket.Conn) {
+	for {
+		err := wsc.readMessages(sc, conn)
+		conn.Close()
+
+		wsc.mu.Lock()
+		if wsc.closed || sc.removed {
+			wsc.mu.Unlock()
+			return
+		}
+		sc.conn = nil
+		sc.state = models.StreamReconnecting
+		sc.lastError = err.Error()
+		wsc.notifyState(sc, 0, err)
+		streams := len(sc.streams)
+		wsc.mu.Unlock()
+
+		expired := errors.Is(err, errConnectionExpired)
+		if expired {
+			wsc.logger.WithFields(map[string]interface{}{
+				"connection_id": sc.id,
+				"streams":       streams,
+			}).Info("Rotating combined stream connection before forced disconnect")
+		} else {
+			wsc.logger.WithFields(map[string]interface{}{
+				"connection_id": sc.id,
+				"streams":       streams,
+				"error":         err.Error(),
+			}).Warn("Combined stream connection lost, reconnecting")
+		}
+
+		if conn = wsc.reconnect(sc, expired); conn == nil {
+			return
+		}
+	}
+}
+
+// reconnect dials a replacement connection with jittered exponential backoff and restores
+// the connection's streams. It returns nil once the connection is no longer wanted.
+func (wsc *WebSocketClient) reconnect(sc *streamConnection, immediate bool) *websocket.Conn {
+	for attempt := 1; ; attempt++ {
+		if !immediate || attempt > 1 {
+			select {
+			case <-wsc.done:
+				return nil
+			case <-time.After(reconnectDelay(attempt)):
+			}
+		}
+
+		wsc.mu.RLock()
+		if wsc.closed || sc.removed {
+			wsc.mu.RUnlock()
+			return nil
+		}
+		restored := make([]string, 0, len(sc.streams))
+		for stream := range sc.streams {
+			restored = append(restored, stream)
+		}
+		wsc.mu.RUnlock()
+
+		conn, err := wsc.dial(restored)
+		if err != nil {
+			wsc.mu.Lock()
+			sc.lastError = err.Error()
+			wsc.notifyState(sc, attempt, err)
+			wsc.mu.Unlock()
+
+			wsc.logger.WithFields(map[string]interface{}{
+				"connection_id": sc.id,
+				"attempt":       attempt,
+				"error":         err.Error(),
+			}).Warn("Combined stream reconnect failed")
+			continue
+		}
+
+		wsc.mu.Lock()
+		if wsc.closed || sc.removed {
+			wsc.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+
+		// Reconcile streams changed while the dial was in flight
+		added, dropped := diffStreams(restored, sc.streams)
+
+		sc.conn = conn
+		sc.state = models.StreamConnected
+		sc.connectedAt = time.Now()
+		sc.reconnects++
+		sc.lastError = ""
+		wsc.notifyState(sc, attempt, nil)
+
+		var requests []streamControl
+		if len(added) > 0 {
+			wsc.nextRequestID++
+			requests = append(requests, streamControl{Method: "SUBSCRIBE", Params: added, ID: wsc.nextRequestID})
+		}
+		if len(dropped) > 0 {
+			wsc.nextRequestID++
+			requests = append(requests, streamControl{Method: "UNSUBSCRIBE", Params: dropped, ID: wsc.nextRequestID})
+		}
+		streams := len(sc.streams)
+		wsc.mu.Unlock()
+
+		for _, request := range requests {
+			if err := sc.writeControl(conn, request); err != nil {
+				wsc.logger.Warn("Failed to reconcile streams on connection %d: %v", sc.id, err)
+			}
+		}
+
+		wsc.logger.WithFields(map[string]interface{}{
+			"connection_id": sc.id,
+			"attempt":       attempt,
+			"streams":       streams,
+		}).Info("Combined stream connection restored")
+
+		return conn
+	}
+}
+
+// reconnectDelay returns the backoff before a reconnect attempt, jittered over the upper
+// half of the window so pooled connections do not reconnect in lockstep
+func reconnectDelay(attempt int) time.Duration {
+	delay := reconnectMaxDelay
+	if attempt < 8 {
+		if backoff := reconnectBaseDelay << uint(attempt-1); backoff < delay {
+			delay = backoff
+		}
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// diffStreams returns the streams in current but not in dialed, and those in dialed but not in current
+func diffStreams(dialed []string, current map[string]bool) (added, dropped []string) {
+	seen := make(map[string]bool, len(dialed))
+	for _, stream := range dialed {
+		seen[stream] = true
+		if !current[stream] {
+			dropped = append(dropped, stream)
+		}
+	}
+	for stream := range current {
+		if !seen[stream] {
+			added = append(added, stream)
+		}
+	}
+	return added, dropped
+}
+
+// readMessages reads a connection and dispatches payloads by stream until the connection
+// fails, stops answering keepalives or reaches the end of its lifetime
+func (wsc *WebSocketClient) readMessages(sc *streamConnection, conn *websocket.Conn) error {
+	setReadDeadlines(conn)
+
+	expired := make(chan struct{})
+	rotation := time.AfterFunc(connectionLifetime, func() {
+		close(expired)
+		conn.Close()
+	})
+	defer rotation.Stop()
+
+	stopPing := make(chan struct{})
+	defer close(stopPing)
+	go keepAlive(conn, stopPing, wsc.logger)
+
+	for {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-expired:
+				return errConnectionExpired
+			default:
+				return err
+			}
+		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var message streamMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			wsc.logger.Warn("Failed to parse stream message: %v", err)
+			continue
+		}
+
+		// Responses to control requests carry an id instead of a stream
+		if message.Stream == "" {
+			if message.Error != nil {
+				wsc.logger.WithFields(map[string]interface{}{
+					"connection_id": sc.id,
+					"request_id":    message.ID,
+					"code":          message.Error.Code,
+				}).Error("Stream control request failed: %s", message.Error.Msg)
+			}
+			continue
+		}
+
+		wsc.dispatch(message.Stream, payload, message.Data)
+	}
+}
+
+// setReadDeadlines extends the read deadline of a connection on every ping and pong,
+// answering server pings so Binance keeps the connection open
+func setReadDeadlines(conn *websocket.Conn) {
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeWait))
+		var netErr net.Error
+		if err == websocket.ErrCloseSent || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil
+		}
+		return err
+	})
+}
+
+// keepAlive pings the connection until stop is closed or a ping cannot be written
+func keepAlive(conn *websocket.Conn, stop <-chan struct{}, log *logger.Logger) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				// Without pongs the read deadline expires, which triggers the reconnect
+				log.Debug("Failed to send WebSocket ping: %v", err)
+				return
+			}
+		}
+	}
+}
+
+// dispatch fans a stream payload out to the subscribers of that stream
+func (wsc *WebSocketClient) dispatch(stream string, payload []byte, data json.RawMessage) {
+	switch {
+	case strings.Contains(stream, "@kline_"):
+		var event models.BinanceKlineEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			wsc.logger.Warn("Failed to parse kline event on %s: %v", stream, err)
+			return
+		}
+		update := convertKline(&event)
+
+		wsc.mu.RLock()
+		for _, ch := range wsc.klineSubscribers[stream] {
+			select {
+			case ch <- update:
+			default:
+				wsc.logger.Warn("Kline subscriber channel full, dropped update for %s", stream)
+			}
+		}
+		wsc.mu.RUnlock()
+
+	case strings.Contains(stream, "@depth"):
+		var event models.BinanceDepthEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			wsc.logger.Warn("Failed to parse depth event on %s: %v", stream, err)
+			return
+		}
+		update := models.DepthUpdate{
+			Symbol:        event.Symbol,
+			FirstUpdateID: event.FirstUpdateID,
+			FinalUpdateID: event.FinalUpdateID,
+			Bids:          parsePriceLevels(event.Bids),
+			Asks:          parsePriceLevels(event.Asks),
+		}
+
+		// A dropped diff breaks the update sequence, which the order book detects and resyncs
+		wsc.mu.RLock()
+		for _, ch := range wsc.depthSubscribers[stream] {
+			select {
+			case ch <- update:
+			default:
+				wsc.logger.Warn("Depth subscriber channel full, dropped update for %s", stream)
+			}
+		}
+		wsc.mu.RUnlock()
+
+	case strings.HasSuffix(stream, "@ticker"):
+		var tickerData models.BinanceStreamTickerData
+		if err := json.Unmarshal(payload, &tickerData); err != nil {
+			wsc.logger.Warn("Failed to parse ticker event on %s: %v", stream, err)
+			return
+		}
+
+		// Create live ticker
+		ticker := models.LiveTicker{
+			Type:   "ticker",
+			Symbol: tickerData.Data.S,
+			Price:  tickerData.Data.C,
+			Volume: tickerData.Data.V,
+			Change: tickerData.Data.P,
+		}
+
+		// Notify subscribers
+		wsc.mu.RLock()
+		for _, ch := range wsc.subscribers[stream] {
+			select {
+			case ch <- ticker:
+			default:
+				// Channel is full, skip
+			}
+		}
+		wsc.mu.RUnlock()
+	}
+}
+
+// convertKline converts a kline stream event into a candle update
+func convertKline(event *models.BinanceKlineEvent) models.KlineUpdate {
+	open, _ := utils.ParseFloat(event.Kline.Open)
+	high, _ := utils.ParseFloat(event.Kline.High)
+	low, _ := utils.ParseFloat(event.Kline.Low)
+	closePrice, _ := utils.ParseFloat(event.Kline.Close)
+	volume, _ := utils.ParseFloat(event.Kline.Volume)
+
+	return models.KlineUpdate{
+		Candle: models.Candle{
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     closePrice,
+			Volume:    volume,
+			Time:      event.Kline.OpenTime / 1000, // Convert to seconds
+			Timestamp: time.Unix(event.Kline.OpenTime/1000, 0),
+			Symbol:    event.Symbol,
+		},
+		IsClosed: event.Kline.IsClosed,
+	}
+}
+
+// Close closes all WebSocket connections and stops reconnecting
+func (wsc *WebSocketClient) Close() error {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+
+	if wsc.closed {
+		return nil
+	}
+	wsc.closed = true
+	close(wsc.done)
+
+	for _, sc := range wsc.connections {
+		if sc.conn != nil {
+			sc.conn.Close()
+		}
+		sc.state = models.StreamDisconnected
+		wsc.notifyState(sc, 0, nil)
+
+		wsc.logger.WithFields(map[string]interface{}{
+			"connection_id": sc.id,
+			"streams":       len(sc.streams),
+		}).Info("Closed WebSocket connection")
+	}
+
+	wsc.connections = nil
+	wsc.streams = make(map[string]*streamConnection)
+	wsc.subscribers = make(map[string][]chan models.LiveTicker)
+	wsc.klineSubscribers = make(map[string][]chan models.KlineUpdate)
+	wsc.depthSubscribers = make(map[string][]chan models.DepthUpdate)
+
+	return nil
+}
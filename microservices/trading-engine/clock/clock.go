@@ -0,0 +1,239 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time, timers and tickers, so time can be driven
+// deterministically in backtests and tests
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a pending call scheduled by AfterFunc
+type Timer interface {
+	Stop() bool
+}
+
+// Ticker delivers the time on its channel at regular intervals
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock is backed by the system clock
+type realClock struct{}
+
+// New returns a clock backed by the system clock
+func New() Clock {
+	return realClock{}
+}
+
+// Now returns the current system time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since t
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// After returns a channel that receives the time once d has elapsed
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// AfterFunc calls f in its own goroutine after d has elapsed
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// NewTicker returns a ticker backed by time.Ticker
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// realTicker adapts time.Ticker to the Ticker interface
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns the channel ticks are delivered on
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop turns off the ticker
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// Simulated is a manually advanced clock. Timers fire synchronously, in order
+// of their deadline, from the goroutine that moves the clock forward. Like
+// time.Ticker, simulated tickers drop ticks a slow receiver has not consumed.
+type Simulated struct {
+	now    time.Time
+	timers []*simulatedTimer
+	mu     sync.Mutex
+}
+
+// simulatedTimer is a timer or ticker scheduled on a Simulated clock
+type simulatedTimer struct {
+	clock    *Simulated
+	deadline time.Time
+	period   time.Duration // Zero for one-shot timers
+	fn       func(now time.Time)
+	stopped  bool
+}
+
+// simulatedTicker is a periodic timer delivering ticks on a channel
+type simulatedTicker struct {
+	timer *simulatedTimer
+	ch    chan time.Time
+}
+
+// NewSimulated creates a simulated clock starting at the given time
+func NewSimulated(start time.Time) *Simulated {
+	return &Simulated{now: start}
+}
+
+// Now returns the simulated time
+func (s *Simulated) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Since returns the simulated time elapsed since t
+func (s *Simulated) Since(t time.Time) time.Duration {
+	return s.Now().Sub(t)
+}
+
+// After returns a channel that receives the simulated time once the clock has advanced by d
+func (s *Simulated) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	s.schedule(d, 0, func(now time.Time) { ch <- now })
+	return ch
+}
+
+// AfterFunc schedules f to be called once the clock has advanced by d
+func (s *Simulated) AfterFunc(d time.Duration, f func()) Timer {
+	return s.schedule(d, 0, func(time.Time) { f() })
+}
+
+// NewTicker returns a ticker that ticks every d of simulated time
+func (s *Simulated) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	ch := make(chan time.Time, 1)
+	timer := s.schedule(d, d, func(now time.Time) {
+		select {
+		case ch <- now:
+		default:
+		}
+	})
+	return &simulatedTicker{timer: timer, ch: ch}
+}
+
+// schedule registers a timer firing after d, and then every period if it is positive
+func (s *Simulated) schedule(d, period time.Duration, fn func(now time.Time)) *simulatedTimer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timer := &simulatedTimer{clock: s, deadline: s.now.Add(d), period: period, fn: fn}
+	s.timers = append(s.timers, timer)
+	return timer
+}
+
+// Advance moves the clock forward by d, firing every timer that becomes due
+func (s *Simulated) Advance(d time.Duration) {
+	s.Set(s.Now().Add(d))
+}
+
+// Set moves the clock forward to t, firing every timer that becomes due.
+// Times before the current simulated time are ignored.
+func (s *Simulated) Set(t time.Time) {
+	for {
+		s.mu.Lock()
+		if t.Before(s.now) {
+			s.mu.Unlock()
+			return
+		}
+
+		timer := s.nextDue(t)
+		if timer == nil {
+			s.now = t
+			s.mu.Unlock()
+			return
+		}
+
+		// Timers observe the clock at their own deadline
+		if timer.deadline.After(s.now) {
+			s.now = timer.deadline
+		}
+		now := s.now
+		if timer.period > 0 {
+			timer.deadline = timer.deadline.Add(timer.period)
+			s.timers = append(s.timers, timer)
+		} else {
+			timer.stopped = true
+		}
+		s.mu.Unlock()
+
+		timer.fn(now)
+	}
+}
+
+// nextDue removes and returns the earliest timer due at or before t
+func (s *Simulated) nextDue(t time.Time) *simulatedTimer {
+	active := s.timers[:0]
+	for _, timer := range s.timers {
+		if !timer.stopped {
+			active = append(active, timer)
+		}
+	}
+	s.timers = active
+
+	sort.SliceStable(s.timers, func(i, j int) bool {
+		return s.timers[i].deadline.Before(s.timers[j].deadline)
+	})
+
+	if len(s.timers) == 0 || s.timers[0].deadline.After(t) {
+		return nil
+	}
+
+	timer := s.timers[0]
+	s.timers = s.timers[1:]
+	return timer
+}
+
+// Stop cancels the timer, returning false if it already fired or was stopped
+func (t *simulatedTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	return true
+}
+
+// C returns the channel ticks are delivered on
+func (t *simulatedTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop turns off the ticker
+func (t *simulatedTicker) Stop() {
+	t.timer.Stop()
+}
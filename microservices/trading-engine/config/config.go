@@ -11,6 +11,13 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// Supported execution venues
+const (
+	TradingModePaper   = "paper"
+	TradingModeTestnet = "testnet"
+	TradingModeLive    = "live"
+)
+
 // Config holds all application configuration
 type Config struct {
 	Server   ServerConfig   `json:"server"`
@@ -29,23 +36,35 @@ type ServerConfig struct {
 }
 
 type BinanceConfig struct {
-	APIKey        string        `json:"api_key"`
-	SecretKey     string        `json:"secret_key"`
-	IsTestnet     bool          `json:"is_testnet"`
-	WSURL         string        `json:"ws_url"`
-	APIBaseURL    string        `json:"api_base_url"`
-	RateLimit     int           `json:"rate_limit"`
-	RetryAttempts int           `json:"retry_attempts"`
-	RetryDelay    time.Duration `json:"retry_delay"`
+	APIKey           string        `json:"api_key"`
+	SecretKey        string        `json:"secret_key"`
+	IsTestnet        bool          `json:"is_testnet"`
+	WSURL            string        `json:"ws_url"`
+	APIBaseURL       string        `json:"api_base_url"`
+	RateLimit        int           `json:"rate_limit"`
+	RetryAttempts    int           `json:"retry_attempts"`
+	RetryDelay       time.Duration `json:"retry_delay"`
+	RecvWindow       time.Duration `json:"recv_window"`
+	BreakerThreshold int           `json:"breaker_threshold"`
+	BreakerCooldown  time.Duration `json:"breaker_cooldown"`
 }
 
 type TradingConfig struct {
-	MaxPositions     int     `json:"max_positions"`
-	DefaultRiskPct   float64 `json:"default_risk_pct"`
-	MaxDailyLoss     float64 `json:"max_daily_loss"`
-	PositionTimeout  int     `json:"position_timeout_minutes"`
-	SignalBufferSize int     `json:"signal_buffer_size"`
-	PriceBufferSize  int     `json:"price_buffer_size"`
+	Mode             string   `json:"mode"`
+	QuoteAsset       string   `json:"quote_asset"`
+	MaxPositions     int      `json:"max_positions"`
+	DefaultRiskPct   float64  `json:"default_risk_pct"`
+	MaxDailyLoss     float64  `json:"max_daily_loss"`
+	PositionTimeout  int      `json:"position_timeout_minutes"`
+	SignalBufferSize int      `json:"signal_buffer_size"`
+	PriceBufferSize  int      `json:"price_buffer_size"`
+	KlineInterval    string   `json:"kline_interval"`
+	Timeframes       []string `json:"timeframes"`
+	TrendTimeframe   string   `json:"trend_timeframe"`
+	Strategy         string   `json:"strategy"`
+	StrategiesFile   string   `json:"strategies_file"`
+	ShortSelling     bool     `json:"short_selling"`
+	ExchangeFilters  bool     `json:"exchange_filters"`
 	TechnicalPeriods struct {
 		RSI    int `json:"rsi"`
 		EMA9   int `json:"ema9"`
@@ -53,6 +72,23 @@ type TradingConfig struct {
 		EMA50  int `json:"ema50"`
 		EMA200 int `json:"ema200"`
 	} `json:"technical_periods"`
+	Paper     PaperConfig     `json:"paper"`
+	OrderBook OrderBookConfig `json:"order_book"`
+}
+
+// PaperConfig holds the simulation parameters of the paper trading venue
+type PaperConfig struct {
+	StartingBalance float64 `json:"starting_balance"`
+	FeeRate         float64 `json:"fee_rate"`
+	SlippageBps     float64 `json:"slippage_bps"`
+	MaxVolumePct    float64 `json:"max_volume_pct"`
+}
+
+// OrderBookConfig holds the settings of the local order books built from depth streams
+type OrderBookConfig struct {
+	Enabled       bool    `json:"enabled"`
+	SnapshotLimit int     `json:"snapshot_limit"`
+	DepthBps      float64 `json:"depth_bps"`
 }
 
 type DatabaseConfig struct {
@@ -89,15 +125,27 @@ func LoadConfig() (*Config, error) {
 		Environment:     getEnvOrDefault("ENVIRONMENT", "development"),
 	}
 
+	// Execution venue: paper, testnet or live
+	mode := strings.ToLower(getEnvOrDefault("TRADING_MODE", TradingModePaper))
+
 	// Binance configuration
 	isTestnet := strings.ToLower(os.Getenv("BINANCE_TESTNET")) == "true"
+	switch mode {
+	case TradingModeTestnet:
+		isTestnet = true
+	case TradingModeLive:
+		isTestnet = false
+	}
 	config.Binance = BinanceConfig{
-		APIKey:        os.Getenv("BINANCE_API_KEY"),
-		SecretKey:     os.Getenv("BINANCE_SECRET_KEY"),
-		IsTestnet:     isTestnet,
-		RateLimit:     getEnvIntOrDefault("BINANCE_RATE_LIMIT", 1200),
-		RetryAttempts: getEnvIntOrDefault("BINANCE_RETRY_ATTEMPTS", 3),
-		RetryDelay:    getEnvDurationOrDefault("BINANCE_RETRY_DELAY", 1*time.Second),
+		APIKey:           os.Getenv("BINANCE_API_KEY"),
+		SecretKey:        os.Getenv("BINANCE_SECRET_KEY"),
+		IsTestnet:        isTestnet,
+		RateLimit:        getEnvIntOrDefault("BINANCE_RATE_LIMIT", 1200), // Request weight per minute
+		RetryAttempts:    getEnvIntOrDefault("BINANCE_RETRY_ATTEMPTS", 3),
+		RetryDelay:       getEnvDurationOrDefault("BINANCE_RETRY_DELAY", 1*time.Second),
+		RecvWindow:       getEnvDurationOrDefault("BINANCE_RECV_WINDOW", 5*time.Second),
+		BreakerThreshold: getEnvIntOrDefault("BINANCE_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  getEnvDurationOrDefault("BINANCE_BREAKER_COOLDOWN", 30*time.Second),
 	}
 
 	if isTestnet {
@@ -112,12 +160,23 @@ func LoadConfig() (*Config, error) {
 
 	// Trading configuration
 	config.Trading = TradingConfig{
+		Mode:             mode,
+		QuoteAsset:       getEnvOrDefault("QUOTE_ASSET", "USDT"),
 		MaxPositions:     getEnvIntOrDefault("MAX_POSITIONS", 5),
 		DefaultRiskPct:   getEnvFloatOrDefault("DEFAULT_RISK_PCT", 2.0),
 		MaxDailyLoss:     getEnvFloatOrDefault("MAX_DAILY_LOSS", 2500.0),
 		PositionTimeout:  getEnvIntOrDefault("POSITION_TIMEOUT_MINUTES", 30),
 		SignalBufferSize: getEnvIntOrDefault("SIGNAL_BUFFER_SIZE", 1000),
 		PriceBufferSize:  getEnvIntOrDefault("PRICE_BUFFER_SIZE", 1000),
+		KlineInterval:    getEnvOrDefault("KLINE_INTERVAL", "5m"),
+		Timeframes:       getEnvListOrDefault("TIMEFRAMES", []string{"1m", "5m", "15m", "1h", "4h"}),
+		TrendTimeframe:   getEnvOrDefault("TREND_TIMEFRAME", ""),
+		Strategy:         getEnvOrDefault("STRATEGY", "signal"),
+		StrategiesFile:   getEnvOrDefault("STRATEGIES_FILE", ""),
+		// Shorts borrow on margin, which the spot testnet does not offer
+		ShortSelling: getEnvBoolOrDefault("SHORT_SELLING_ENABLED", mode == TradingModePaper),
+		// Round and validate orders against the symbol rules from exchangeInfo
+		ExchangeFilters: getEnvBoolOrDefault("EXCHANGE_FILTERS_ENABLED", true),
 	}
 
 	config.Trading.TechnicalPeriods.RSI = getEnvIntOrDefault("RSI_PERIOD", 14)
@@ -126,6 +185,19 @@ func LoadConfig() (*Config, error) {
 	config.Trading.TechnicalPeriods.EMA50 = getEnvIntOrDefault("EMA50_PERIOD", 50)
 	config.Trading.TechnicalPeriods.EMA200 = getEnvIntOrDefault("EMA200_PERIOD", 200)
 
+	config.Trading.Paper = PaperConfig{
+		StartingBalance: getEnvFloatOrDefault("PAPER_STARTING_BALANCE", 50000),
+		FeeRate:         getEnvFloatOrDefault("PAPER_FEE_RATE", 0.001),
+		SlippageBps:     getEnvFloatOrDefault("PAPER_SLIPPAGE_BPS", 5),
+		MaxVolumePct:    getEnvFloatOrDefault("PAPER_MAX_VOLUME_PCT", 10),
+	}
+
+	config.Trading.OrderBook = OrderBookConfig{
+		Enabled:       getEnvBoolOrDefault("ORDER_BOOK_ENABLED", true),
+		SnapshotLimit: getEnvIntOrDefault("ORDER_BOOK_SNAPSHOT_LIMIT", 1000),
+		DepthBps:      getEnvFloatOrDefault("ORDER_BOOK_DEPTH_BPS", 10),
+	}
+
 	// Database configuration (optional)
 	config.Database = DatabaseConfig{
 		Host:     getEnvOrDefault("DB_HOST", "localhost"),
@@ -154,11 +226,18 @@ func LoadConfig() (*Config, error) {
 
 // Validate checks if all required configuration is present
 func (c *Config) Validate() error {
-	if c.Binance.APIKey == "" {
-		return fmt.Errorf("BINANCE_API_KEY is required")
-	}
-	if c.Binance.SecretKey == "" {
-		return fmt.Errorf("BINANCE_SECRET_KEY is required")
+	switch c.Trading.Mode {
+	case TradingModePaper:
+	case TradingModeTestnet, TradingModeLive:
+		// Signed endpoints are only needed when orders reach Binance
+		if c.Binance.APIKey == "" {
+			return fmt.Errorf("BINANCE_API_KEY is required")
+		}
+		if c.Binance.SecretKey == "" {
+			return fmt.Errorf("BINANCE_SECRET_KEY is required")
+		}
+	default:
+		return fmt.Errorf("TRADING_MODE must be one of %s, %s or %s", TradingModePaper, TradingModeTestnet, TradingModeLive)
 	}
 	if c.Trading.MaxPositions <= 0 {
 		return fmt.Errorf("MAX_POSITIONS must be greater than 0")
@@ -177,6 +256,19 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	return defaultValue
+}
+
 func getEnvIntOrDefault(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -195,6 +287,15 @@ func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -2,13 +2,15 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"trading-engine/logger"
 	"trading-engine/models"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // DB wraps database connection with trading-specific methods
@@ -81,9 +83,15 @@ func (db *DB) InitSchema() error {
 			pnl DECIMAL(20,8),
 			exit_price DECIMAL(20,8),
 			hold_time INTEGER,
+			strategy_id VARCHAR(50),
+			fee DECIMAL(20,8) NOT NULL DEFAULT 0,
 			created_at TIMESTAMP DEFAULT NOW()
 		)`,
 
+		// Added after the table was first created
+		`ALTER TABLE trades ADD COLUMN IF NOT EXISTS strategy_id VARCHAR(50)`,
+		`ALTER TABLE trades ADD COLUMN IF NOT EXISTS fee DECIMAL(20,8) NOT NULL DEFAULT 0`,
+
 		`CREATE TABLE IF NOT EXISTS positions (
 			id VARCHAR(50) PRIMARY KEY,
 			symbol VARCHAR(20) NOT NULL,
@@ -94,11 +102,20 @@ func (db *DB) InitSchema() error {
 			entry_time TIMESTAMP NOT NULL,
 			target_price DECIMAL(20,8),
 			stop_loss_price DECIMAL(20,8),
+			strategy_id VARCHAR(50),
 			is_active BOOLEAN DEFAULT TRUE,
 			created_at TIMESTAMP DEFAULT NOW(),
 			updated_at TIMESTAMP DEFAULT NOW()
 		)`,
 
+		// Added after the table was first created
+		`ALTER TABLE positions ADD COLUMN IF NOT EXISTS strategy_id VARCHAR(50)`,
+		`ALTER TABLE positions ADD COLUMN IF NOT EXISTS initial_quantity DECIMAL(20,8) NOT NULL DEFAULT 0`,
+		`ALTER TABLE positions ADD COLUMN IF NOT EXISTS best_price DECIMAL(20,8) NOT NULL DEFAULT 0`,
+		`ALTER TABLE positions ADD COLUMN IF NOT EXISTS stop_type VARCHAR(20) NOT NULL DEFAULT ''`,
+		`ALTER TABLE positions ADD COLUMN IF NOT EXISTS ladder_step INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE positions ADD COLUMN IF NOT EXISTS entries INTEGER NOT NULL DEFAULT 1`,
+
 		`CREATE TABLE IF NOT EXISTS market_data (
 			id SERIAL PRIMARY KEY,
 			symbol VARCHAR(20) NOT NULL,
@@ -122,6 +139,8 @@ func (db *DB) InitSchema() error {
 			ema200 DECIMAL(20,8),
 			rsi DECIMAL(10,4),
 			macd DECIMAL(20,8),
+			macd_signal DECIMAL(20,8),
+			macd_hist DECIMAL(20,8),
 			vwap DECIMAL(20,8),
 			ma50 DECIMAL(20,8),
 			signal VARCHAR(20),
@@ -130,6 +149,10 @@ func (db *DB) InitSchema() error {
 			created_at TIMESTAMP DEFAULT NOW()
 		)`,
 
+		// Added after the table was first created
+		`ALTER TABLE technical_analysis ADD COLUMN IF NOT EXISTS macd_signal DECIMAL(20,8)`,
+		`ALTER TABLE technical_analysis ADD COLUMN IF NOT EXISTS macd_hist DECIMAL(20,8)`,
+
 		`CREATE TABLE IF NOT EXISTS trading_settings (
 			id SERIAL PRIMARY KEY,
 			min_confidence INTEGER NOT NULL,
@@ -141,11 +164,30 @@ func (db *DB) InitSchema() error {
 			take_profit_percent DECIMAL(10,4) NOT NULL,
 			max_hold_time INTEGER NOT NULL,
 			scaling_factor INTEGER NOT NULL DEFAULT 1,
+			stop_mode VARCHAR(10) NOT NULL DEFAULT 'PERCENT',
+			atr_stop_multiple DECIMAL(10,4) NOT NULL DEFAULT 1.5,
+			atr_target_multiple DECIMAL(10,4) NOT NULL DEFAULT 3,
+			trailing_mode VARCHAR(10) NOT NULL DEFAULT 'OFF',
+			trailing_percent DECIMAL(10,4) NOT NULL DEFAULT 0,
+			trailing_atr_multiple DECIMAL(10,4) NOT NULL DEFAULT 0,
+			break_even_percent DECIMAL(10,4) NOT NULL DEFAULT 0,
+			take_profit_ladder TEXT NOT NULL DEFAULT '[]',
 			is_enabled BOOLEAN DEFAULT FALSE,
 			created_at TIMESTAMP DEFAULT NOW(),
 			updated_at TIMESTAMP DEFAULT NOW()
 		)`,
 
+		// Added after the table was first created
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS stop_mode VARCHAR(10) NOT NULL DEFAULT 'PERCENT'`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS atr_stop_multiple DECIMAL(10,4) NOT NULL DEFAULT 1.5`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS atr_target_multiple DECIMAL(10,4) NOT NULL DEFAULT 3`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS trailing_mode VARCHAR(10) NOT NULL DEFAULT 'OFF'`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS trailing_percent DECIMAL(10,4) NOT NULL DEFAULT 0`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS trailing_atr_multiple DECIMAL(10,4) NOT NULL DEFAULT 0`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS break_even_percent DECIMAL(10,4) NOT NULL DEFAULT 0`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS take_profit_ladder TEXT NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE trading_settings ADD COLUMN IF NOT EXISTS scale_in_profit_percent DECIMAL(10,4) NOT NULL DEFAULT 0`,
+
 		`CREATE TABLE IF NOT EXISTS watchlist (
 			id SERIAL PRIMARY KEY,
 			symbol VARCHAR(20) NOT NULL UNIQUE,
@@ -156,6 +198,12 @@ func (db *DB) InitSchema() error {
 			created_at TIMESTAMP DEFAULT NOW()
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS engine_state (
+			id INTEGER PRIMARY KEY,
+			state TEXT NOT NULL,
+			updated_at TIMESTAMP DEFAULT NOW()
+		)`,
+
 		`CREATE TABLE IF NOT EXISTS performance_metrics (
 			id SERIAL PRIMARY KEY,
 			date DATE NOT NULL,
@@ -184,6 +232,7 @@ func (db *DB) InitSchema() error {
 		`CREATE INDEX IF NOT EXISTS idx_positions_symbol ON positions(symbol)`,
 		`CREATE INDEX IF NOT EXISTS idx_positions_active ON positions(is_active)`,
 		`CREATE INDEX IF NOT EXISTS idx_market_data_symbol_timestamp ON market_data(symbol, timestamp)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_market_data_candle ON market_data(symbol, timeframe, timestamp)`,
 		`CREATE INDEX IF NOT EXISTS idx_technical_analysis_symbol_timestamp ON technical_analysis(symbol, timestamp)`,
 		`CREATE INDEX IF NOT EXISTS idx_performance_metrics_date ON performance_metrics(date)`,
 	}
@@ -201,8 +250,8 @@ func (db *DB) InitSchema() error {
 // SaveTrade saves a trade to the database
 func (db *DB) SaveTrade(trade *models.Trade) error {
 	query := `
-		INSERT INTO trades (id, symbol, type, price, quantity, timestamp, signal, confidence, pnl, exit_price, hold_time)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO trades (id, symbol, type, price, quantity, timestamp, signal, confidence, pnl, exit_price, hold_time, strategy_id, fee)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (id) DO UPDATE SET
 			pnl = EXCLUDED.pnl,
 			exit_price = EXCLUDED.exit_price,
@@ -212,7 +261,7 @@ func (db *DB) SaveTrade(trade *models.Trade) error {
 	_, err := db.conn.Exec(query,
 		trade.ID, trade.Symbol, trade.Type, trade.Price, trade.Quantity,
 		trade.Timestamp, trade.Signal, trade.Confidence,
-		trade.PnL, trade.ExitPrice, trade.HoldTime)
+		trade.PnL, trade.ExitPrice, trade.HoldTime, trade.StrategyID, trade.Fee)
 
 	if err != nil {
 		db.logger.Error("Failed to save trade %s: %v", trade.ID, err)
@@ -230,7 +279,8 @@ func (db *DB) GetTrades(symbol string, limit int) ([]models.Trade, error) {
 	if symbol != "" {
 		query = `
 			SELECT id, symbol, type, price, quantity, timestamp, signal, confidence, 
-				   COALESCE(pnl, 0), COALESCE(exit_price, 0), COALESCE(hold_time, 0)
+				   COALESCE(pnl, 0), COALESCE(exit_price, 0), COALESCE(hold_time, 0),
+				   COALESCE(strategy_id, ''), fee
 			FROM trades 
 			WHERE symbol = $1 
 			ORDER BY timestamp DESC 
@@ -240,7 +290,8 @@ func (db *DB) GetTrades(symbol string, limit int) ([]models.Trade, error) {
 	} else {
 		query = `
 			SELECT id, symbol, type, price, quantity, timestamp, signal, confidence,
-				   COALESCE(pnl, 0), COALESCE(exit_price, 0), COALESCE(hold_time, 0)
+				   COALESCE(pnl, 0), COALESCE(exit_price, 0), COALESCE(hold_time, 0),
+				   COALESCE(strategy_id, ''), fee
 			FROM trades 
 			ORDER BY timestamp DESC 
 			LIMIT $1
@@ -263,7 +314,7 @@ func (db *DB) GetTrades(symbol string, limit int) ([]models.Trade, error) {
 		err := rows.Scan(
 			&trade.ID, &trade.Symbol, &trade.Type, &trade.Price, &trade.Quantity,
 			&trade.Timestamp, &trade.Signal, &trade.Confidence,
-			&pnl, &exitPrice, &holdTime)
+			&pnl, &exitPrice, &holdTime, &trade.StrategyID, &trade.Fee)
 
 		if err != nil {
 			return nil, err
@@ -289,21 +340,30 @@ func (db *DB) GetTrades(symbol string, limit int) ([]models.Trade, error) {
 func (db *DB) SavePosition(position *models.Position) error {
 	query := `
 		INSERT INTO positions (id, symbol, quantity, avg_buy_price, current_value, unrealized_pnl, 
-							   entry_time, target_price, stop_loss_price, is_active, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+							   entry_time, target_price, stop_loss_price, strategy_id, initial_quantity,
+							   best_price, stop_type, ladder_step, entries, is_active, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
 		ON CONFLICT (id) DO UPDATE SET
+			quantity = EXCLUDED.quantity,
+			avg_buy_price = EXCLUDED.avg_buy_price,
 			current_value = EXCLUDED.current_value,
 			unrealized_pnl = EXCLUDED.unrealized_pnl,
 			target_price = EXCLUDED.target_price,
 			stop_loss_price = EXCLUDED.stop_loss_price,
+			best_price = EXCLUDED.best_price,
+			stop_type = EXCLUDED.stop_type,
+			ladder_step = EXCLUDED.ladder_step,
+			entries = EXCLUDED.entries,
 			is_active = EXCLUDED.is_active,
 			updated_at = NOW()
 	`
 
+	// entry_time has no time zone, so it is stored in UTC to read back the same instant
 	_, err := db.conn.Exec(query,
 		position.ID, position.Symbol, position.Quantity, position.AvgBuyPrice,
-		position.CurrentValue, position.UnrealizedPnL, position.EntryTime,
-		position.TargetPrice, position.StopLossPrice, true)
+		position.CurrentValue, position.UnrealizedPnL, position.EntryTime.UTC(),
+		position.TargetPrice, position.StopLossPrice, position.StrategyID, position.InitialQuantity,
+		position.BestPrice, position.StopType, position.LadderStep, position.Entries, true)
 
 	if err != nil {
 		db.logger.Error("Failed to save position %s: %v", position.ID, err)
@@ -313,11 +373,44 @@ func (db *DB) SavePosition(position *models.Position) error {
 	return nil
 }
 
+// SyncPositions saves the open positions and deactivates every other active position
+func (db *DB) SyncPositions(positions []models.Position) error {
+	ids := make([]string, 0, len(positions))
+	for i := range positions {
+		if err := db.SavePosition(&positions[i]); err != nil {
+			return err
+		}
+		ids = append(ids, positions[i].ID)
+	}
+
+	query := `UPDATE positions SET is_active = FALSE, updated_at = NOW() WHERE is_active = TRUE AND NOT (id = ANY($1))`
+
+	if _, err := db.conn.Exec(query, pq.Array(ids)); err != nil {
+		db.logger.Error("Failed to deactivate closed positions: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeactivatePosition marks a position as closed
+func (db *DB) DeactivatePosition(id string) error {
+	query := `UPDATE positions SET is_active = FALSE, updated_at = NOW() WHERE id = $1`
+
+	if _, err := db.conn.Exec(query, id); err != nil {
+		db.logger.Error("Failed to deactivate position %s: %v", id, err)
+		return err
+	}
+
+	return nil
+}
+
 // GetActivePositions retrieves active positions from the database
 func (db *DB) GetActivePositions() ([]models.Position, error) {
 	query := `
 		SELECT id, symbol, quantity, avg_buy_price, current_value, unrealized_pnl,
-			   entry_time, target_price, stop_loss_price
+			   entry_time, target_price, stop_loss_price, COALESCE(strategy_id, ''),
+			   initial_quantity, best_price, stop_type, ladder_step, entries
 		FROM positions 
 		WHERE is_active = TRUE
 		ORDER BY entry_time DESC
@@ -337,7 +430,9 @@ func (db *DB) GetActivePositions() ([]models.Position, error) {
 		err := rows.Scan(
 			&position.ID, &position.Symbol, &position.Quantity, &position.AvgBuyPrice,
 			&position.CurrentValue, &position.UnrealizedPnL, &position.EntryTime,
-			&targetPrice, &stopLossPrice)
+			&targetPrice, &stopLossPrice, &position.StrategyID,
+			&position.InitialQuantity, &position.BestPrice, &position.StopType, &position.LadderStep,
+			&position.Entries)
 
 		if err != nil {
 			return nil, err
@@ -369,39 +464,126 @@ func (db *DB) ClosePosition(positionID string) error {
 	return nil
 }
 
-// SaveMarketData saves market data to the database
-func (db *DB) SaveMarketData(candle *models.Candle) error {
+// marketDataBatchSize is the number of candles written per INSERT, keeping the
+// statement well under the PostgreSQL limit of 65535 parameters
+const marketDataBatchSize = 500
+
+// SaveMarketData saves candles of a timeframe to the database in a single transaction.
+// Candles already stored for the same symbol, timeframe and open time are skipped.
+func (db *DB) SaveMarketData(timeframe string, candles []models.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin market data transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(candles); start += marketDataBatchSize {
+		end := start + marketDataBatchSize
+		if end > len(candles) {
+			end = len(candles)
+		}
+
+		var query strings.Builder
+		query.WriteString(`INSERT INTO market_data (symbol, price, volume, timestamp, timeframe,
+			open_price, high_price, low_price, close_price) VALUES `)
+		args := make([]interface{}, 0, (end-start)*9)
+		for i, candle := range candles[start:end] {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9)
+			args = append(args, candle.Symbol, candle.Close, candle.Volume, candle.Timestamp.UTC(), timeframe,
+				candle.Open, candle.High, candle.Low, candle.Close)
+		}
+		query.WriteString(" ON CONFLICT DO NOTHING")
+
+		if _, err := tx.Exec(query.String(), args...); err != nil {
+			db.logger.Error("Failed to save market data for %s: %v", candles[start].Symbol, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit market data: %w", err)
+	}
+	return nil
+}
+
+// GetMarketData retrieves stored candles for a symbol and timeframe in chronological order
+func (db *DB) GetMarketData(symbol, timeframe string, start, end time.Time) ([]models.Candle, error) {
 	query := `
-		INSERT INTO market_data (symbol, price, volume, timestamp, timeframe, 
-								open_price, high_price, low_price, close_price)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT DO NOTHING
+		SELECT COALESCE(open_price, price), COALESCE(high_price, price), COALESCE(low_price, price),
+			   COALESCE(close_price, price), volume, timestamp
+		FROM market_data
+		WHERE symbol = $1 AND timeframe = $2 AND timestamp >= $3 AND timestamp < $4
+		ORDER BY timestamp ASC
 	`
 
-	_, err := db.conn.Exec(query,
-		candle.Symbol, candle.Close, candle.Volume, candle.Timestamp, "5m",
-		candle.Open, candle.High, candle.Low, candle.Close)
+	rows, err := db.conn.Query(query, symbol, timeframe, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	var candles []models.Candle
+	for rows.Next() {
+		candle := models.Candle{Symbol: symbol}
+		err := rows.Scan(&candle.Open, &candle.High, &candle.Low, &candle.Close, &candle.Volume, &candle.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		candle.Time = candle.Timestamp.Unix()
+		candles = append(candles, candle)
+	}
+
+	return candles, rows.Err()
+}
+
+// GetMarketDataTimestamps returns the open times of the stored candles of a symbol and timeframe in a range
+func (db *DB) GetMarketDataTimestamps(symbol, timeframe string, start, end time.Time) ([]time.Time, error) {
+	query := `
+		SELECT timestamp FROM market_data
+		WHERE symbol = $1 AND timeframe = $2 AND timestamp >= $3 AND timestamp < $4
+		ORDER BY timestamp ASC
+	`
+
+	rows, err := db.conn.Query(query, symbol, timeframe, start.UTC(), end.UTC())
 	if err != nil {
-		db.logger.Error("Failed to save market data for %s: %v", candle.Symbol, err)
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
+	var timestamps []time.Time
+	for rows.Next() {
+		var timestamp time.Time
+		if err := rows.Scan(&timestamp); err != nil {
+			return nil, err
+		}
+		timestamps = append(timestamps, timestamp)
+	}
+
+	return timestamps, rows.Err()
 }
 
 // SaveTechnicalAnalysis saves technical analysis to the database
 func (db *DB) SaveTechnicalAnalysis(symbol string, analysis *models.TechnicalAnalysis) error {
 	query := `
-		INSERT INTO technical_analysis (symbol, ema9, ema21, ema50, ema200, rsi, macd, vwap, 
-										ma50, signal, confidence, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+		INSERT INTO technical_analysis (symbol, ema9, ema21, ema50, ema200, rsi, macd, macd_signal,
+										macd_hist, vwap, ma50, signal, confidence, timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
 	`
 
 	_, err := db.conn.Exec(query,
 		symbol, analysis.EMA9, analysis.EMA21, analysis.EMA50, analysis.EMA200,
-		analysis.RSI, analysis.MACD, analysis.VWAP, analysis.MA50,
-		analysis.Signal, analysis.Confidence)
+		analysis.RSI, analysis.MACD, analysis.MACDSignal, analysis.MACDHist,
+		analysis.VWAP, analysis.MA50, analysis.Signal, analysis.Confidence)
 
 	if err != nil {
 		db.logger.Error("Failed to save technical analysis for %s: %v", symbol, err)
@@ -417,15 +599,38 @@ func (db *DB) SaveTradingSettings(settings *models.TradingSettings) error {
 		INSERT INTO trading_settings (min_confidence, max_position_size, risk_per_trade, 
 									  max_daily_loss, max_positions, stop_loss_percent, 
 									  take_profit_percent, max_hold_time, scaling_factor, 
+									  stop_mode, atr_stop_multiple, atr_target_multiple,
+									  trailing_mode, trailing_percent, trailing_atr_multiple,
+									  break_even_percent, take_profit_ladder, scale_in_profit_percent,
 									  is_enabled, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, NOW())
 	`
 
-	_, err := db.conn.Exec(query,
+	stopMode := settings.StopMode
+	if stopMode == "" {
+		stopMode = models.StopModePercent
+	}
+	trailingMode := settings.TrailingMode
+	if trailingMode == "" {
+		trailingMode = models.TrailingModeOff
+	}
+
+	ladder := settings.TakeProfitLadder
+	if ladder == nil {
+		ladder = []models.TakeProfitStep{}
+	}
+	ladderJSON, err := json.Marshal(ladder)
+	if err != nil {
+		return fmt.Errorf("failed to marshal take-profit ladder: %w", err)
+	}
+
+	_, err = db.conn.Exec(query,
 		settings.MinConfidence, settings.MaxPositionSize, settings.RiskPerTrade,
 		settings.MaxDailyLoss, settings.MaxPositions, settings.StopLossPercent,
 		settings.TakeProfitPercent, settings.MaxHoldTime, settings.ScalingFactor,
-		settings.IsEnabled)
+		stopMode, settings.ATRStopMultiple, settings.ATRTargetMultiple,
+		trailingMode, settings.TrailingPercent, settings.TrailingATRMultiple,
+		settings.BreakEvenPercent, string(ladderJSON), settings.ScaleInProfitPercent, settings.IsEnabled)
 
 	if err != nil {
 		db.logger.Error("Failed to save trading settings: %v", err)
@@ -440,18 +645,23 @@ func (db *DB) GetLatestTradingSettings() (*models.TradingSettings, error) {
 	query := `
 		SELECT min_confidence, max_position_size, risk_per_trade, max_daily_loss,
 			   max_positions, stop_loss_percent, take_profit_percent, max_hold_time,
-			   scaling_factor, is_enabled
+			   scaling_factor, stop_mode, atr_stop_multiple, atr_target_multiple,
+			   trailing_mode, trailing_percent, trailing_atr_multiple, break_even_percent,
+			   take_profit_ladder, scale_in_profit_percent, is_enabled
 		FROM trading_settings 
 		ORDER BY created_at DESC 
 		LIMIT 1
 	`
 
 	var settings models.TradingSettings
+	var ladderJSON string
 	err := db.conn.QueryRow(query).Scan(
 		&settings.MinConfidence, &settings.MaxPositionSize, &settings.RiskPerTrade,
 		&settings.MaxDailyLoss, &settings.MaxPositions, &settings.StopLossPercent,
 		&settings.TakeProfitPercent, &settings.MaxHoldTime, &settings.ScalingFactor,
-		&settings.IsEnabled)
+		&settings.StopMode, &settings.ATRStopMultiple, &settings.ATRTargetMultiple,
+		&settings.TrailingMode, &settings.TrailingPercent, &settings.TrailingATRMultiple,
+		&settings.BreakEvenPercent, &ladderJSON, &settings.ScaleInProfitPercent, &settings.IsEnabled)
 
 	if err == sql.ErrNoRows {
 		// Return default settings if none found
@@ -463,6 +673,10 @@ func (db *DB) GetLatestTradingSettings() (*models.TradingSettings, error) {
 			MaxPositions:      5,
 			StopLossPercent:   1.0,
 			TakeProfitPercent: 1.5,
+			StopMode:          models.StopModePercent,
+			ATRStopMultiple:   1.5,
+			ATRTargetMultiple: 3.0,
+			TrailingMode:      models.TrailingModeOff,
 			MaxHoldTime:       60,
 			ScalingFactor:     1,
 			IsEnabled:         false,
@@ -473,9 +687,55 @@ func (db *DB) GetLatestTradingSettings() (*models.TradingSettings, error) {
 		return nil, err
 	}
 
+	if err := json.Unmarshal([]byte(ladderJSON), &settings.TakeProfitLadder); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal take-profit ladder: %w", err)
+	}
+
 	return &settings, nil
 }
 
+// SaveEngineState saves the engine state kept outside the positions and settings tables
+func (db *DB) SaveEngineState(state *models.EngineState) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal engine state: %w", err)
+	}
+
+	query := `
+		INSERT INTO engine_state (id, state, updated_at)
+		VALUES (1, $1, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			state = EXCLUDED.state,
+			updated_at = NOW()
+	`
+
+	if _, err := db.conn.Exec(query, string(stateJSON)); err != nil {
+		db.logger.Error("Failed to save engine state: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetEngineState retrieves the last saved engine state, or nil if none was saved
+func (db *DB) GetEngineState() (*models.EngineState, error) {
+	var stateJSON string
+	err := db.conn.QueryRow(`SELECT state FROM engine_state WHERE id = 1`).Scan(&stateJSON)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state models.EngineState
+	if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal engine state: %w", err)
+	}
+
+	return &state, nil
+}
+
 // UpdateWatchlist updates or inserts watchlist items
 func (db *DB) UpdateWatchlist(items []models.WatchlistItem) error {
 	tx, err := db.conn.Begin()
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/config"
+	"trading-engine/database"
+	"trading-engine/history"
+	"trading-engine/logger"
+)
+
+// runDownloadCommand backfills market_data with historical klines and prints a JSON summary
+// per symbol. Interrupting it keeps the pages already saved, so rerunning it resumes.
+func runDownloadCommand(args []string) int {
+	flags := flag.NewFlagSet("download", flag.ExitOnError)
+	symbols := flags.String("symbols", "BTCUSDT", "comma separated symbols to download")
+	interval := flags.String("interval", "5m", "kline interval")
+	from := flags.String("from", "", "start of the range to download (RFC3339, required)")
+	to := flags.String("to", "", "end of the range to download (RFC3339, defaults to now)")
+	flags.Parse(args)
+
+	if *from == "" {
+		fmt.Fprintln(os.Stderr, "The -from flag is required")
+		return 1
+	}
+	start, err := time.Parse(time.RFC3339, *from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -from time: %v\n", err)
+		return 1
+	}
+	end := time.Now()
+	if *to != "" {
+		if end, err = time.Parse(time.RFC3339, *to); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -to time: %v\n", err)
+			return 1
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	log, err := logger.NewLogger("download", logger.INFO, "./logs")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return 1
+	}
+	defer log.Close()
+
+	db, err := database.NewDB(&database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.Name,
+		SSLMode:  cfg.Database.SSLMode,
+	}, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	downloader := history.NewDownloader(binance.NewClient(&cfg.Binance, log), db, log)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	for _, symbol := range strings.Split(*symbols, ",") {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" {
+			continue
+		}
+
+		result, err := downloader.Download(ctx, symbol, *interval, start, end)
+		if result != nil {
+			encoder.Encode(result)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Download of %s failed: %v\n", symbol, err)
+			return 1
+		}
+	}
+
+	return 0
+}
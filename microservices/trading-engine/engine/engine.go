@@ -2,31 +2,83 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"trading-engine/binance"
+	"trading-engine/clock"
 	"trading-engine/config"
+	"trading-engine/events"
+	"trading-engine/execution"
 	"trading-engine/logger"
 	"trading-engine/models"
+	"trading-engine/orderbook"
+	"trading-engine/strategy"
 	"trading-engine/technical"
 	"trading-engine/utils"
 )
 
+const (
+	// klineUpdateBufferSize is the number of kline updates queued between the WebSocket and the engine
+	klineUpdateBufferSize = 1024
+
+	// streamEventBufferSize is the number of stream connection state changes queued for the engine
+	streamEventBufferSize = 16
+
+	// userDataBufferSize is the number of order and balance updates queued for the engine
+	userDataBufferSize = 256
+
+	// unconfirmedOrderTTL is how long an order whose request failed without a response is
+	// tracked for fills that show it reached the exchange after all
+	unconfirmedOrderTTL = 10 * time.Minute
+)
+
 // Engine represents the main trading engine
 type Engine struct {
 	config         *config.Config
 	logger         *logger.Logger
+	clock          clock.Clock
 	binanceClient  *binance.Client
 	wsClient       *binance.WebSocketClient
+	orderBooks     *orderbook.Manager
+	userData       *binance.UserDataStream
+	marginUserData *binance.UserDataStream // Fills of short positions on the cross margin account
+	executor       execution.Executor
 	techAnalyzer   *technical.Analyzer
 	tradingState   *models.TradingState
-	dataBuffers    map[string][]models.Candle
+	timeframes     []timeframe
+	dataBuffers    map[string]map[string][]models.Candle
+	buckets        map[string]bucket
+	analysisQueues map[string]chan map[string][]models.Candle // Buffers awaiting analysis per symbol, fed by the kline loop
 	subscribers    map[string][]chan models.LiveTicker
-	positionTimers map[string]*time.Timer
+	positionTimers map[string]clock.Timer
+	dayTimer       clock.Timer // Fires at midnight UTC to reset the daily P&L
 	lastTradeTime  map[string]time.Time
+	pendingOrders  map[string]bool
+
+	// Strategy instances deciding entries and exits, with the trades not passed to them yet
+	strategies   []*strategyRunner
+	filledTrades []models.Trade
+	pnlDay       time.Time // Midnight UTC starting the day the daily P&L is counted for
+
+	// Lifecycle events, with the risk limits currently blocking entries keyed by strategy
+	events     *events.Bus
+	riskLimits map[string]bool
+
+	// Fill tracking for orders routed to Binance, maintained from the user data stream
+	quoteBalance    models.Balance
+	marginQuote     models.Balance     // Part of quoteBalance held on the cross margin account
+	heldBack        float64            // Free quote balance held back for restored positions until balances are loaded
+	orderFills      map[string]float64 // Keyed by client order ID, assigned before an order is sent
+	orderStrategies map[string]string
+	orderPrefix     string
+	orderSeq        int64
+	deferredReports map[string][]models.ExecutionReport
 
 	// Mutexes for thread safety
 	stateMutex       sync.RWMutex
@@ -42,6 +94,16 @@ type Engine struct {
 
 // NewEngine creates a new trading engine instance
 func NewEngine(cfg *config.Config, log *logger.Logger) (*Engine, error) {
+	return NewEngineWithClock(cfg, log, clock.New())
+}
+
+// NewEngineWithClock creates a new trading engine instance driven by the given clock
+func NewEngineWithClock(cfg *config.Config, log *logger.Logger, clk clock.Clock) (*Engine, error) {
+	timeframes, err := resolveTimeframes(&cfg.Trading)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize Binance clients
 	binanceClient := binance.NewClient(&cfg.Binance, log)
 	wsClient := binance.NewWebSocketClient(&cfg.Binance, log)
@@ -56,30 +118,46 @@ func NewEngine(cfg *config.Config, log *logger.Logger) (*Engine, error) {
 		MinConfidence: 60,
 		CacheDuration: 30 * time.Second,
 	}
-	techAnalyzer := technical.NewAnalyzer(techConfig)
+	techAnalyzer := technical.NewAnalyzerWithClock(techConfig, clk)
+
+	// Local order books are maintained from depth streams once the engine starts
+	var orderBooks *orderbook.Manager
+	if cfg.Trading.OrderBook.Enabled {
+		orderBooks = orderbook.NewManager(&cfg.Trading.OrderBook, binanceClient, wsClient, clk, log)
+		techAnalyzer.SetOrderBookSource(orderBooks)
+	}
+
+	// Orders sent to Binance are filled asynchronously, reported on the user data stream
+	var userData, marginUserData *binance.UserDataStream
+	if cfg.Trading.Mode != config.TradingModePaper {
+		userData = binance.NewUserDataStream(binanceClient, &cfg.Binance, log)
+		if cfg.Trading.ShortSelling {
+			marginUserData = binance.NewMarginUserDataStream(binanceClient, &cfg.Binance, log)
+		}
+	}
 
 	// Initialize default watchlist
 	defaultWatchlist := []models.WatchlistItem{
-		{Symbol: "BTCUSDT", Name: "Bitcoin", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "ETHUSDT", Name: "Ethereum", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "BNBUSDT", Name: "BNB", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "XRPUSDT", Name: "Ripple", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "ADAUSDT", Name: "Cardano", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "SOLUSDT", Name: "Solana", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "DOGEUSDT", Name: "Dogecoin", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "TRXUSDT", Name: "TRON", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "MATICUSDT", Name: "Polygon", IsActive: true, LastUpdate: time.Now()},
-		{Symbol: "DOTUSDT", Name: "Polkadot", IsActive: true, LastUpdate: time.Now()},
-	}
-
-	// Initialize trading state
+		{Symbol: "BTCUSDT", Name: "Bitcoin", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "ETHUSDT", Name: "Ethereum", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "BNBUSDT", Name: "BNB", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "XRPUSDT", Name: "Ripple", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "ADAUSDT", Name: "Cardano", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "SOLUSDT", Name: "Solana", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "DOGEUSDT", Name: "Dogecoin", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "TRXUSDT", Name: "TRON", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "MATICUSDT", Name: "Polygon", IsActive: true, LastUpdate: clk.Now()},
+		{Symbol: "DOTUSDT", Name: "Polkadot", IsActive: true, LastUpdate: clk.Now()},
+	}
+
+	// Initialize trading state, balances are loaded from the execution venue on start
 	tradingState := &models.TradingState{
 		Trades:           []models.Trade{},
 		Positions:        []models.Position{},
 		TotalPnL:         0,
 		DayPnL:           0,
-		TradingBalance:   50000,
-		AvailableBalance: 50000,
+		TradingBalance:   0,
+		AvailableBalance: 0,
 		Watchlist:        defaultWatchlist,
 		Settings: models.TradingSettings{
 			MinConfidence:     60,
@@ -89,6 +167,10 @@ func NewEngine(cfg *config.Config, log *logger.Logger) (*Engine, error) {
 			MaxPositions:      cfg.Trading.MaxPositions,
 			StopLossPercent:   1.0,
 			TakeProfitPercent: 1.5,
+			StopMode:          models.StopModePercent,
+			ATRStopMultiple:   1.5,
+			ATRTargetMultiple: 3.0,
+			TrailingMode:      models.TrailingModeOff,
 			MaxHoldTime:       cfg.Trading.PositionTimeout,
 			ScalingFactor:     1,
 			IsEnabled:         false,
@@ -96,18 +178,51 @@ func NewEngine(cfg *config.Config, log *logger.Logger) (*Engine, error) {
 	}
 
 	engine := &Engine{
-		config:         cfg,
-		logger:         log,
-		binanceClient:  binanceClient,
-		wsClient:       wsClient,
-		techAnalyzer:   techAnalyzer,
-		tradingState:   tradingState,
-		dataBuffers:    make(map[string][]models.Candle),
-		subscribers:    make(map[string][]chan models.LiveTicker),
-		positionTimers: make(map[string]*time.Timer),
-		lastTradeTime:  make(map[string]time.Time),
-		stopChan:       make(chan struct{}),
-		tradingEnabled: false,
+		config:          cfg,
+		logger:          log,
+		clock:           clk,
+		binanceClient:   binanceClient,
+		wsClient:        wsClient,
+		orderBooks:      orderBooks,
+		userData:        userData,
+		marginUserData:  marginUserData,
+		techAnalyzer:    techAnalyzer,
+		tradingState:    tradingState,
+		timeframes:      timeframes,
+		dataBuffers:     make(map[string]map[string][]models.Candle),
+		buckets:         make(map[string]bucket),
+		analysisQueues:  make(map[string]chan map[string][]models.Candle),
+		subscribers:     make(map[string][]chan models.LiveTicker),
+		positionTimers:  make(map[string]clock.Timer),
+		lastTradeTime:   make(map[string]time.Time),
+		pendingOrders:   make(map[string]bool),
+		orderFills:      make(map[string]float64),
+		orderStrategies: make(map[string]string),
+		orderPrefix:     "te" + strconv.FormatInt(clk.Now().UnixMilli(), 36),
+		events:          events.NewBus(log),
+		riskLimits:      make(map[string]bool),
+		pnlDay:          utcDay(clk.Now()),
+		deferredReports: make(map[string][]models.ExecutionReport),
+		stopChan:        make(chan struct{}),
+		tradingEnabled:  false,
+	}
+
+	// Initialize the execution venue, paper fills are simulated from the data buffers
+	executor, err := execution.NewExecutor(cfg, binanceClient, engine, clk, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize executor: %w", err)
+	}
+	engine.executor = executor
+
+	// Without a strategies file the configured strategy trades the whole balance alone
+	instances := []models.StrategyInstance{{ID: defaultStrategyID, Strategy: cfg.Trading.Strategy}}
+	if cfg.Trading.StrategiesFile != "" {
+		if instances, err = loadStrategyInstances(cfg.Trading.StrategiesFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := engine.SetStrategies(instances); err != nil {
+		return nil, fmt.Errorf("failed to configure strategies: %w", err)
 	}
 
 	return engine, nil
@@ -122,21 +237,52 @@ func (e *Engine) Start(ctx context.Context) error {
 		return fmt.Errorf("Binance health check failed: %w", err)
 	}
 
+	// Align request timestamps with the exchange before sending signed orders
+	if err := e.binanceClient.SyncServerTime(ctx); err != nil {
+		e.logger.Warn("Failed to synchronize Binance server time: %v", err)
+	}
+
+	// Load account balances from the execution venue
+	if err := e.syncBalances(ctx); err != nil {
+		return fmt.Errorf("failed to load balances from %s venue: %w", e.executor.Name(), err)
+	}
+
+	// Follow fills and balance changes on the exchange
+	if e.userData != nil {
+		if err := e.startUserData(ctx); err != nil {
+			return fmt.Errorf("failed to start user data stream: %w", err)
+		}
+	}
+
 	// Initialize historical data
 	if err := e.initializeHistoricalData(ctx); err != nil {
 		e.logger.Warn("Failed to initialize historical data: %v", err)
 	}
 
+	// Restored positions time out when they would have before the restart, once prices are known
+	e.resumePositionTimers()
+
+	// The daily loss limit counts from midnight UTC
+	e.scheduleDayRollover()
+
 	// Start data fetching
 	go e.startDataFetching(ctx)
 
+	// Start order book maintenance
+	if e.orderBooks != nil {
+		e.orderBooks.Start(ctx, e.watchlistSymbols())
+	}
+
 	// Start trading loop
 	go e.startTradingLoop(ctx)
 
 	// Start position monitoring
 	go e.startPositionMonitoring(ctx)
 
-	e.logger.Info("Trading engine started successfully")
+	e.logger.WithFields(map[string]interface{}{
+		"venue":      e.executor.Name(),
+		"strategies": len(e.runners()),
+	}).Info("Trading engine started successfully")
 	return nil
 }
 
@@ -146,6 +292,21 @@ func (e *Engine) Stop() error {
 
 	close(e.stopChan)
 
+	if e.orderBooks != nil {
+		e.orderBooks.Stop()
+	}
+
+	if e.userData != nil {
+		if err := e.userData.Close(); err != nil {
+			e.logger.Error("Error closing user data stream: %v", err)
+		}
+	}
+	if e.marginUserData != nil {
+		if err := e.marginUserData.Close(); err != nil {
+			e.logger.Error("Error closing margin user data stream: %v", err)
+		}
+	}
+
 	// Close WebSocket connections
 	if err := e.wsClient.Close(); err != nil {
 		e.logger.Error("Error closing WebSocket connections: %v", err)
@@ -157,13 +318,87 @@ func (e *Engine) Stop() error {
 		timer.Stop()
 		e.logger.Debug("Cancelled timer for position: %s", symbol)
 	}
-	e.positionTimers = make(map[string]*time.Timer)
+	e.positionTimers = make(map[string]clock.Timer)
+	if e.dayTimer != nil {
+		e.dayTimer.Stop()
+		e.dayTimer = nil
+	}
 	e.timersMutex.Unlock()
 
 	e.logger.Info("Trading engine stopped")
 	return nil
 }
 
+// syncBalances sets the trading balance from the quote asset held on the execution venue
+func (e *Engine) syncBalances(ctx context.Context) error {
+	balances, err := e.executor.GetBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	quote := execution.FindBalance(balances, e.config.Trading.QuoteAsset)
+
+	// The spot and the margin stream each report their own account, so the split is kept
+	marginQuote := models.Balance{Asset: e.config.Trading.QuoteAsset}
+	if e.marginUserData != nil {
+		marginBalances, err := e.binanceClient.GetMarginBalances(ctx)
+		if err != nil {
+			return err
+		}
+		marginQuote = execution.FindBalance(marginBalances, e.config.Trading.QuoteAsset)
+	}
+
+	e.stateMutex.Lock()
+	e.quoteBalance = quote
+	e.marginQuote = marginQuote
+	e.tradingState.TradingBalance = quote.Free + quote.Locked
+	e.tradingState.AvailableBalance = quote.Free - e.heldBack
+	e.heldBack = 0
+	e.stateMutex.Unlock()
+
+	e.logger.WithFields(map[string]interface{}{
+		"venue":  e.executor.Name(),
+		"asset":  quote.Asset,
+		"free":   quote.Free,
+		"locked": quote.Locked,
+		"assets": len(balances),
+	}).Info("Loaded account balances")
+
+	return nil
+}
+
+// OrderBook returns the liquidity of a symbol's local order book
+func (e *Engine) OrderBook(symbol string) (models.OrderBookSummary, bool) {
+	if e.orderBooks == nil {
+		return models.OrderBookSummary{}, false
+	}
+	return e.orderBooks.Summary(symbol)
+}
+
+// watchlistSymbols returns the symbols on the watchlist
+func (e *Engine) watchlistSymbols() []string {
+	e.stateMutex.RLock()
+	defer e.stateMutex.RUnlock()
+
+	symbols := make([]string, len(e.tradingState.Watchlist))
+	for i, item := range e.tradingState.Watchlist {
+		symbols[i] = item.Symbol
+	}
+	return symbols
+}
+
+// LatestCandle returns the most recent base timeframe candle buffered for a symbol
+func (e *Engine) LatestCandle(symbol string) (models.Candle, bool) {
+	e.buffersMutex.RLock()
+	defer e.buffersMutex.RUnlock()
+
+	buffer := e.dataBuffers[symbol][e.baseTimeframe()]
+	if len(buffer) == 0 {
+		return models.Candle{}, false
+	}
+	return buffer[len(buffer)-1], true
+}
+
 // initializeHistoricalData fetches historical data for all watchlist symbols
 func (e *Engine) initializeHistoricalData(ctx context.Context) error {
 	e.logger.Info("Initializing historical data...")
@@ -182,29 +417,40 @@ func (e *Engine) initializeHistoricalData(ctx context.Context) error {
 		default:
 		}
 
-		candles, err := e.binanceClient.FetchHistoricalKlines(ctx, symbol, "5m", 200)
-		if err != nil {
+		if err := e.loadHistoricalCandles(ctx, symbol); err != nil {
 			e.logger.Error("Failed to fetch historical data for %s: %v", symbol, err)
-			continue
 		}
-
-		e.buffersMutex.Lock()
-		e.dataBuffers[symbol] = candles
-		e.buffersMutex.Unlock()
-
-		e.logger.Debug("Loaded %d historical candles for %s", len(candles), symbol)
 	}
 
 	e.logger.Info("Historical data initialization completed")
 	return nil
 }
 
-// startDataFetching starts the real-time data fetching routine
+// startDataFetching subscribes to kline streams for all watchlist symbols and applies their updates
 func (e *Engine) startDataFetching(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+	e.stateMutex.RLock()
+	symbols := make([]string, len(e.tradingState.Watchlist))
+	for i, item := range e.tradingState.Watchlist {
+		symbols[i] = item.Symbol
+	}
+	e.stateMutex.RUnlock()
+
+	updates := make(chan models.KlineUpdate, klineUpdateBufferSize)
+	interval := e.baseTimeframe()
 
-	e.logger.Info("Starting real-time data fetching")
+	states := make(chan models.StreamConnectionEvent, streamEventBufferSize)
+	e.wsClient.AddStateSubscriber(states)
+
+	for _, symbol := range symbols {
+		if err := e.wsClient.SubscribeKlines(symbol, interval, updates); err != nil {
+			e.logger.Error("Failed to subscribe to %s klines for %s: %v", interval, symbol, err)
+		}
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"symbols":  len(symbols),
+		"interval": interval,
+	}).Info("Starting real-time kline ingestion")
 
 	for {
 		select {
@@ -212,72 +458,143 @@ func (e *Engine) startDataFetching(ctx context.Context) {
 			return
 		case <-e.stopChan:
 			return
-		case <-ticker.C:
-			e.updateRealTimeData(ctx)
+		case update := <-updates:
+			e.updateRealTimeData(ctx, update)
+		case event := <-states:
+			e.handleStreamEvent(event)
 		}
 	}
 }
 
-// updateRealTimeData fetches and updates real-time market data
-func (e *Engine) updateRealTimeData(ctx context.Context) {
-	e.stateMutex.RLock()
-	symbols := make([]string, len(e.tradingState.Watchlist))
-	for i, item := range e.tradingState.Watchlist {
-		symbols[i] = item.Symbol
-	}
-	e.stateMutex.RUnlock()
+// handleStreamEvent logs market stream connection state changes
+func (e *Engine) handleStreamEvent(event models.StreamConnectionEvent) {
+	fields := e.logger.WithFields(map[string]interface{}{
+		"connection_id": event.ConnectionID,
+		"state":         event.State,
+		"streams":       event.Streams,
+		"attempt":       event.Attempt,
+	})
 
-	if len(symbols) == 0 {
-		return
+	switch event.State {
+	case models.StreamReconnecting:
+		fields.Warn("Market data stream interrupted: %s", event.Error)
+	case models.StreamConnected:
+		if event.Attempt > 0 {
+			fields.Info("Market data stream restored")
+		}
 	}
+}
 
-	prices, err := e.binanceClient.FetchPrices(ctx, symbols)
-	if err != nil {
-		e.logger.Error("Failed to fetch real-time prices: %v", err)
-		return
-	}
+// StreamStatus returns the state of the market data stream connections
+func (e *Engine) StreamStatus() []models.StreamConnectionStatus {
+	return e.wsClient.ConnectionStatus()
+}
 
-	// Update data buffers and perform technical analysis
-	for symbol, priceData := range prices {
-		candle := models.Candle{
-			Open:      priceData.LastPrice,
-			High:      priceData.LastPrice,
-			Low:       priceData.LastPrice,
-			Close:     priceData.LastPrice,
-			Volume:    priceData.Volume,
-			Time:      time.Now().Unix(),
-			Timestamp: time.Now(),
-			Symbol:    symbol,
-		}
+// Events returns the bus the engine publishes its lifecycle events on
+func (e *Engine) Events() *events.Bus {
+	return e.events
+}
+
+// ExchangeStatus returns the state of the circuit breaker guarding Binance REST calls
+func (e *Engine) ExchangeStatus() string {
+	return e.binanceClient.CircuitState()
+}
 
-		e.buffersMutex.Lock()
-		buffer := e.dataBuffers[symbol]
-		if len(buffer) >= e.config.Trading.PriceBufferSize {
-			buffer = buffer[1:]
+// updateRealTimeData applies a kline update to the data buffers and queues the analysis of
+// its symbol. Each symbol is analyzed by its own worker, in the order of its updates.
+func (e *Engine) updateRealTimeData(ctx context.Context, update models.KlineUpdate) {
+	buffers := e.applyCandle(update.Candle, update.IsClosed)
+
+	symbol := update.Candle.Symbol
+	queue, exists := e.analysisQueues[symbol]
+	if !exists {
+		queue = make(chan map[string][]models.Candle, 1)
+		e.analysisQueues[symbol] = queue
+		go e.analyzeSymbol(ctx, symbol, queue)
+	}
+
+	// While the worker is busy the queued buffers are replaced by the newer ones, keeping the
+	// queued buffers of timeframes the update did not reach
+	select {
+	case queue <- buffers:
+	default:
+		select {
+		case queued := <-queue:
+			for interval, buffer := range queued {
+				if _, ok := buffers[interval]; !ok {
+					buffers[interval] = buffer
+				}
+			}
+		default:
 		}
-		buffer = append(buffer, candle)
-		e.dataBuffers[symbol] = buffer
-		e.buffersMutex.Unlock()
+		queue <- buffers
+	}
 
-		// Perform technical analysis
-		go e.updateTechnicalAnalysis(ctx, symbol, buffer)
+	if update.IsClosed {
+		e.logger.Debug("Closed %s candle for %s at %.8f", e.baseTimeframe(), symbol, update.Candle.Close)
 	}
+}
 
-	e.logger.Debug("Updated real-time data for %d symbols", len(prices))
+// analyzeSymbol runs the technical analysis of a symbol on each buffer snapshot queued for it,
+// higher timeframes first so the signal sees their trends
+func (e *Engine) analyzeSymbol(ctx context.Context, symbol string, queue <-chan map[string][]models.Candle) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case buffers := <-queue:
+			for i := len(e.timeframes) - 1; i >= 0; i-- {
+				if buffer, ok := buffers[e.timeframes[i].name]; ok {
+					e.updateTechnicalAnalysis(ctx, symbol, e.timeframes[i].name, buffer)
+				}
+			}
+		}
+	}
 }
 
-// updateTechnicalAnalysis updates technical analysis for a symbol
-func (e *Engine) updateTechnicalAnalysis(ctx context.Context, symbol string, candles []models.Candle) {
-	analysis, err := e.techAnalyzer.Analyze(ctx, symbol, candles)
+// updateTechnicalAnalysis updates technical analysis for a symbol at a timeframe. The
+// analysis of the signal timeframe is published on the watchlist with the trend of every
+// timeframe.
+func (e *Engine) updateTechnicalAnalysis(ctx context.Context, symbol, interval string, candles []models.Candle) {
+	analysis, err := e.techAnalyzer.Analyze(ctx, symbol, interval, candles)
 	if err != nil {
-		e.logger.Error("Technical analysis failed for %s: %v", symbol, err)
+		e.logger.Error("Technical analysis of %s %s failed: %v", symbol, interval, err)
+		return
+	}
+	if interval != e.config.Trading.KlineInterval {
 		return
 	}
 
+	trends := make(map[string]string, len(e.timeframes))
+	for _, tf := range e.timeframes {
+		if result, ok := e.techAnalyzer.GetCachedAnalysis(symbol, tf.name); ok {
+			trends[tf.name] = result.TrendDirection
+		}
+	}
+
 	// Update watchlist with technical analysis
+	var signal *events.SignalGenerated
 	e.stateMutex.Lock()
 	for i, item := range e.tradingState.Watchlist {
 		if item.Symbol == symbol {
+			previous := ""
+			if item.Technical != nil {
+				previous = item.Technical.Signal
+			}
+			if analysis.Signals.Overall != previous {
+				signal = &events.SignalGenerated{
+					Header:     events.Header{At: e.clock.Now()},
+					Symbol:     symbol,
+					Interval:   interval,
+					Signal:     analysis.Signals.Overall,
+					Previous:   previous,
+					Confidence: analysis.Confidence,
+					Price:      analysis.Price,
+				}
+			}
+
 			e.tradingState.Watchlist[i].Technical = &models.TechnicalAnalysis{
 				EMA9:       analysis.Indicators.EMA9,
 				EMA21:      analysis.Indicators.EMA21,
@@ -285,183 +602,304 @@ func (e *Engine) updateTechnicalAnalysis(ctx context.Context, symbol string, can
 				EMA200:     analysis.Indicators.EMA200,
 				RSI:        analysis.Indicators.RSI,
 				MACD:       analysis.Indicators.MACD,
+				MACDSignal: analysis.Indicators.MACDSignal,
+				MACDHist:   analysis.Indicators.MACDHist,
 				VWAP:       analysis.Indicators.VWAP,
 				MA50:       analysis.Indicators.EMA50, // Using EMA50 as MA50 approximation
 				Signal:     analysis.Signals.Overall,
 				Confidence: analysis.Confidence,
+				ATR:        analysis.Indicators.ATR,
+				BBUpper:    analysis.Indicators.BBUpper,
+				BBLower:    analysis.Indicators.BBLower,
+				StochRSI:   analysis.Indicators.StochRSIK,
+				ADX:        analysis.Indicators.ADX,
+				OBV:        analysis.Indicators.OBV,
+				SuperTrend: analysis.Indicators.SuperTrend,
+				SwingHigh:  analysis.SwingLevels.SwingHigh,
+				SwingLow:   analysis.SwingLevels.SwingLow,
+				StopLoss:   analysis.PriceTargets.StopLoss,
+				TakeProfit: analysis.PriceTargets.TakeProfit,
+				Trends:     trends,
 			}
 			e.tradingState.Watchlist[i].Price = analysis.Price
-			e.tradingState.Watchlist[i].LastUpdate = time.Now()
+			e.tradingState.Watchlist[i].LastUpdate = e.clock.Now()
 			break
 		}
 	}
 	e.stateMutex.Unlock()
+
+	if signal != nil {
+		e.events.Publish(*signal)
+	}
 }
 
 // startTradingLoop starts the main trading execution loop
 func (e *Engine) startTradingLoop(ctx context.Context) {
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := e.clock.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
 	e.logger.Info("Starting trading loop")
 
+	paused := false
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-e.stopChan:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			e.tradingMutex.RLock()
 			enabled := e.tradingEnabled
 			e.tradingMutex.RUnlock()
 
-			if enabled {
+			// Hold off on new decisions while the circuit breaker reports Binance as unhealthy.
+			// Only requests move the breaker on, so a paused loop probes with a ping, which the
+			// breaker refuses without contacting Binance until its cooldown has elapsed.
+			healthy := e.binanceClient.ExchangeHealthy()
+			if !healthy {
+				healthy = e.binanceClient.HealthCheck(ctx) == nil
+			}
+			if healthy == paused {
+				paused = !healthy
+				if paused {
+					e.logger.Warn("Binance is unhealthy, pausing trading loop")
+				} else {
+					e.logger.Info("Binance recovered, resuming trading loop")
+				}
+			}
+
+			if enabled && !paused {
 				e.processTrading(ctx)
 			}
 		}
 	}
 }
 
-// processTrading processes trading signals and executes trades
+// processTrading asks every strategy instance about the active symbols with an analysis it
+// trades and acts on the intents it returns
 func (e *Engine) processTrading(ctx context.Context) {
 	e.stateMutex.RLock()
 	watchlist := make([]models.WatchlistItem, len(e.tradingState.Watchlist))
 	copy(watchlist, e.tradingState.Watchlist)
-	settings := e.tradingState.Settings
-	currentPositions := len(e.tradingState.Positions)
-	availableBalance := e.tradingState.AvailableBalance
-	dayPnL := e.tradingState.DayPnL
 	e.stateMutex.RUnlock()
 
-	// Check daily loss limit
-	if math.Abs(dayPnL) >= settings.MaxDailyLoss {
-		e.logger.Warn("Daily loss limit reached: %.2f", dayPnL)
-		return
-	}
-
-	// Check if we can open new positions
-	if currentPositions >= settings.MaxPositions {
-		return
-	}
-
-	// Check available balance
-	if availableBalance < 1000 {
-		e.logger.Warn("Insufficient balance for trading: %.2f", availableBalance)
-		return
-	}
+	for _, runner := range e.runners() {
+		e.stateMutex.RLock()
+		settings := e.settingsFor(runner)
+		e.stateMutex.RUnlock()
 
-	for _, item := range watchlist {
-		if !item.IsActive || item.Technical == nil {
-			continue
-		}
-
-		// Check if we already have a position for this symbol
-		hasPosition := e.hasPosition(item.Symbol)
-		if hasPosition {
-			continue
-		}
+		for _, item := range watchlist {
+			if !item.IsActive || item.Technical == nil || !runner.trades(item.Symbol) {
+				continue
+			}
 
-		// Check signal confidence
-		if item.Technical.Confidence < settings.MinConfidence {
-			continue
-		}
+			candle, exists := e.LatestCandle(item.Symbol)
+			if !exists {
+				continue
+			}
 
-		// Check cooldown period
-		if e.isInCooldown(item.Symbol) {
-			continue
-		}
+			// Each instance only sees the positions it opened
+			market := strategy.Market{
+				Symbol:    item.Symbol,
+				Candle:    candle,
+				Technical: item.Technical,
+				Settings:  settings,
+			}
+			if position, open := e.positionFor(item.Symbol); open && position.StrategyID == runner.instance.ID {
+				market.Position = &position
+			}
 
-		// Execute trade based on signal
-		switch item.Technical.Signal {
-		case "STRONG_BUY", "BUY":
-			e.executeBuyTrade(ctx, item, settings)
-		case "STRONG_SELL", "SELL":
-			e.executeSellTrade(ctx, item, settings)
+			intents := runner.ask(func(s strategy.Strategy) []strategy.Intent {
+				return s.OnCandle(market)
+			})
+			e.applyIntents(ctx, runner, intents)
 		}
 	}
+
+	e.dispatchFills(ctx)
 }
 
-// executeBuyTrade executes a buy trade
-func (e *Engine) executeBuyTrade(ctx context.Context, item models.WatchlistItem, settings models.TradingSettings) {
+// openPosition sizes and sends an entry order for a strategy instance, then records the
+// resulting position, or adds the fill to the open position of the symbol when adding. Short
+// entries borrow the base asset on margin and lock the notional as collateral.
+func (e *Engine) openPosition(ctx context.Context, runner *strategyRunner, item models.WatchlistItem, settings models.TradingSettings, isLong bool, signal string, adding bool) {
 	if item.Technical == nil {
 		return
 	}
+	strategyID := runner.instance.ID
+
+	e.stateMutex.RLock()
+	availableBalance := e.strategyBalance(runner)
+	e.stateMutex.RUnlock()
+
+	// Calculate position size so that hitting the stop loses the risk amount
+	stopDistance, targetDistance := exitDistances(settings, item.Technical, item.Price, isLong)
+	if stopDistance <= 0 || targetDistance <= 0 {
+		return
+	}
+	riskAmount := availableBalance * (settings.RiskPerTrade / 100)
+	positionSize := utils.MinFloat64(riskAmount/(stopDistance/item.Price), settings.MaxPositionSize)
 
-	// Calculate position size
-	riskAmount := e.tradingState.AvailableBalance * (settings.RiskPerTrade / 100)
-	positionSize := utils.MinFloat64(riskAmount/(settings.StopLossPercent/100), settings.MaxPositionSize)
+	// Additions keep the combined position within the maximum position size
+	if existing, open := e.positionFor(item.Symbol); adding && open {
+		positionSize = utils.MinFloat64(positionSize, settings.MaxPositionSize-math.Abs(existing.Quantity)*item.Price)
+	}
 
 	if positionSize < 100 {
 		return // Position too small
 	}
 
 	quantity := positionSize / item.Price
-	totalCost := quantity * item.Price
 
-	// Validate position
-	if err := utils.ValidatePositionSize(totalCost, settings.MaxPositionSize, e.tradingState.AvailableBalance); err != nil {
+	// Validate position, shorts must be fully collateralized by the available balance
+	if err := utils.ValidatePositionSize(positionSize, settings.MaxPositionSize, availableBalance); err != nil {
 		e.logger.Error("Position validation failed for %s: %v", item.Symbol, err)
 		return
 	}
 
-	// Calculate stop loss and take profit
-	stopLoss := utils.CalculateStopLoss(item.Price, settings.StopLossPercent, true)
-	takeProfit := utils.CalculateTakeProfit(item.Price, settings.TakeProfitPercent, true)
+	if !e.beginOrder(item.Symbol) {
+		return
+	}
+	defer e.endOrder(item.Symbol)
+
+	request := &models.OrderRequest{
+		Symbol:   item.Symbol,
+		Side:     "BUY",
+		Type:     "MARKET",
+		Quantity: quantity,
+	}
+	if !isLong {
+		request.Side = "SELL"
+		request.SideEffectType = models.SideEffectMarginBuy
+	}
+	e.submitOrder(request, strategyID)
+
+	// Send market order to the execution venue
+	e.events.Publish(events.OrderSubmitted{
+		Header:     events.Header{At: e.clock.Now()},
+		StrategyID: strategyID,
+		Venue:      e.executor.Name(),
+		Request:    *request,
+	})
+	order, err := e.executor.PlaceOrder(ctx, request)
+	if errors.Is(err, execution.ErrFilterViolation) {
+		e.orderFailed(request, err, false)
+		e.logger.Warn("Skipped %s entry for %s: %v", strings.ToLower(request.Side), item.Symbol, err)
+		return
+	}
+	if err != nil {
+		e.orderFailed(request, err, adding)
+		e.logger.Error("Failed to place %s order for %s: %v", strings.ToLower(request.Side), item.Symbol, err)
+		return
+	}
+	if order.ExecutedQty == 0 {
+		// Fills arriving later on the user data stream still open the position
+		e.stateMutex.Lock()
+		e.trackOrder(request.ClientOrderID, order.ExecutedQty, strategyID)
+		if adding {
+			e.countEntry(item.Symbol)
+		}
+		e.stateMutex.Unlock()
+
+		e.logger.Warn("%s order for %s was not filled: status=%s", request.Side, item.Symbol, order.Status)
+		return
+	}
+
+	// Use the actual fill instead of the signal price
+	fillPrice := order.AvgPrice
+	quantity = order.ExecutedQty
+	totalCost := quantity * fillPrice
+	fee := execution.QuoteFee(order, e.config.Trading.QuoteAsset)
+
+	// Place stop loss and take profit at the same distances from the fill, above and below entry for shorts
+	stopLoss := fillPrice - stopDistance
+	takeProfit := fillPrice + targetDistance
+	if !isLong {
+		stopLoss = fillPrice + stopDistance
+		takeProfit = fillPrice - targetDistance
+	}
+
+	// Short positions carry a negative quantity
+	positionQty := quantity
+	if !isLong {
+		positionQty = -quantity
+	}
 
 	// Create trade
 	trade := models.Trade{
-		ID:         utils.GenerateTradeID(item.Symbol),
+		ID:         utils.GenerateTradeIDAt(item.Symbol, e.clock.Now()),
 		Symbol:     item.Symbol,
-		Type:       "BUY",
-		Price:      item.Price,
+		Type:       request.Side,
+		Price:      fillPrice,
 		Quantity:   quantity,
-		Timestamp:  time.Now(),
-		Signal:     item.Technical.Signal,
+		Timestamp:  order.Timestamp,
+		Signal:     signal,
 		Confidence: item.Technical.Confidence,
+		StrategyID: strategyID,
+		Fee:        fee,
 	}
 
 	// Create position
 	position := models.Position{
-		ID:            utils.GenerateTradeID(item.Symbol),
+		ID:            utils.GenerateTradeIDAt(item.Symbol, e.clock.Now()),
 		Symbol:        item.Symbol,
-		Quantity:      quantity,
-		AvgBuyPrice:   item.Price,
+		Quantity:      positionQty,
+		AvgBuyPrice:   fillPrice,
 		CurrentValue:  totalCost,
 		UnrealizedPnL: 0,
-		EntryTime:     time.Now(),
+		EntryTime:     order.Timestamp,
 		TargetPrice:   &takeProfit,
 		StopLossPrice: &stopLoss,
+		StrategyID:    strategyID,
+
+		InitialQuantity: quantity,
+		BestPrice:       fillPrice,
+		Entries:         1,
 	}
 
-	// Update trading state
+	// Update trading state, the notional is committed as cost for longs and collateral for shorts
 	e.stateMutex.Lock()
-	e.tradingState.Trades = append(e.tradingState.Trades, trade)
-	e.tradingState.Positions = append(e.tradingState.Positions, position)
-	e.tradingState.AvailableBalance -= totalCost
+	e.recordTrade(trade)
+	opened := true
+	for i := range e.tradingState.Positions {
+		if adding && e.tradingState.Positions[i].Symbol == item.Symbol {
+			scaleInPosition(&e.tradingState.Positions[i], quantity, fillPrice, stopDistance, targetDistance)
+			e.tradingState.Positions[i].Entries++
+			position, opened = e.tradingState.Positions[i], false
+			break
+		}
+	}
+	if opened {
+		e.tradingState.Positions = append(e.tradingState.Positions, position)
+	}
+	e.adjustAvailableBalance(-(totalCost + fee))
+	e.attribute(strategyID, -(totalCost + fee), -fee)
+	e.tradingState.TotalPnL -= fee
+	e.tradingState.DayPnL -= fee
+	e.trackOrder(request.ClientOrderID, order.ExecutedQty, strategyID)
+	e.lastTradeTime[item.Symbol] = e.clock.Now()
 	e.stateMutex.Unlock()
 
-	// Set position timer
-	e.setPositionTimer(item.Symbol, settings.MaxHoldTime)
-
-	// Update last trade time
-	e.lastTradeTime[item.Symbol] = time.Now()
+	// Additions keep the hold-time timer of the position
+	if opened {
+		e.events.Publish(events.PositionOpened{Header: events.Header{At: e.clock.Now()}, Position: position})
+		e.setPositionTimer(item.Symbol, time.Duration(settings.MaxHoldTime)*time.Minute)
+	}
 
 	e.logger.WithFields(map[string]interface{}{
 		"symbol":      item.Symbol,
-		"type":        "BUY",
-		"price":       item.Price,
-		"quantity":    quantity,
+		"strategy":    strategyID,
+		"type":        request.Side,
+		"order_id":    order.OrderID,
+		"venue":       e.executor.Name(),
+		"price":       fillPrice,
+		"fee":         fee,
+		"quantity":    positionQty,
 		"confidence":  item.Technical.Confidence,
-		"stop_loss":   stopLoss,
-		"take_profit": takeProfit,
-	}).Info("Executed buy trade")
-}
-
-// executeSellTrade executes a sell trade (short position)
-func (e *Engine) executeSellTrade(ctx context.Context, item models.WatchlistItem, settings models.TradingSettings) {
-	// Similar implementation to executeBuyTrade but for short positions
-	// ... (implementation similar to buy but with negative quantity for short)
+		"stop_loss":   *position.StopLossPrice,
+		"take_profit": *position.TargetPrice,
+		"entries":     position.Entries,
+	}).Info("Executed " + strings.ToLower(request.Side) + " trade")
 }
 
 // hasPosition checks if there's an active position for a symbol
@@ -469,6 +907,10 @@ func (e *Engine) hasPosition(symbol string) bool {
 	e.stateMutex.RLock()
 	defer e.stateMutex.RUnlock()
 
+	if e.pendingOrders[symbol] {
+		return true
+	}
+
 	for _, position := range e.tradingState.Positions {
 		if position.Symbol == symbol {
 			return true
@@ -477,19 +919,46 @@ func (e *Engine) hasPosition(symbol string) bool {
 	return false
 }
 
+// beginOrder marks a symbol as having an order in flight, returning false if one already is
+func (e *Engine) beginOrder(symbol string) bool {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	if e.pendingOrders[symbol] {
+		return false
+	}
+	e.pendingOrders[symbol] = true
+	return true
+}
+
+// endOrder clears the in-flight order marker for a symbol and applies the order
+// updates that arrived while the order's REST response was being recorded
+func (e *Engine) endOrder(symbol string) {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	delete(e.pendingOrders, symbol)
+	for _, report := range e.deferredReports[symbol] {
+		e.applyExecution(report)
+	}
+	delete(e.deferredReports, symbol)
+}
+
 // isInCooldown checks if a symbol is in cooldown period
 func (e *Engine) isInCooldown(symbol string) bool {
+	e.stateMutex.RLock()
 	lastTrade, exists := e.lastTradeTime[symbol]
+	e.stateMutex.RUnlock()
 	if !exists {
 		return false
 	}
 
 	cooldownPeriod := 30 * time.Second
-	return time.Since(lastTrade) < cooldownPeriod
+	return e.clock.Since(lastTrade) < cooldownPeriod
 }
 
-// setPositionTimer sets a timer to automatically close a position
-func (e *Engine) setPositionTimer(symbol string, maxHoldMinutes int) {
+// setPositionTimer sets a timer to automatically close a position after it has been held for holdTime
+func (e *Engine) setPositionTimer(symbol string, holdTime time.Duration) {
 	e.timersMutex.Lock()
 	defer e.timersMutex.Unlock()
 
@@ -499,7 +968,7 @@ func (e *Engine) setPositionTimer(symbol string, maxHoldMinutes int) {
 	}
 
 	// Set new timer
-	timer := time.AfterFunc(time.Duration(maxHoldMinutes)*time.Minute, func() {
+	timer := e.clock.AfterFunc(holdTime, func() {
 		e.closePositionByTimeout(symbol)
 	})
 
@@ -518,7 +987,7 @@ func (e *Engine) closePositionByTimeout(symbol string) {
 
 // startPositionMonitoring starts monitoring positions for exit conditions
 func (e *Engine) startPositionMonitoring(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := e.clock.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	e.logger.Info("Starting position monitoring")
@@ -529,39 +998,67 @@ func (e *Engine) startPositionMonitoring(ctx context.Context) {
 			return
 		case <-e.stopChan:
 			return
-		case <-ticker.C:
-			e.checkExitConditions()
+		case <-ticker.C():
+			e.checkExitConditions(ctx)
 		}
 	}
 }
 
 // checkExitConditions checks if any positions should be closed
-func (e *Engine) checkExitConditions() {
+func (e *Engine) checkExitConditions(ctx context.Context) {
 	e.stateMutex.RLock()
 	positions := make([]models.Position, len(e.tradingState.Positions))
 	copy(positions, e.tradingState.Positions)
 	e.stateMutex.RUnlock()
 
 	for _, position := range positions {
-		e.buffersMutex.RLock()
-		buffer, exists := e.dataBuffers[position.Symbol]
-		e.buffersMutex.RUnlock()
-
-		if !exists || len(buffer) == 0 {
+		candle, exists := e.LatestCandle(position.Symbol)
+		if !exists {
 			continue
 		}
 
-		currentPrice := buffer[len(buffer)-1].Close
+		currentPrice := candle.Close
+
+		// Positions are managed with the settings of the strategy instance that opened them
+		e.stateMutex.RLock()
+		runner := e.runnerFor(position.StrategyID)
+		settings := e.settingsFor(runner)
+		e.stateMutex.RUnlock()
+
+		// Let the strategy act on the price before the built-in exits
+		if runner != nil {
+			tick := strategy.Tick{
+				Symbol:   position.Symbol,
+				Price:    currentPrice,
+				Time:     e.clock.Now(),
+				Position: position,
+			}
+			e.applyIntents(ctx, runner, runner.ask(func(s strategy.Strategy) []strategy.Intent {
+				return s.OnTick(tick)
+			}))
+		}
+
+		// Move the stop to break-even or trail it behind the price before checking it
+		updated, exists := e.updateStops(position, currentPrice, settings)
+		if !exists {
+			continue
+		}
+		position = updated
 
 		// Check stop loss
 		if position.StopLossPrice != nil {
 			if (position.Quantity > 0 && currentPrice <= *position.StopLossPrice) ||
 				(position.Quantity < 0 && currentPrice >= *position.StopLossPrice) {
-				e.ClosePosition(position.Symbol, "STOP_LOSS")
+				e.ClosePosition(position.Symbol, stopReason(position.StopType))
 				continue
 			}
 		}
 
+		// Scale out at the take-profit ladder
+		if !e.scaleOut(position, currentPrice, settings) {
+			continue
+		}
+
 		// Check take profit
 		if position.TargetPrice != nil {
 			if (position.Quantity > 0 && currentPrice >= *position.TargetPrice) ||
@@ -574,6 +1071,8 @@ func (e *Engine) checkExitConditions() {
 		// Update unrealized P&L
 		e.updatePositionPnL(position.Symbol, currentPrice)
 	}
+
+	e.dispatchFills(ctx)
 }
 
 // updatePositionPnL updates the unrealized P&L for a position
@@ -647,6 +1146,11 @@ func (e *Engine) DisableTrading() {
 	e.logger.Info("Automated trading disabled")
 }
 
+// Venue returns the name of the execution venue orders are routed to
+func (e *Engine) Venue() string {
+	return e.executor.Name()
+}
+
 // IsTrading returns whether automated trading is enabled
 func (e *Engine) IsTrading() bool {
 	e.tradingMutex.RLock()
@@ -657,83 +1161,165 @@ func (e *Engine) IsTrading() bool {
 
 // ClosePosition closes a position with the given reason
 func (e *Engine) ClosePosition(symbol, reason string) error {
-	e.stateMutex.Lock()
-	defer e.stateMutex.Unlock()
+	return e.closeQuantity(symbol, 0, reason)
+}
 
-	// Find position
-	positionIndex := -1
-	for i, position := range e.tradingState.Positions {
-		if position.Symbol == symbol {
-			positionIndex = i
+// closeQuantity closes part of a position, or all of it when quantity is 0 or covers the
+// position. Each exit is recorded as its own trade.
+func (e *Engine) closeQuantity(symbol string, quantity float64, reason string) error {
+	e.stateMutex.RLock()
+	var position *models.Position
+	for i := range e.tradingState.Positions {
+		if e.tradingState.Positions[i].Symbol == symbol {
+			p := e.tradingState.Positions[i]
+			position = &p
 			break
 		}
 	}
+	e.stateMutex.RUnlock()
 
-	if positionIndex == -1 {
+	if position == nil {
 		return fmt.Errorf("position not found for symbol: %s", symbol)
 	}
 
-	position := e.tradingState.Positions[positionIndex]
+	if !e.beginOrder(symbol) {
+		return fmt.Errorf("order already in progress for symbol: %s", symbol)
+	}
+	defer e.endOrder(symbol)
 
-	// Get current price
-	e.buffersMutex.RLock()
-	buffer, exists := e.dataBuffers[symbol]
-	e.buffersMutex.RUnlock()
+	held := math.Abs(position.Quantity)
+	if quantity <= 0 || quantity > held {
+		quantity = held
+	}
 
-	if !exists || len(buffer) == 0 {
-		return fmt.Errorf("no price data available for symbol: %s", symbol)
+	// Send the offsetting market order, buying back a short repays the borrowed asset
+	request := &models.OrderRequest{
+		Symbol:   symbol,
+		Side:     "SELL",
+		Type:     "MARKET",
+		Quantity: quantity,
+	}
+	if position.Quantity < 0 {
+		request.Side = "BUY"
+		request.SideEffectType = models.SideEffectAutoRepay
 	}
+	e.submitOrder(request, position.StrategyID)
 
-	currentPrice := buffer[len(buffer)-1].Close
+	ctx, cancel := utils.TimeoutContext(30 * time.Second)
+	defer cancel()
+
+	e.events.Publish(events.OrderSubmitted{
+		Header:     events.Header{At: e.clock.Now()},
+		StrategyID: position.StrategyID,
+		Venue:      e.executor.Name(),
+		Request:    *request,
+	})
+	order, err := e.executor.PlaceOrder(ctx, request)
+	if err != nil {
+		e.orderFailed(request, err, false)
+		return fmt.Errorf("failed to place exit order for %s: %w", symbol, err)
+	}
+	if order.ExecutedQty == 0 {
+		e.stateMutex.Lock()
+		e.trackOrder(request.ClientOrderID, order.ExecutedQty, position.StrategyID)
+		e.stateMutex.Unlock()
+
+		return fmt.Errorf("exit order for %s was not filled: status=%s", symbol, order.Status)
+	}
+
+	exitPrice := order.AvgPrice
+	closedQty := math.Min(order.ExecutedQty, held)
+	fee := execution.QuoteFee(order, e.config.Trading.QuoteAsset)
 
 	// Calculate P&L
-	pnl := utils.CalculatePnL(position.AvgBuyPrice, currentPrice, position.Quantity, position.Quantity > 0)
-	holdTime := int(time.Since(position.EntryTime).Minutes())
+	pnl := utils.CalculatePnL(position.AvgBuyPrice, exitPrice, closedQty, position.Quantity > 0)
+	holdTime := int(e.clock.Since(position.EntryTime).Minutes())
 
 	// Create exit trade
 	exitTrade := models.Trade{
-		ID:         utils.GenerateTradeID(symbol + "_exit"),
+		ID:         utils.GenerateTradeIDAt(symbol+"_exit", e.clock.Now()),
 		Symbol:     symbol,
 		Type:       "CLOSE",
-		Price:      currentPrice,
-		Quantity:   math.Abs(position.Quantity),
-		Timestamp:  time.Now(),
+		Price:      exitPrice,
+		Quantity:   closedQty,
+		Timestamp:  order.Timestamp,
 		Signal:     reason,
 		Confidence: 100,
 		PnL:        &pnl,
-		ExitPrice:  &currentPrice,
+		ExitPrice:  &exitPrice,
 		HoldTime:   &holdTime,
+		StrategyID: position.StrategyID,
+		Fee:        fee,
 	}
 
-	// Update trading state
-	e.tradingState.Trades = append(e.tradingState.Trades, exitTrade)
-	e.tradingState.TotalPnL += pnl
-	e.tradingState.DayPnL += pnl
+	e.stateMutex.Lock()
+	positionIndex := -1
+	for i, p := range e.tradingState.Positions {
+		if p.ID == position.ID {
+			positionIndex = i
+			break
+		}
+	}
 
-	// Return capital to available balance
-	originalInvestment := math.Abs(position.Quantity) * position.AvgBuyPrice
-	e.tradingState.AvailableBalance += originalInvestment + pnl
+	// Update trading state
+	e.recordTrade(exitTrade)
+	e.rollDay()
+	e.tradingState.TotalPnL += pnl - fee
+	e.tradingState.DayPnL += pnl - fee
+
+	// Reduce the position, or remove it once nothing meaningful is left
+	remaining := held - closedQty
+	partial := remaining > held*1e-9
+	if positionIndex != -1 {
+		if partial {
+			e.tradingState.Positions[positionIndex].Quantity = math.Copysign(remaining, position.Quantity)
+			e.tradingState.Positions[positionIndex].CurrentValue = remaining * exitPrice
+		} else {
+			e.tradingState.Positions = append(
+				e.tradingState.Positions[:positionIndex],
+				e.tradingState.Positions[positionIndex+1:]...)
+		}
+	}
 
-	// Remove position
-	e.tradingState.Positions = append(
-		e.tradingState.Positions[:positionIndex],
-		e.tradingState.Positions[positionIndex+1:]...)
+	// Return capital, or the released short collateral, to available balance
+	originalInvestment := closedQty * position.AvgBuyPrice
+	e.adjustAvailableBalance(originalInvestment + pnl - fee)
+	e.attribute(position.StrategyID, originalInvestment+pnl-fee, pnl-fee)
+	e.trackOrder(request.ClientOrderID, order.ExecutedQty, position.StrategyID)
+	e.stateMutex.Unlock()
 
 	// Cancel timer
-	e.timersMutex.Lock()
-	if timer, exists := e.positionTimers[symbol]; exists {
-		timer.Stop()
-		delete(e.positionTimers, symbol)
+	if !partial {
+		e.events.Publish(events.PositionClosed{
+			Header:   events.Header{At: e.clock.Now()},
+			Position: *position,
+			Reason:   reason,
+		})
+
+		e.timersMutex.Lock()
+		if timer, exists := e.positionTimers[symbol]; exists {
+			timer.Stop()
+			delete(e.positionTimers, symbol)
+		}
+		e.timersMutex.Unlock()
 	}
-	e.timersMutex.Unlock()
 
+	message := "Position closed"
+	if partial {
+		message = "Position partially closed"
+	}
 	e.logger.WithFields(map[string]interface{}{
 		"symbol":     symbol,
 		"reason":     reason,
+		"order_id":   order.OrderID,
+		"venue":      e.executor.Name(),
+		"quantity":   closedQty,
+		"remaining":  remaining,
 		"pnl":        pnl,
+		"fee":        fee,
 		"hold_time":  holdTime,
-		"exit_price": currentPrice,
-	}).Info("Position closed")
+		"exit_price": exitPrice,
+	}).Info(message)
 
 	return nil
 }
@@ -744,17 +1330,32 @@ func (e *Engine) UpdateSettings(settings models.TradingSettings) error {
 	if err := utils.ValidateRiskParameters(settings.RiskPerTrade, settings.StopLossPercent, settings.TakeProfitPercent); err != nil {
 		return err
 	}
+	if err := validateStopMode(settings); err != nil {
+		return err
+	}
+	if err := validateExitManagement(settings); err != nil {
+		return err
+	}
+	if err := validateScaling(settings); err != nil {
+		return err
+	}
 
 	e.stateMutex.Lock()
 	e.tradingState.Settings = settings
 	e.stateMutex.Unlock()
 
+	e.events.Publish(events.SettingsChanged{Header: events.Header{At: e.clock.Now()}, Settings: settings})
+
 	e.logger.WithFields(map[string]interface{}{
 		"min_confidence":      settings.MinConfidence,
 		"max_position_size":   settings.MaxPositionSize,
 		"risk_per_trade":      settings.RiskPerTrade,
 		"stop_loss_percent":   settings.StopLossPercent,
 		"take_profit_percent": settings.TakeProfitPercent,
+		"stop_mode":           settings.StopMode,
+		"trailing_mode":       settings.TrailingMode,
+		"break_even_percent":  settings.BreakEvenPercent,
+		"take_profit_steps":   len(settings.TakeProfitLadder),
 	}).Info("Trading settings updated")
 
 	return nil
@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"trading-engine/execution"
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// profitPercent returns the profit of a position at price relative to its entry price
+func profitPercent(position models.Position, price float64) float64 {
+	if position.AvgBuyPrice == 0 {
+		return 0
+	}
+	change := (price - position.AvgBuyPrice) / position.AvgBuyPrice * 100
+	if position.Quantity < 0 {
+		return -change
+	}
+	return change
+}
+
+// stopReason returns the close reason for a stop hit, by how the stop was last moved
+func stopReason(stopType string) string {
+	switch stopType {
+	case models.StopTypeBreakEven:
+		return "BREAK_EVEN"
+	case models.StopTypeTrailing:
+		return "TRAILING_STOP"
+	}
+	return "STOP_LOSS"
+}
+
+// symbolATR returns the ATR of the signal timeframe published on the watchlist for a symbol
+func (e *Engine) symbolATR(symbol string) float64 {
+	e.stateMutex.RLock()
+	defer e.stateMutex.RUnlock()
+
+	for _, item := range e.tradingState.Watchlist {
+		if item.Symbol == symbol && item.Technical != nil {
+			return item.Technical.ATR
+		}
+	}
+	return 0
+}
+
+// updateStops records the best price a position reached and moves its stop loss to
+// break-even or behind the price as the settings ask. The stop only ever tightens. It returns
+// the updated position, or false when the position has been closed meanwhile.
+func (e *Engine) updateStops(open models.Position, price float64, settings models.TradingSettings) (models.Position, bool) {
+	atr := 0.0
+	if settings.TrailingMode == models.TrailingModeATR {
+		atr = e.symbolATR(open.Symbol)
+	}
+
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	for i := range e.tradingState.Positions {
+		position := &e.tradingState.Positions[i]
+		if position.ID != open.ID {
+			continue
+		}
+
+		isLong := position.Quantity > 0
+		if position.BestPrice == 0 || (isLong && price > position.BestPrice) || (!isLong && price < position.BestPrice) {
+			position.BestPrice = price
+		}
+
+		// A candidate is tighter when it sits above the stop of a long or below that of a short
+		tighter := func(candidate float64) bool {
+			if position.StopLossPrice == nil {
+				return true
+			}
+			if isLong {
+				return candidate > *position.StopLossPrice
+			}
+			return candidate < *position.StopLossPrice
+		}
+		moveStop := func(candidate float64, stopType string) {
+			if candidate > 0 && tighter(candidate) {
+				stop := candidate
+				position.StopLossPrice = &stop
+				position.StopType = stopType
+			}
+		}
+
+		if settings.BreakEvenPercent > 0 && profitPercent(*position, price) >= settings.BreakEvenPercent {
+			moveStop(position.AvgBuyPrice, models.StopTypeBreakEven)
+		}
+
+		var distance float64
+		switch settings.TrailingMode {
+		case models.TrailingModePercent:
+			distance = position.BestPrice * settings.TrailingPercent / 100
+		case models.TrailingModeATR:
+			distance = atr * settings.TrailingATRMultiple
+		}
+		if distance > 0 {
+			if isLong {
+				moveStop(position.BestPrice-distance, models.StopTypeTrailing)
+			} else {
+				moveStop(position.BestPrice+distance, models.StopTypeTrailing)
+			}
+		}
+
+		return *position, true
+	}
+	return models.Position{}, false
+}
+
+// scaleOut closes the share of a position set by each take-profit ladder step its profit has
+// reached. A step the exchange filters reject is skipped. The last step of a ladder closing
+// the whole position, or a step leaving less than the exchange accepts in one order, closes
+// what is left. It returns false once the position is fully closed.
+func (e *Engine) scaleOut(position models.Position, price float64, settings models.TradingSettings) bool {
+	profit := profitPercent(position, price)
+
+	var closed float64
+	for step, level := range settings.TakeProfitLadder {
+		if step >= position.LadderStep {
+			break
+		}
+		closed += level.ClosePercent
+	}
+
+	for step := position.LadderStep; step < len(settings.TakeProfitLadder); step++ {
+		level := settings.TakeProfitLadder[step]
+		if profit < level.ProfitPercent {
+			break
+		}
+		closed += level.ClosePercent
+
+		quantity := position.InitialQuantity * level.ClosePercent / 100
+		if closed >= 100-1e-9 || e.leavesDust(position.Symbol, quantity) {
+			quantity = 0 // The whole position
+		}
+		reason := fmt.Sprintf("TAKE_PROFIT_%d", step+1)
+		if err := e.closeQuantity(position.Symbol, quantity, reason); err != nil {
+			if !errors.Is(err, execution.ErrFilterViolation) {
+				e.logger.WithFields(map[string]interface{}{
+					"symbol": position.Symbol,
+					"step":   step + 1,
+					"error":  err.Error(),
+				}).Error("Failed to scale out of position")
+				return true
+			}
+			e.logger.WithFields(map[string]interface{}{
+				"symbol":   position.Symbol,
+				"step":     step + 1,
+				"quantity": quantity,
+				"error":    err.Error(),
+			}).Warn("Skipped take-profit step rejected by exchange filters")
+		}
+
+		if !e.advanceLadder(position.ID, step+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// leavesDust reports whether closing quantity of the position on a symbol would leave a
+// remainder the exchange filters reject, which could then never be closed
+func (e *Engine) leavesDust(symbol string, quantity float64) bool {
+	normalizer, ok := e.executor.(execution.OrderNormalizer)
+	if !ok {
+		return false
+	}
+	position, open := e.positionFor(symbol)
+	if !open {
+		return false
+	}
+
+	ctx, cancel := utils.TimeoutContext(10 * time.Second)
+	defer cancel()
+
+	side := "SELL"
+	if position.Quantity < 0 {
+		side = "BUY"
+	}
+	step, err := normalizer.Normalize(ctx, &models.OrderRequest{Symbol: symbol, Side: side, Type: "MARKET", Quantity: quantity})
+	if err != nil {
+		return false
+	}
+
+	held := math.Abs(position.Quantity)
+	remainder := held - step.Quantity
+	if remainder <= held*1e-9 {
+		return false
+	}
+	_, err = normalizer.Normalize(ctx, &models.OrderRequest{Symbol: symbol, Side: side, Type: "MARKET", Quantity: remainder})
+	return errors.Is(err, execution.ErrFilterViolation)
+}
+
+// advanceLadder records the ladder steps taken by a position, returning false when the
+// position is no longer open
+func (e *Engine) advanceLadder(positionID string, step int) bool {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	for i := range e.tradingState.Positions {
+		if e.tradingState.Positions[i].ID == positionID {
+			e.tradingState.Positions[i].LadderStep = step
+			return true
+		}
+	}
+	return false
+}
+
+// validateExitManagement checks the trailing stop, break-even and take-profit ladder settings
+func validateExitManagement(settings models.TradingSettings) error {
+	switch settings.TrailingMode {
+	case "", models.TrailingModeOff:
+	case models.TrailingModePercent:
+		if settings.TrailingPercent <= 0 {
+			return fmt.Errorf("trailing percent must be positive")
+		}
+	case models.TrailingModeATR:
+		if settings.TrailingATRMultiple <= 0 {
+			return fmt.Errorf("trailing ATR multiple must be positive")
+		}
+	default:
+		return fmt.Errorf("trailing mode must be one of %s, %s or %s", models.TrailingModeOff, models.TrailingModePercent, models.TrailingModeATR)
+	}
+
+	if settings.BreakEvenPercent < 0 {
+		return fmt.Errorf("break-even percent cannot be negative")
+	}
+
+	var total, previous float64
+	for i, step := range settings.TakeProfitLadder {
+		if step.ProfitPercent <= previous {
+			return fmt.Errorf("take-profit ladder step %d must be above the previous profit level", i+1)
+		}
+		if step.ClosePercent <= 0 || step.ClosePercent > 100 {
+			return fmt.Errorf("take-profit ladder step %d must close between 0 and 100 percent", i+1)
+		}
+		previous = step.ProfitPercent
+		total += step.ClosePercent
+	}
+	if total > 100+1e-9 {
+		return fmt.Errorf("take-profit ladder closes %.2f%% of the position, more than 100%%", total)
+	}
+
+	return nil
+}
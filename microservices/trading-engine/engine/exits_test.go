@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"trading-engine/config"
+	"trading-engine/execution"
+	"trading-engine/models"
+)
+
+// staticFilters serves the same trading rules for every symbol
+type staticFilters models.SymbolFilters
+
+func (f staticFilters) SymbolFilters(ctx context.Context, symbol string) (models.SymbolFilters, error) {
+	return models.SymbolFilters(f), nil
+}
+
+func TestScaleOutClosesRemainder(t *testing.T) {
+	tests := []struct {
+		name          string
+		filters       *models.SymbolFilters // Nil places orders without exchange filters
+		ladder        []models.TakeProfitStep
+		quantity      float64
+		ladderStep    int
+		wantOpen      bool
+		wantRemaining float64
+	}{
+		{
+			name:       "last step of a full ladder",
+			ladder:     []models.TakeProfitStep{{ProfitPercent: 5, ClosePercent: 50}, {ProfitPercent: 8, ClosePercent: 50}},
+			quantity:   0.5005, // Left over by the rounding of the first step
+			ladderStep: 1,
+		},
+		{
+			name: "remainder below the minimum notional",
+			filters: &models.SymbolFilters{
+				Status: "TRADING", StepSize: 0.001, MinQty: 0.001, MinNotional: 50, MinNotionalMarket: true,
+			},
+			ladder:   []models.TakeProfitStep{{ProfitPercent: 5, ClosePercent: 60}, {ProfitPercent: 20, ClosePercent: 30}},
+			quantity: 1,
+		},
+		{
+			name: "remainder below the minimum quantity",
+			filters: &models.SymbolFilters{
+				Status: "TRADING", StepSize: 0.001, MinQty: 0.5,
+			},
+			ladder:   []models.TakeProfitStep{{ProfitPercent: 5, ClosePercent: 60}, {ProfitPercent: 20, ClosePercent: 30}},
+			quantity: 1,
+		},
+		{
+			name: "tradable remainder stays open",
+			filters: &models.SymbolFilters{
+				Status: "TRADING", StepSize: 0.001, MinQty: 0.001, MinNotional: 10, MinNotionalMarket: true,
+			},
+			ladder:        []models.TakeProfitStep{{ProfitPercent: 5, ClosePercent: 60}, {ProfitPercent: 20, ClosePercent: 30}},
+			quantity:      1,
+			wantOpen:      true,
+			wantRemaining: 0.4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t, false)
+
+			paper := execution.NewPaperExecutor(&config.PaperConfig{StartingBalance: 100000}, "USDT", e, e.clock, e.logger)
+			paper.RestoreBalances([]models.Balance{{Asset: "USDT", Free: 100000}, {Asset: "BTC", Free: tt.quantity}})
+			e.executor = paper
+			if tt.filters != nil {
+				e.executor = execution.NewFilteredExecutor(paper, staticFilters(*tt.filters), e, e.logger)
+			}
+
+			// The price is 10% above the entry
+			at := e.clock.Now()
+			e.applyCandle(models.Candle{
+				Symbol: "BTCUSDT", Timestamp: at, Time: at.Unix(), Open: 110, High: 110, Low: 110, Close: 110, Volume: 1e6,
+			}, true)
+
+			position := models.Position{
+				ID: "BTCUSDT_1", Symbol: "BTCUSDT", Quantity: tt.quantity, AvgBuyPrice: 100, EntryTime: at,
+				StrategyID: defaultStrategyID, InitialQuantity: 1, LadderStep: tt.ladderStep, Entries: 1,
+			}
+			e.stateMutex.Lock()
+			e.tradingState.Positions = append(e.tradingState.Positions, position)
+			e.stateMutex.Unlock()
+
+			settings := e.GetTradingState().Settings
+			settings.TakeProfitLadder = tt.ladder
+			stillOpen := e.scaleOut(position, 110, settings)
+
+			remaining, open := e.positionFor("BTCUSDT")
+			if open != tt.wantOpen || stillOpen != tt.wantOpen {
+				t.Fatalf("position open = %v, scaleOut returned %v, want %v (position %+v)", open, stillOpen, tt.wantOpen, remaining)
+			}
+			if open && math.Abs(remaining.Quantity-tt.wantRemaining) > 1e-9 {
+				t.Errorf("remaining quantity = %v, want %v", remaining.Quantity, tt.wantRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"trading-engine/models"
+)
+
+// maxEntries returns the number of entries a position may be built from, 1 without pyramiding
+func maxEntries(settings models.TradingSettings) int {
+	if settings.ScalingFactor < 1 {
+		return 1
+	}
+	return settings.ScalingFactor
+}
+
+// canScaleIn reports whether a renewed entry of a strategy instance may add to the open
+// position of a symbol: the instance opened it in the same direction, it has entries left and
+// is at least ScaleInProfitPercent in profit
+func (e *Engine) canScaleIn(runner *strategyRunner, symbol string, isLong bool, price float64, settings models.TradingSettings) bool {
+	position, open := e.positionFor(symbol)
+	if !open || position.StrategyID != runner.instance.ID || (position.Quantity > 0) != isLong {
+		return false
+	}
+	if position.Entries >= maxEntries(settings) {
+		return false
+	}
+
+	profit := profitPercent(position, price)
+	return profit > 0 && profit >= settings.ScaleInProfitPercent
+}
+
+// scaleInPosition adds a fill to a position. The entry price becomes the weighted average of
+// the fills, the take profit moves to targetDistance from it and the stop loss to stopDistance,
+// unless the stop is already tighter.
+func scaleInPosition(position *models.Position, quantity, price, stopDistance, targetDistance float64) {
+	isLong := position.Quantity > 0
+	held := math.Abs(position.Quantity)
+
+	position.AvgBuyPrice = (held*position.AvgBuyPrice + quantity*price) / (held + quantity)
+	position.Quantity = math.Copysign(held+quantity, position.Quantity)
+	position.InitialQuantity += quantity
+	position.CurrentValue = (held + quantity) * price
+	position.UnrealizedPnL = 0
+
+	stopLoss := position.AvgBuyPrice - stopDistance
+	takeProfit := position.AvgBuyPrice + targetDistance
+	if !isLong {
+		stopLoss = position.AvgBuyPrice + stopDistance
+		takeProfit = position.AvgBuyPrice - targetDistance
+	}
+	position.TargetPrice = &takeProfit
+
+	if position.StopLossPrice == nil ||
+		(isLong && stopLoss > *position.StopLossPrice) || (!isLong && stopLoss < *position.StopLossPrice) {
+		position.StopLossPrice = &stopLoss
+		position.StopType = ""
+	}
+}
+
+// countEntry counts an addition to the open position of a symbol whose fills are reported
+// later on the user data stream, the caller must hold stateMutex
+func (e *Engine) countEntry(symbol string) {
+	for i := range e.tradingState.Positions {
+		if e.tradingState.Positions[i].Symbol == symbol {
+			e.tradingState.Positions[i].Entries++
+			return
+		}
+	}
+}
+
+// validateScaling checks the pyramiding settings
+func validateScaling(settings models.TradingSettings) error {
+	if settings.ScalingFactor < 0 {
+		return fmt.Errorf("scaling factor cannot be negative")
+	}
+	if settings.ScaleInProfitPercent < 0 {
+		return fmt.Errorf("scale-in profit percent cannot be negative")
+	}
+	return nil
+}
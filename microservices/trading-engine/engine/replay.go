@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"trading-engine/models"
+)
+
+// WatchSymbols replaces the watchlist with the given symbols
+func (e *Engine) WatchSymbols(symbols []string) {
+	watchlist := make([]models.WatchlistItem, 0, len(symbols))
+	for _, symbol := range symbols {
+		watchlist = append(watchlist, models.WatchlistItem{
+			Symbol:     symbol,
+			Name:       symbol,
+			IsActive:   true,
+			LastUpdate: e.clock.Now(),
+		})
+	}
+
+	e.stateMutex.Lock()
+	e.tradingState.Watchlist = watchlist
+	e.stateMutex.Unlock()
+}
+
+// StartReplay prepares the engine to be driven from recorded candles. Instead of
+// the live loops, the caller feeds candles with ApplyCandle, advances the clock
+// to the candle close so hold-time timers fire, then calls RunCycle.
+func (e *Engine) StartReplay(ctx context.Context) error {
+	if err := e.syncBalances(ctx); err != nil {
+		return fmt.Errorf("failed to load balances from %s venue: %w", e.executor.Name(), err)
+	}
+	e.scheduleDayRollover()
+	return nil
+}
+
+// ApplyCandle adds a closed base timeframe candle to the symbol's data buffers
+func (e *Engine) ApplyCandle(candle models.Candle) {
+	e.applyCandle(candle, true)
+}
+
+// TriggerExits closes the position on a candle's symbol when its stop loss or take profit lies
+// within the range of the candle, before the candle is applied. Only the extremes of the candle
+// are known, so the stop is taken when both are. The exit fills at the level, or at the open
+// when the candle gapped through it.
+func (e *Engine) TriggerExits(candle models.Candle) {
+	position, open := e.positionFor(candle.Symbol)
+	if !open {
+		return
+	}
+	isLong := position.Quantity > 0
+
+	var price float64
+	var reason string
+	switch {
+	case position.StopLossPrice != nil && isLong && candle.Low <= *position.StopLossPrice:
+		price, reason = math.Min(candle.Open, *position.StopLossPrice), stopReason(position.StopType)
+	case position.StopLossPrice != nil && !isLong && candle.High >= *position.StopLossPrice:
+		price, reason = math.Max(candle.Open, *position.StopLossPrice), stopReason(position.StopType)
+	case position.TargetPrice != nil && isLong && candle.High >= *position.TargetPrice:
+		price, reason = math.Max(candle.Open, *position.TargetPrice), "TAKE_PROFIT"
+	case position.TargetPrice != nil && !isLong && candle.Low <= *position.TargetPrice:
+		price, reason = math.Min(candle.Open, *position.TargetPrice), "TAKE_PROFIT"
+	default:
+		return
+	}
+
+	// Fill against the part of the candle up to the level, the whole candle replaces it next
+	touched := candle
+	touched.Close = price
+	touched.High = math.Max(candle.Open, price)
+	touched.Low = math.Min(candle.Open, price)
+	e.applyCandle(touched, false)
+
+	if err := e.ClosePosition(candle.Symbol, reason); err != nil {
+		e.logger.Error("Failed to close position for %s: %v", candle.Symbol, err)
+	}
+}
+
+// RunCycle runs one analysis, exit and entry pass over the buffered data
+func (e *Engine) RunCycle(ctx context.Context) {
+	e.stateMutex.RLock()
+	symbols := make([]string, len(e.tradingState.Watchlist))
+	for i, item := range e.tradingState.Watchlist {
+		symbols[i] = item.Symbol
+	}
+	e.stateMutex.RUnlock()
+
+	for _, symbol := range symbols {
+		// Higher timeframes first so the signal timeframe sees their trends
+		for i := len(e.timeframes) - 1; i >= 0; i-- {
+			interval := e.timeframes[i].name
+			if buffer := e.candleBuffer(symbol, interval); len(buffer) >= e.config.Trading.TechnicalPeriods.EMA200 {
+				e.updateTechnicalAnalysis(ctx, symbol, interval, buffer)
+			}
+		}
+	}
+
+	e.checkExitConditions(ctx)
+
+	if e.IsTrading() {
+		e.processTrading(ctx)
+	}
+}
+
+// CloseAllPositions closes every open position with the given reason
+func (e *Engine) CloseAllPositions(reason string) {
+	e.stateMutex.RLock()
+	symbols := make([]string, len(e.tradingState.Positions))
+	for i, position := range e.tradingState.Positions {
+		symbols[i] = position.Symbol
+	}
+	e.stateMutex.RUnlock()
+
+	for _, symbol := range symbols {
+		if err := e.ClosePosition(symbol, reason); err != nil {
+			e.logger.Error("Failed to close position for %s: %v", symbol, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"trading-engine/config"
+	"trading-engine/execution"
+	"trading-engine/models"
+)
+
+// State returns the engine state needed to resume trading after a restart
+func (e *Engine) State(ctx context.Context) (*models.EngineState, error) {
+	// Live venues report their own balances on start, the paper venue only has these
+	var balances []models.Balance
+	if e.config.Trading.Mode == config.TradingModePaper {
+		var err error
+		if balances, err = e.executor.GetBalances(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load balances from %s venue: %w", e.executor.Name(), err)
+		}
+	}
+
+	e.stateMutex.RLock()
+	defer e.stateMutex.RUnlock()
+
+	quoteFree := e.quoteBalance.Free
+	if balances != nil {
+		quoteFree = execution.FindBalance(balances, e.config.Trading.QuoteAsset).Free
+	}
+
+	settings := e.tradingState.Settings
+	state := &models.EngineState{
+		Positions:     make([]models.Position, len(e.tradingState.Positions)),
+		Settings:      &settings,
+		TotalPnL:      e.tradingState.TotalPnL,
+		DayPnL:        e.tradingState.DayPnL,
+		Balances:      balances,
+		HeldBack:      quoteFree - e.tradingState.AvailableBalance,
+		LastTradeTime: make(map[string]time.Time, len(e.lastTradeTime)),
+		Strategies:    make([]models.StrategyAccount, 0, len(e.strategies)),
+		SavedAt:       e.clock.Now(),
+	}
+	copy(state.Positions, e.tradingState.Positions)
+
+	for symbol, at := range e.lastTradeTime {
+		state.LastTradeTime[symbol] = at
+	}
+	for _, runner := range e.strategies {
+		state.Strategies = append(state.Strategies, models.StrategyAccount{
+			ID:          runner.instance.ID,
+			Available:   runner.available,
+			TotalPnL:    runner.totalPnL,
+			DayPnL:      runner.dayPnL,
+			PeakPnL:     runner.peakPnL,
+			MaxDrawdown: runner.maxDrawdown,
+			Wins:        runner.wins,
+			Losses:      runner.losses,
+		})
+	}
+
+	return state, nil
+}
+
+// RestoreState resumes from a saved state and must be called before Start. Automated trading
+// stays disabled until it is enabled again, the restored positions are managed meanwhile.
+func (e *Engine) RestoreState(state *models.EngineState) error {
+	if state.Settings != nil {
+		if err := validateStopMode(*state.Settings); err != nil {
+			return fmt.Errorf("invalid saved settings: %w", err)
+		}
+		if err := validateExitManagement(*state.Settings); err != nil {
+			return fmt.Errorf("invalid saved settings: %w", err)
+		}
+		if err := validateScaling(*state.Settings); err != nil {
+			return fmt.Errorf("invalid saved settings: %w", err)
+		}
+	}
+
+	// Balances are loaded from the venue on start, so the paper venue gets its own back first
+	if len(state.Balances) > 0 {
+		if restorer, ok := e.executor.(execution.BalanceRestorer); ok {
+			restorer.RestoreBalances(state.Balances)
+		}
+	}
+
+	positions := make([]models.Position, len(state.Positions))
+	for i, position := range state.Positions {
+		// Positions saved before exit management was persisted start it afresh
+		if position.InitialQuantity == 0 {
+			position.InitialQuantity = math.Abs(position.Quantity)
+		}
+		if position.BestPrice == 0 {
+			position.BestPrice = position.AvgBuyPrice
+		}
+		if position.Entries == 0 {
+			position.Entries = 1
+		}
+		positions[i] = position
+	}
+
+	// The daily P&L of a state saved on an earlier day no longer counts towards the limit
+	today := utcDay(e.clock.Now())
+	sameDay := utcDay(state.SavedAt).Equal(today)
+
+	e.stateMutex.Lock()
+	e.tradingState.Positions = positions
+	e.tradingState.TotalPnL = state.TotalPnL
+	e.tradingState.DayPnL = 0
+	if sameDay {
+		e.tradingState.DayPnL = state.DayPnL
+	}
+	e.pnlDay = today
+	e.heldBack = state.HeldBack
+	if state.Settings != nil {
+		e.tradingState.Settings = *state.Settings
+		e.tradingState.Settings.IsEnabled = false
+	}
+	for symbol, at := range state.LastTradeTime {
+		e.lastTradeTime[symbol] = at
+	}
+	for _, account := range state.Strategies {
+		runner := e.runnerFor(account.ID)
+		if runner == nil {
+			continue // The instance is no longer run
+		}
+		runner.available = account.Available
+		runner.totalPnL = account.TotalPnL
+		runner.dayPnL = 0
+		if sameDay {
+			runner.dayPnL = account.DayPnL
+		}
+		runner.peakPnL = account.PeakPnL
+		runner.maxDrawdown = account.MaxDrawdown
+		runner.wins = account.Wins
+		runner.losses = account.Losses
+	}
+	e.stateMutex.Unlock()
+
+	e.logger.WithFields(map[string]interface{}{
+		"positions":  len(positions),
+		"total_pnl":  state.TotalPnL,
+		"same_day":   sameDay,
+		"strategies": len(state.Strategies),
+		"saved_at":   state.SavedAt,
+	}).Info("Engine state restored")
+	return nil
+}
+
+// resumePositionTimers sets the hold-time timers of restored positions to the time they had
+// left, positions held past it are closed right away
+func (e *Engine) resumePositionTimers() {
+	remaining := make(map[string]time.Duration)
+
+	e.stateMutex.RLock()
+	for _, position := range e.tradingState.Positions {
+		settings := e.settingsFor(e.runnerFor(position.StrategyID))
+		holdTime := time.Duration(settings.MaxHoldTime) * time.Minute
+		remaining[position.Symbol] = holdTime - e.clock.Since(position.EntryTime)
+	}
+	e.stateMutex.RUnlock()
+
+	for symbol, left := range remaining {
+		if left < 0 {
+			left = 0
+		}
+		e.setPositionTimer(symbol, left)
+
+		e.logger.WithFields(map[string]interface{}{
+			"symbol":    symbol,
+			"remaining": left.String(),
+		}).Info("Resumed position timer")
+	}
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"trading-engine/clock"
+	"trading-engine/models"
+)
+
+func TestRestoreStateDayPnL(t *testing.T) {
+	tests := []struct {
+		name    string
+		savedAt time.Time
+		dayPnL  float64
+	}{
+		{"saved earlier today", time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), -40},
+		{"saved before midnight", time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC), 0},
+		{"saved days ago", time.Date(2023, 12, 20, 12, 0, 0, 0, time.UTC), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t, false)
+			e.clock.(*clock.Simulated).Advance(18 * time.Hour)
+			if err := e.SetStrategies([]models.StrategyInstance{{ID: "saved", Strategy: "signal"}}); err != nil {
+				t.Fatalf("failed to set strategies: %v", err)
+			}
+
+			err := e.RestoreState(&models.EngineState{
+				TotalPnL:   -250,
+				DayPnL:     -40,
+				Strategies: []models.StrategyAccount{{ID: "saved", TotalPnL: -250, DayPnL: -40}},
+				SavedAt:    tt.savedAt,
+			})
+			if err != nil {
+				t.Fatalf("failed to restore state: %v", err)
+			}
+
+			runner := e.strategies[0]
+			if e.tradingState.DayPnL != tt.dayPnL || runner.dayPnL != tt.dayPnL {
+				t.Errorf("daily P&L = %v, strategy %v, want %v", e.tradingState.DayPnL, runner.dayPnL, tt.dayPnL)
+			}
+			if e.tradingState.TotalPnL != -250 || runner.totalPnL != -250 {
+				t.Errorf("total P&L = %v, strategy %v, want -250", e.tradingState.TotalPnL, runner.totalPnL)
+			}
+		})
+	}
+}
+
+func TestDayPnLResetsAtMidnight(t *testing.T) {
+	e := newTestEngine(t, false)
+	sim := e.clock.(*clock.Simulated)
+	sim.Advance(18 * time.Hour)
+	if err := e.SetStrategies([]models.StrategyInstance{{ID: "daily", Strategy: "signal"}}); err != nil {
+		t.Fatalf("failed to set strategies: %v", err)
+	}
+	runner := e.strategies[0]
+
+	e.scheduleDayRollover()
+	e.stateMutex.Lock()
+	e.attribute("daily", 0, -30)
+	e.tradingState.DayPnL -= 30
+	e.stateMutex.Unlock()
+
+	for _, check := range []struct {
+		advance time.Duration
+		dayPnL  float64
+	}{
+		{6*time.Hour - time.Second, -30},
+		{time.Second, 0},
+	} {
+		sim.Advance(check.advance)
+
+		e.stateMutex.RLock()
+		dayPnL, runnerDayPnL := e.tradingState.DayPnL, runner.dayPnL
+		e.stateMutex.RUnlock()
+		if dayPnL != check.dayPnL || runnerDayPnL != check.dayPnL {
+			t.Errorf("at %v daily P&L = %v, strategy %v, want %v", sim.Now(), dayPnL, runnerDayPnL, check.dayPnL)
+		}
+	}
+
+	// Nothing traded on the next day, the rollover after it still fires
+	e.stateMutex.Lock()
+	e.tradingState.DayPnL = -10
+	e.stateMutex.Unlock()
+	sim.Advance(24 * time.Hour)
+	if dayPnL := e.GetTradingState().DayPnL; dayPnL != 0 {
+		t.Errorf("daily P&L after the second midnight = %v, want 0", dayPnL)
+	}
+	if runner.totalPnL != -30 {
+		t.Errorf("total P&L = %v, want -30", runner.totalPnL)
+	}
+}
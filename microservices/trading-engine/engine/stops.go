@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"fmt"
+
+	"trading-engine/models"
+)
+
+// exitDistances returns how far from an entry at price the stop loss and take profit sit, by
+// the stop mode of the settings. Swing and ATR modes fall back to fixed percentages when the
+// analysis has no usable levels yet.
+func exitDistances(settings models.TradingSettings, technical *models.TechnicalAnalysis, price float64, isLong bool) (float64, float64) {
+	stopDistance := price * settings.StopLossPercent / 100
+	targetDistance := price * settings.TakeProfitPercent / 100
+
+	switch settings.StopMode {
+	case models.StopModeATR:
+		if technical.ATR > 0 {
+			stopDistance = technical.ATR * settings.ATRStopMultiple
+			targetDistance = technical.ATR * settings.ATRTargetMultiple
+		}
+	case models.StopModeSwing:
+		// The price targets are computed for the direction of the signal
+		stop, target := price-technical.StopLoss, technical.TakeProfit-price
+		if !isLong {
+			stop, target = -stop, -target
+		}
+		if stop > 0 && target > 0 {
+			stopDistance, targetDistance = stop, target
+		}
+	}
+
+	return stopDistance, targetDistance
+}
+
+// validateStopMode checks the stop mode of the settings and the parameters it needs
+func validateStopMode(settings models.TradingSettings) error {
+	switch settings.StopMode {
+	case "", models.StopModePercent, models.StopModeSwing:
+	case models.StopModeATR:
+		if settings.ATRStopMultiple <= 0 || settings.ATRTargetMultiple <= 0 {
+			return fmt.Errorf("ATR stop and target multiples must be positive")
+		}
+	default:
+		return fmt.Errorf("stop mode must be one of %s, %s or %s", models.StopModePercent, models.StopModeSwing, models.StopModeATR)
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"trading-engine/events"
+	"trading-engine/models"
+	"trading-engine/strategy"
+)
+
+// defaultStrategyID identifies the instance run when no strategy instances are configured
+const defaultStrategyID = "default"
+
+// strategyRunner is a strategy instance with its capital budget and results
+type strategyRunner struct {
+	instance models.StrategyInstance
+	strategy strategy.Strategy
+	symbols  map[string]bool
+	mu       sync.Mutex // The strategy is never called concurrently
+
+	// Accounting, guarded by the engine's stateMutex
+	available   float64 // Unused part of the capital budget
+	totalPnL    float64 // Realized P&L net of fees
+	dayPnL      float64 // Realized P&L net of fees since midnight UTC
+	peakPnL     float64
+	maxDrawdown float64
+	wins        int
+	losses      int
+}
+
+// newStrategyRunner creates the strategy of an instance
+func newStrategyRunner(instance models.StrategyInstance) (*strategyRunner, error) {
+	if instance.ID == "" {
+		return nil, fmt.Errorf("strategy instance ID is required")
+	}
+	if instance.Capital < 0 {
+		return nil, fmt.Errorf("capital of strategy %s cannot be negative", instance.ID)
+	}
+	if instance.Settings != nil {
+		if err := validateStopMode(*instance.Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings for strategy %s: %w", instance.ID, err)
+		}
+		if err := validateExitManagement(*instance.Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings for strategy %s: %w", instance.ID, err)
+		}
+		if err := validateScaling(*instance.Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings for strategy %s: %w", instance.ID, err)
+		}
+	}
+
+	s, err := strategy.New(instance.Strategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create strategy %s: %w", instance.ID, err)
+	}
+
+	symbols := make(map[string]bool, len(instance.Symbols))
+	for _, symbol := range instance.Symbols {
+		symbols[symbol] = true
+	}
+
+	return &strategyRunner{
+		instance:  instance,
+		strategy:  s,
+		symbols:   symbols,
+		available: instance.Capital,
+	}, nil
+}
+
+// trades reports whether the instance trades a symbol
+func (r *strategyRunner) trades(symbol string) bool {
+	return len(r.symbols) == 0 || r.symbols[symbol]
+}
+
+// ask runs one strategy callback
+func (r *strategyRunner) ask(call func(s strategy.Strategy) []strategy.Intent) []strategy.Intent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return call(r.strategy)
+}
+
+// loadStrategyInstances reads strategy instances from a JSON file
+func loadStrategyInstances(path string) ([]models.StrategyInstance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read strategies file: %w", err)
+	}
+
+	var instances []models.StrategyInstance
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, fmt.Errorf("failed to parse strategies file: %w", err)
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("strategies file %s defines no strategies", path)
+	}
+	return instances, nil
+}
+
+// SetStrategies replaces the strategy instances run side by side. Open positions of an
+// instance no longer run follow the engine settings.
+func (e *Engine) SetStrategies(instances []models.StrategyInstance) error {
+	runners := make([]*strategyRunner, 0, len(instances))
+	seen := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if seen[instance.ID] {
+			return fmt.Errorf("duplicate strategy ID %s", instance.ID)
+		}
+		seen[instance.ID] = true
+
+		runner, err := newStrategyRunner(instance)
+		if err != nil {
+			return err
+		}
+		runners = append(runners, runner)
+	}
+
+	e.stateMutex.Lock()
+	e.strategies = runners
+	e.stateMutex.Unlock()
+
+	for _, runner := range runners {
+		e.logger.WithFields(map[string]interface{}{
+			"id":       runner.instance.ID,
+			"strategy": runner.strategy.Name(),
+			"capital":  runner.instance.Capital,
+			"symbols":  runner.instance.Symbols,
+		}).Info("Strategy configured")
+	}
+	return nil
+}
+
+// runners returns the strategy instances
+func (e *Engine) runners() []*strategyRunner {
+	e.stateMutex.RLock()
+	defer e.stateMutex.RUnlock()
+	return e.strategies
+}
+
+// runnerFor returns the strategy instance with an ID, the caller must hold stateMutex
+func (e *Engine) runnerFor(id string) *strategyRunner {
+	for _, runner := range e.strategies {
+		if runner.instance.ID == id {
+			return runner
+		}
+	}
+	return nil
+}
+
+// settingsFor returns the settings of a strategy instance, the caller must hold stateMutex
+func (e *Engine) settingsFor(runner *strategyRunner) models.TradingSettings {
+	if runner == nil || runner.instance.Settings == nil {
+		return e.tradingState.Settings
+	}
+	return *runner.instance.Settings
+}
+
+// strategyBalance returns the balance an instance may commit, the caller must hold stateMutex
+func (e *Engine) strategyBalance(runner *strategyRunner) float64 {
+	if runner == nil || runner.instance.Capital <= 0 {
+		return e.tradingState.AvailableBalance
+	}
+	return math.Min(runner.available, e.tradingState.AvailableBalance)
+}
+
+// attribute books a change to the budget and realized P&L of a strategy instance, the caller
+// must hold stateMutex
+func (e *Engine) attribute(id string, capital, pnl float64) {
+	e.rollDay()
+	runner := e.runnerFor(id)
+	if runner == nil {
+		return
+	}
+
+	runner.available += capital
+	runner.totalPnL += pnl
+	runner.dayPnL += pnl
+	runner.peakPnL = math.Max(runner.peakPnL, runner.totalPnL)
+	runner.maxDrawdown = math.Max(runner.maxDrawdown, runner.peakPnL-runner.totalPnL)
+}
+
+// utcDay returns the midnight UTC starting the day of a time
+func utcDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// rollDay starts the daily P&L afresh once midnight UTC has passed, the caller must hold
+// stateMutex
+func (e *Engine) rollDay() {
+	day := utcDay(e.clock.Now())
+	if day.Equal(e.pnlDay) {
+		return
+	}
+	e.pnlDay = day
+	e.tradingState.DayPnL = 0
+	for _, runner := range e.strategies {
+		runner.dayPnL = 0
+	}
+}
+
+// scheduleDayRollover starts the daily P&L afresh at the next midnight UTC, and every
+// midnight after it, even when nothing is traded
+func (e *Engine) scheduleDayRollover() {
+	now := e.clock.Now()
+	untilMidnight := utcDay(now).Add(24 * time.Hour).Sub(now)
+
+	e.timersMutex.Lock()
+	defer e.timersMutex.Unlock()
+
+	if e.dayTimer != nil {
+		e.dayTimer.Stop()
+	}
+	e.dayTimer = e.clock.AfterFunc(untilMidnight, func() {
+		e.stateMutex.Lock()
+		e.rollDay()
+		e.stateMutex.Unlock()
+
+		e.logger.Info("Daily P&L reset for %s", utcDay(e.clock.Now()).Format("2006-01-02"))
+		e.scheduleDayRollover()
+	})
+}
+
+// StrategyPerformance returns the results of each strategy instance
+func (e *Engine) StrategyPerformance() []models.StrategyPerformance {
+	e.stateMutex.RLock()
+	defer e.stateMutex.RUnlock()
+
+	performance := make([]models.StrategyPerformance, 0, len(e.strategies))
+	for _, runner := range e.strategies {
+		result := models.StrategyPerformance{
+			ID:               runner.instance.ID,
+			Strategy:         runner.strategy.Name(),
+			Capital:          runner.instance.Capital,
+			AvailableBalance: e.strategyBalance(runner),
+			TotalPnL:         runner.totalPnL,
+			TotalTrades:      runner.wins + runner.losses,
+			WinningTrades:    runner.wins,
+			LosingTrades:     runner.losses,
+			MaxDrawdown:      runner.maxDrawdown,
+		}
+		if result.TotalTrades > 0 {
+			result.WinRate = float64(runner.wins) / float64(result.TotalTrades) * 100
+		}
+		for _, position := range e.tradingState.Positions {
+			if position.StrategyID == runner.instance.ID {
+				result.OpenPositions++
+			}
+		}
+		performance = append(performance, result)
+	}
+	return performance
+}
+
+// positionFor returns a copy of the open position of a symbol
+func (e *Engine) positionFor(symbol string) (models.Position, bool) {
+	e.stateMutex.RLock()
+	defer e.stateMutex.RUnlock()
+
+	for _, position := range e.tradingState.Positions {
+		if position.Symbol == symbol {
+			return position, true
+		}
+	}
+	return models.Position{}, false
+}
+
+// recordTrade adds a trade to the history, counts exits towards the results of the strategy
+// that made them, queues the trade for its OnFill and publishes the fill. The caller must
+// hold stateMutex.
+func (e *Engine) recordTrade(trade models.Trade) {
+	e.tradingState.Trades = append(e.tradingState.Trades, trade)
+	e.filledTrades = append(e.filledTrades, trade)
+	e.events.Publish(events.OrderFilled{Header: events.Header{At: e.clock.Now()}, Trade: trade})
+
+	if runner := e.runnerFor(trade.StrategyID); runner != nil && trade.PnL != nil {
+		if *trade.PnL > 0 {
+			runner.wins++
+		} else {
+			runner.losses++
+		}
+	}
+}
+
+// dispatchFills passes the trades recorded since the last call to the strategies that made
+// them and acts on the intents they return
+func (e *Engine) dispatchFills(ctx context.Context) {
+	e.stateMutex.Lock()
+	trades := e.filledTrades
+	e.filledTrades = nil
+	e.stateMutex.Unlock()
+
+	for _, trade := range trades {
+		e.stateMutex.RLock()
+		runner := e.runnerFor(trade.StrategyID)
+		e.stateMutex.RUnlock()
+		if runner == nil {
+			continue
+		}
+
+		intents := runner.ask(func(s strategy.Strategy) []strategy.Intent {
+			return s.OnFill(trade)
+		})
+		e.applyIntents(ctx, runner, intents)
+	}
+}
+
+// applyIntents acts on the intents of a strategy instance
+func (e *Engine) applyIntents(ctx context.Context, runner *strategyRunner, intents []strategy.Intent) {
+	for _, intent := range intents {
+		switch intent.Action {
+		case strategy.ActionOpenLong:
+			e.enterPosition(ctx, runner, intent, true)
+		case strategy.ActionOpenShort:
+			e.enterPosition(ctx, runner, intent, false)
+		case strategy.ActionClose:
+			// A strategy only closes the positions it opened
+			position, open := e.positionFor(intent.Symbol)
+			if !open || position.StrategyID != runner.instance.ID {
+				continue
+			}
+			reason := intent.Reason
+			if reason == "" {
+				reason = "STRATEGY"
+			}
+			if err := e.ClosePosition(intent.Symbol, reason); err != nil {
+				e.logger.Error("Failed to close position for %s: %v", intent.Symbol, err)
+			}
+		default:
+			e.logger.Warn("Ignored unknown %s intent for %s from strategy %s", intent.Action, intent.Symbol, runner.instance.ID)
+		}
+	}
+}
+
+// enterPosition opens a position for an entry intent once the risk limits, cooldown and
+// trend filter allow it. An instance with its own settings is held to its own daily loss and
+// position limits, the others to those of the whole engine.
+func (e *Engine) enterPosition(ctx context.Context, runner *strategyRunner, intent strategy.Intent, isLong bool) {
+	if !runner.trades(intent.Symbol) {
+		return
+	}
+
+	e.stateMutex.Lock()
+	e.rollDay()
+	settings := e.settingsFor(runner)
+	currentPositions := len(e.tradingState.Positions)
+	dayPnL := e.tradingState.DayPnL
+	if runner.instance.Settings != nil {
+		currentPositions = 0
+		for _, position := range e.tradingState.Positions {
+			if position.StrategyID == runner.instance.ID {
+				currentPositions++
+			}
+		}
+		dayPnL = runner.dayPnL
+	}
+	availableBalance := e.strategyBalance(runner)
+	var item models.WatchlistItem
+	found := false
+	for _, watched := range e.tradingState.Watchlist {
+		if watched.Symbol == intent.Symbol {
+			item, found = watched, true
+			break
+		}
+	}
+	e.stateMutex.Unlock()
+
+	if !found || !item.IsActive || item.Technical == nil {
+		return
+	}
+
+	// A renewed entry on a symbol already held may add to the position instead of opening one
+	adding := e.canScaleIn(runner, intent.Symbol, isLong, item.Price, settings)
+
+	// Check daily loss limit, a day in profit never blocks entries
+	if e.checkRiskLimit(runner, intent.Symbol, events.LimitDailyLoss, dayPnL <= -settings.MaxDailyLoss, dayPnL, settings.MaxDailyLoss) {
+		e.logger.Warn("Daily loss limit reached for strategy %s: %.2f", runner.instance.ID, dayPnL)
+		return
+	}
+
+	// Check if we can open new positions
+	if !adding && e.checkRiskLimit(runner, intent.Symbol, events.LimitMaxPositions, currentPositions >= settings.MaxPositions, float64(currentPositions), float64(settings.MaxPositions)) {
+		return
+	}
+
+	// Check available balance
+	if e.checkRiskLimit(runner, intent.Symbol, events.LimitBalance, availableBalance < 1000, availableBalance, 1000) {
+		e.logger.Warn("Insufficient balance for strategy %s: %.2f", runner.instance.ID, availableBalance)
+		return
+	}
+
+	// Check if we already have a position for this symbol that cannot be added to, or are in
+	// its cooldown period
+	if (!adding && e.hasPosition(intent.Symbol)) || e.isInCooldown(intent.Symbol) {
+		return
+	}
+
+	// Entries must follow the trend of the higher timeframe
+	direction := "BUY"
+	if !isLong {
+		direction = "SELL"
+	}
+	if !e.trendAgrees(intent.Symbol, direction) {
+		return
+	}
+
+	if !isLong && !e.config.Trading.ShortSelling {
+		return // Bearish signals are ignored unless shorting is enabled
+	}
+
+	signal := intent.Reason
+	if signal == "" {
+		signal = item.Technical.Signal
+	}
+	e.openPosition(ctx, runner, item, settings, isLong, signal, adding)
+}
+
+// checkRiskLimit returns hit, publishing RiskLimitHit when a limit starts blocking the entries
+// of a strategy instance rather than on every blocked entry
+func (e *Engine) checkRiskLimit(runner *strategyRunner, symbol, limit string, hit bool, value, threshold float64) bool {
+	key := runner.instance.ID + "@" + limit
+
+	e.stateMutex.Lock()
+	wasHit := e.riskLimits[key]
+	e.riskLimits[key] = hit
+	e.stateMutex.Unlock()
+
+	if hit && !wasHit {
+		e.events.Publish(events.RiskLimitHit{
+			Header:     events.Header{At: e.clock.Now()},
+			StrategyID: runner.instance.ID,
+			Symbol:     symbol,
+			Limit:      limit,
+			Value:      value,
+			Threshold:  threshold,
+		})
+	}
+	return hit
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"trading-engine/clock"
+	"trading-engine/events"
+	"trading-engine/models"
+	"trading-engine/strategy"
+)
+
+func TestDailyLossLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		pnl     float64
+		advance time.Duration // Time passing between the booking and the entry
+		blocked bool
+	}{
+		{"day in profit", 500, 0, false},
+		{"loss below the limit", -99, 0, false},
+		{"loss at the limit", -100, 0, true},
+		{"loss past the limit", -150, 0, true},
+		{"loss made yesterday", -150, 24 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t, false)
+			err := e.SetStrategies([]models.StrategyInstance{{
+				ID:       "limited",
+				Strategy: "signal",
+				// No position may be opened, so an entry allowed by the daily loss limit
+				// stops at the position limit without sending an order
+				Settings: &models.TradingSettings{MaxDailyLoss: 100, MaxPositions: 0},
+			}})
+			if err != nil {
+				t.Fatalf("failed to set strategies: %v", err)
+			}
+			runner := e.strategies[0]
+
+			e.stateMutex.Lock()
+			e.tradingState.Watchlist = []models.WatchlistItem{{
+				Symbol:    "BTCUSDT",
+				Price:     30000,
+				Technical: &models.TechnicalAnalysis{},
+				IsActive:  true,
+			}}
+			// Earlier days count towards the total but not the daily P&L
+			runner.totalPnL = -1000
+			e.attribute(runner.instance.ID, 0, tt.pnl)
+			e.stateMutex.Unlock()
+
+			e.clock.(*clock.Simulated).Advance(tt.advance)
+			e.enterPosition(context.Background(), runner, strategy.Intent{Action: strategy.ActionOpenLong, Symbol: "BTCUSDT"}, true)
+
+			if blocked := e.riskLimits["limited@"+events.LimitDailyLoss]; blocked != tt.blocked {
+				t.Errorf("daily loss limit blocked entry = %v, want %v", blocked, tt.blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/config"
+	"trading-engine/events"
+	"trading-engine/models"
+)
+
+// historicalCandles is the number of candles loaded per timeframe on startup
+const historicalCandles = 200
+
+// timeframe is a candle interval buffered for every symbol
+type timeframe struct {
+	name string
+	step time.Duration
+}
+
+// bucket is the aggregate of the closed base candles of an open higher timeframe candle
+type bucket struct {
+	candle  models.Candle
+	through time.Time // Open time of the last base candle folded in
+}
+
+// resolveTimeframes returns the buffered timeframes in ascending order: the configured ones
+// plus the signal and trend timeframes. The smallest one is streamed and every other one is
+// aggregated from it, so they must all be multiples of it.
+func resolveTimeframes(cfg *config.TradingConfig) ([]timeframe, error) {
+	names := append([]string{cfg.KlineInterval}, cfg.Timeframes...)
+	if cfg.TrendTimeframe != "" {
+		names = append(names, cfg.TrendTimeframe)
+	}
+
+	var timeframes []timeframe
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		step, err := binance.IntervalDuration(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeframe %q: %w", name, err)
+		}
+		timeframes = append(timeframes, timeframe{name: name, step: step})
+	}
+
+	sort.Slice(timeframes, func(i, j int) bool {
+		return timeframes[i].step < timeframes[j].step
+	})
+	for _, tf := range timeframes[1:] {
+		if tf.step%timeframes[0].step != 0 {
+			return nil, fmt.Errorf("timeframe %s is not a multiple of the base timeframe %s", tf.name, timeframes[0].name)
+		}
+	}
+	return timeframes, nil
+}
+
+// baseTimeframe returns the streamed timeframe every other one is aggregated from
+func (e *Engine) baseTimeframe() string {
+	return e.timeframes[0].name
+}
+
+// candleBuffer returns a snapshot of the candles buffered for a symbol at a timeframe
+func (e *Engine) candleBuffer(symbol, interval string) []models.Candle {
+	e.buffersMutex.RLock()
+	defer e.buffersMutex.RUnlock()
+
+	buffer := e.dataBuffers[symbol][interval]
+	snapshot := make([]models.Candle, len(buffer))
+	copy(snapshot, buffer)
+	return snapshot
+}
+
+// loadHistoricalCandles seeds every timeframe of a symbol from the klines endpoint. The open
+// candle of a higher timeframe is rebuilt from the base candles closed before now, which
+// become its bucket.
+func (e *Engine) loadHistoricalCandles(ctx context.Context, symbol string) error {
+	base := e.timeframes[0]
+	now := e.clock.Now()
+	through := binance.IntervalOpen(now, base.step).Add(-base.step)
+
+	for _, tf := range e.timeframes {
+		candles, err := e.binanceClient.FetchHistoricalKlines(ctx, symbol, tf.name, historicalCandles)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s candles: %w", tf.name, err)
+		}
+
+		var open bucket
+		seeded := false
+		if tf.name != base.name {
+			openTime := binance.IntervalOpen(now, tf.step)
+			if last := len(candles) - 1; last >= 0 && !candles[last].Timestamp.Before(openTime) {
+				candles = candles[:last]
+			}
+			if open, seeded, err = e.closedBucket(ctx, symbol, openTime, through); err != nil {
+				return fmt.Errorf("failed to rebuild the open %s candle: %w", tf.name, err)
+			}
+			if seeded {
+				candles = append(candles, open.candle)
+			}
+		}
+
+		e.buffersMutex.Lock()
+		if e.dataBuffers[symbol] == nil {
+			e.dataBuffers[symbol] = make(map[string][]models.Candle)
+		}
+		e.dataBuffers[symbol][tf.name] = candles
+		if seeded {
+			e.buckets[bucketKey(symbol, tf.name)] = open
+		}
+		e.buffersMutex.Unlock()
+
+		e.logger.Debug("Loaded %d historical %s candles for %s", len(candles), tf.name, symbol)
+	}
+	return nil
+}
+
+// closedBucket aggregates the base candles opened from openTime through through into the
+// bucket of a higher timeframe. It reports false when no base candle of the bucket has closed.
+func (e *Engine) closedBucket(ctx context.Context, symbol string, openTime, through time.Time) (bucket, bool, error) {
+	base := e.timeframes[0]
+
+	var aggregate models.Candle
+	folded := false
+	for from := openTime; !from.After(through); {
+		page, err := e.binanceClient.FetchKlines(ctx, symbol, base.name, from, through, binance.MaxKlinesPerRequest)
+		if err != nil {
+			return bucket{}, false, err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for _, candle := range page {
+			if !folded {
+				aggregate = candle
+				aggregate.Timestamp = openTime
+				aggregate.Time = openTime.Unix()
+				folded = true
+				continue
+			}
+			aggregate = mergeCandles(aggregate, candle)
+		}
+		from = page[len(page)-1].Timestamp.Add(base.step)
+	}
+
+	return bucket{candle: aggregate, through: through}, folded, nil
+}
+
+// applyCandle applies a base timeframe candle to every timeframe of its symbol. The base
+// buffer takes it as is, higher timeframes merge it into the candle of the bucket containing
+// it and fold it into the bucket once closed. Each candle that closes is published. It returns
+// a snapshot of each buffer.
+func (e *Engine) applyCandle(candle models.Candle, closed bool) map[string][]models.Candle {
+	e.buffersMutex.Lock()
+	defer e.buffersMutex.Unlock()
+
+	buffers := e.dataBuffers[candle.Symbol]
+	if buffers == nil {
+		buffers = make(map[string][]models.Candle)
+		e.dataBuffers[candle.Symbol] = buffers
+	}
+
+	snapshots := make(map[string][]models.Candle, len(e.timeframes))
+	for i, tf := range e.timeframes {
+		current := candle
+		if i > 0 {
+			var ok bool
+			if current, ok = e.aggregate(tf, candle, closed); !ok {
+				continue
+			}
+		}
+
+		buffer := upsertCandle(buffers[tf.name], current, e.config.Trading.PriceBufferSize)
+		buffers[tf.name] = buffer
+
+		// A higher timeframe candle closes with the last base candle of its bucket
+		if closed && !candle.Timestamp.Add(e.timeframes[0].step).Before(current.Timestamp.Add(tf.step)) {
+			e.events.Publish(events.CandleClosed{
+				Header:   events.Header{At: e.clock.Now()},
+				Symbol:   candle.Symbol,
+				Interval: tf.name,
+				Candle:   current,
+			})
+		}
+
+		snapshot := make([]models.Candle, len(buffer))
+		copy(snapshot, buffer)
+		snapshots[tf.name] = snapshot
+	}
+	return snapshots
+}
+
+// aggregate returns the higher timeframe candle containing a base candle, folding the base
+// candle into its bucket once closed. Base candles already folded in are ignored. The caller
+// must hold buffersMutex.
+func (e *Engine) aggregate(tf timeframe, candle models.Candle, closed bool) (models.Candle, bool) {
+	key := bucketKey(candle.Symbol, tf.name)
+	openTime := binance.IntervalOpen(candle.Timestamp, tf.step)
+
+	current, exists := e.buckets[key]
+	if exists && current.candle.Timestamp.Equal(openTime) {
+		if !candle.Timestamp.After(current.through) {
+			return models.Candle{}, false
+		}
+	} else {
+		exists = false
+	}
+
+	merged := candle
+	merged.Timestamp = openTime
+	merged.Time = openTime.Unix()
+	if exists {
+		merged = mergeCandles(current.candle, candle)
+	}
+
+	if closed {
+		e.buckets[key] = bucket{candle: merged, through: candle.Timestamp}
+	}
+	return merged, true
+}
+
+// mergeCandles extends an aggregate candle with a later candle
+func mergeCandles(aggregate, next models.Candle) models.Candle {
+	merged := aggregate
+	if next.High > merged.High {
+		merged.High = next.High
+	}
+	if next.Low < merged.Low {
+		merged.Low = next.Low
+	}
+	merged.Close = next.Close
+	merged.Volume += next.Volume
+	return merged
+}
+
+// upsertCandle updates the in-progress candle in place, or appends the candle once a new
+// interval opens, dropping the oldest candle beyond size
+func upsertCandle(buffer []models.Candle, candle models.Candle, size int) []models.Candle {
+	last := len(buffer) - 1
+	switch {
+	case last >= 0 && buffer[last].Time == candle.Time:
+		buffer[last] = candle
+	case last >= 0 && buffer[last].Time > candle.Time:
+		// Late update for a candle that has already been superseded
+	default:
+		if len(buffer) >= size {
+			buffer = buffer[1:]
+		}
+		buffer = append(buffer, candle)
+	}
+	return buffer
+}
+
+// bucketKey returns the key of the open bucket of a symbol at a timeframe
+func bucketKey(symbol, interval string) string {
+	return symbol + "@" + interval
+}
+
+// trendAgrees reports whether the trend of the trend timeframe supports an entry in the
+// direction of a signal. Without a distinct trend timeframe every entry is allowed.
+func (e *Engine) trendAgrees(symbol, signal string) bool {
+	trendTimeframe := e.config.Trading.TrendTimeframe
+	if trendTimeframe == "" || trendTimeframe == e.config.Trading.KlineInterval {
+		return true
+	}
+
+	analysis, ok := e.techAnalyzer.GetCachedAnalysis(symbol, trendTimeframe)
+	if !ok {
+		return false
+	}
+
+	switch signal {
+	case "STRONG_BUY", "BUY":
+		return analysis.TrendDirection == "UPTREND"
+	case "STRONG_SELL", "SELL":
+		return analysis.TrendDirection == "DOWNTREND"
+	}
+	return false
+}
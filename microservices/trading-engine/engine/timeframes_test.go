@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/clock"
+	"trading-engine/config"
+	"trading-engine/models"
+)
+
+// klinesResponse renders candles of a given volume opening every step from first through last
+func klinesResponse(first, last time.Time, step time.Duration, volume float64) string {
+	var klines []string
+	for at := first; !at.After(last); at = at.Add(step) {
+		klines = append(klines, fmt.Sprintf(`[%d,"100","101","99","100","%g"]`, at.UnixMilli(), volume))
+	}
+	return "[" + strings.Join(klines, ",") + "]"
+}
+
+func TestLoadHistoricalCandlesRebuildsOpenCandle(t *testing.T) {
+	e := newTestEngine(t, false)
+	start := e.clock.Now()
+	e.clock.(*clock.Simulated).Advance(3*time.Minute + 30*time.Second)
+
+	// The 5m candle in progress reports a volume of 100, including the 1m candle in progress
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("interval") == "5m":
+			fmt.Fprint(w, klinesResponse(start.Add(-10*time.Minute), start, 5*time.Minute, 100))
+		case query.Get("startTime") == "":
+			fmt.Fprint(w, klinesResponse(start, start.Add(3*time.Minute), time.Minute, 1))
+		default:
+			from, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+			through, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+			fmt.Fprint(w, klinesResponse(time.UnixMilli(from), time.UnixMilli(through), time.Minute, 1))
+		}
+	}))
+	defer server.Close()
+
+	e.binanceClient = binance.NewClient(&config.BinanceConfig{
+		APIBaseURL:       server.URL,
+		RateLimit:        6000,
+		RetryAttempts:    1,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	}, e.logger)
+	e.timeframes = []timeframe{{name: "1m", step: time.Minute}, {name: "5m", step: 5 * time.Minute}}
+
+	if err := e.loadHistoricalCandles(context.Background(), "BTCUSDT"); err != nil {
+		t.Fatalf("loadHistoricalCandles failed: %v", err)
+	}
+
+	buffer := e.candleBuffer("BTCUSDT", "5m")
+	if len(buffer) != 3 {
+		t.Fatalf("5m buffer holds %d candles, want 3", len(buffer))
+	}
+	if open := buffer[2]; !open.Timestamp.Equal(start) || open.Volume != 3 {
+		t.Errorf("open 5m candle = %+v, want the 3 closed 1m candles from %s", open, start)
+	}
+
+	// The 1m candle in progress closes, its volume is counted once
+	snapshots := e.applyCandle(models.Candle{
+		Symbol: "BTCUSDT", Timestamp: start.Add(3 * time.Minute), Time: start.Add(3 * time.Minute).Unix(),
+		Open: 100, High: 101, Low: 99, Close: 100, Volume: 1,
+	}, true)
+	if got := snapshots["5m"][2].Volume; got != 4 {
+		t.Errorf("5m volume after the next close = %v, want 4", got)
+	}
+}
@@ -0,0 +1,427 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/events"
+	"trading-engine/execution"
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// startUserData follows order updates and balance changes on the user data streams of the
+// spot account and, with short selling, the cross margin account
+func (e *Engine) startUserData(ctx context.Context) error {
+	orders := make(chan models.ExecutionReport, userDataBufferSize)
+	balances := make(chan models.AccountUpdate, userDataBufferSize)
+
+	for _, stream := range []*binance.UserDataStream{e.userData, e.marginUserData} {
+		if stream == nil {
+			continue
+		}
+		stream.AddOrderSubscriber(orders)
+		stream.AddBalanceSubscriber(balances)
+
+		if err := stream.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	go e.processUserData(ctx, orders, balances)
+	return nil
+}
+
+// processUserData applies user data events until the engine stops
+func (e *Engine) processUserData(ctx context.Context, orders <-chan models.ExecutionReport, balances <-chan models.AccountUpdate) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case report := <-orders:
+			e.handleExecutionReport(report)
+		case update := <-balances:
+			e.handleAccountUpdate(update)
+		}
+	}
+}
+
+// handleExecutionReport applies an order update. Updates arriving while the REST response of
+// an order on the same symbol is still being recorded are deferred until endOrder.
+func (e *Engine) handleExecutionReport(report models.ExecutionReport) {
+	e.logger.WithFields(map[string]interface{}{
+		"symbol":       report.Order.Symbol,
+		"order_id":     report.Order.OrderID,
+		"execution":    report.ExecutionType,
+		"status":       report.Order.Status,
+		"executed_qty": report.Order.ExecutedQty,
+	}).Debug("Received order update")
+
+	if report.RejectReason != "" {
+		e.logger.Warn("Order %d for %s was rejected: %s", report.Order.OrderID, report.Order.Symbol, report.RejectReason)
+	}
+
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	if e.pendingOrders[report.Order.Symbol] {
+		e.deferredReports[report.Order.Symbol] = append(e.deferredReports[report.Order.Symbol], report)
+		return
+	}
+	e.applyExecution(report)
+}
+
+// applyExecution applies the fills of an engine order not yet reflected in the trading state.
+// Orders are matched by their client order ID, known even when the order request failed
+// without a response. The caller must hold stateMutex.
+func (e *Engine) applyExecution(report models.ExecutionReport) {
+	order := report.Order
+	recorded, tracked := e.orderFills[order.ClientOrderID]
+	if !tracked {
+		return // Orders placed outside the engine do not change its positions
+	}
+
+	if quantity := order.ExecutedQty - recorded; quantity > 0 {
+		e.orderFills[order.ClientOrderID] = order.ExecutedQty
+
+		price := report.LastPrice
+		if price == 0 {
+			price = order.AvgPrice
+		}
+		fee := execution.QuoteFee(&models.Order{
+			Symbol:          order.Symbol,
+			AvgPrice:        price,
+			Commission:      report.LastCommission,
+			CommissionAsset: report.CommissionAsset,
+		}, e.config.Trading.QuoteAsset)
+
+		e.applyFill(order.Symbol, order.Side, quantity, price, fee, order.Timestamp, e.orderStrategies[order.ClientOrderID])
+	}
+
+	switch order.Status {
+	case "FILLED", "CANCELED", "REJECTED", "EXPIRED", "EXPIRED_IN_MATCH":
+		e.untrackOrder(order.ClientOrderID)
+	}
+}
+
+// fillExitDistances returns the stop loss and take profit distances of a fill at price, by
+// the stop mode of the settings like an entry sent by the engine. Without an analysis of the
+// symbol they are fixed percentages. The caller must hold stateMutex.
+func (e *Engine) fillExitDistances(symbol string, settings models.TradingSettings, price float64, isLong bool) (float64, float64) {
+	for _, item := range e.tradingState.Watchlist {
+		if item.Symbol == symbol && item.Technical != nil {
+			return exitDistances(settings, item.Technical, price, isLong)
+		}
+	}
+	return price * settings.StopLossPercent / 100, price * settings.TakeProfitPercent / 100
+}
+
+// applyFill opens, adds to, reduces or closes the position of a symbol with a fill reported
+// by the user data stream for an order of a strategy instance. The caller must hold
+// stateMutex.
+func (e *Engine) applyFill(symbol, side string, quantity, price, fee float64, at time.Time, strategyID string) {
+	signedQty := quantity
+	if side == "SELL" {
+		signedQty = -quantity
+	}
+
+	positionIndex := -1
+	for i, p := range e.tradingState.Positions {
+		if p.Symbol == symbol {
+			positionIndex = i
+			break
+		}
+	}
+
+	// Fills on an open position belong to the instance that opened it
+	if positionIndex != -1 {
+		strategyID = e.tradingState.Positions[positionIndex].StrategyID
+	}
+
+	e.rollDay()
+	e.tradingState.TotalPnL -= fee
+	e.tradingState.DayPnL -= fee
+	e.attribute(strategyID, 0, -fee)
+
+	// A fill in the direction of the position, or without one, adds exposure
+	if positionIndex == -1 || (e.tradingState.Positions[positionIndex].Quantity > 0) == (signedQty > 0) {
+		if positionIndex == -1 {
+			settings := e.settingsFor(e.runnerFor(strategyID))
+			stopDistance, targetDistance := e.fillExitDistances(symbol, settings, price, signedQty > 0)
+			stopLoss := price - stopDistance
+			takeProfit := price + targetDistance
+			if signedQty < 0 {
+				stopLoss = price + stopDistance
+				takeProfit = price - targetDistance
+			}
+
+			e.tradingState.Positions = append(e.tradingState.Positions, models.Position{
+				ID:            utils.GenerateTradeIDAt(symbol, at),
+				Symbol:        symbol,
+				Quantity:      signedQty,
+				AvgBuyPrice:   price,
+				CurrentValue:  quantity * price,
+				EntryTime:     at,
+				TargetPrice:   &takeProfit,
+				StopLossPrice: &stopLoss,
+				StrategyID:    strategyID,
+
+				InitialQuantity: quantity,
+				BestPrice:       price,
+				Entries:         1,
+			})
+			e.setPositionTimer(symbol, time.Duration(settings.MaxHoldTime)*time.Minute)
+
+			e.events.Publish(events.PositionOpened{
+				Header:   events.Header{At: e.clock.Now()},
+				Position: e.tradingState.Positions[len(e.tradingState.Positions)-1],
+			})
+		} else {
+			// Entries are counted when their order is sent, a fill may be one of several
+			settings := e.settingsFor(e.runnerFor(strategyID))
+			stopDistance, targetDistance := e.fillExitDistances(symbol, settings, price, signedQty > 0)
+			scaleInPosition(&e.tradingState.Positions[positionIndex], quantity, price, stopDistance, targetDistance)
+		}
+
+		e.recordTrade(models.Trade{
+			ID:         utils.GenerateTradeIDAt(symbol, at),
+			Symbol:     symbol,
+			Type:       side,
+			Price:      price,
+			Quantity:   quantity,
+			Timestamp:  at,
+			Signal:     "FILL",
+			StrategyID: strategyID,
+			Fee:        fee,
+		})
+		e.adjustAvailableBalance(-(quantity*price + fee))
+		e.attribute(strategyID, -(quantity*price + fee), 0)
+
+		e.logger.WithFields(map[string]interface{}{
+			"symbol":   symbol,
+			"side":     side,
+			"price":    price,
+			"quantity": quantity,
+			"fee":      fee,
+		}).Info("Applied fill from user data stream")
+		return
+	}
+
+	// A fill against the position realizes P&L on the closed part
+	position := e.tradingState.Positions[positionIndex]
+	held := math.Abs(position.Quantity)
+	closedQty := math.Min(quantity, held)
+	pnl := utils.CalculatePnL(position.AvgBuyPrice, price, closedQty, position.Quantity > 0)
+	holdTime := int(at.Sub(position.EntryTime).Minutes())
+	exitPrice := price
+
+	e.recordTrade(models.Trade{
+		ID:         utils.GenerateTradeIDAt(symbol+"_exit", at),
+		Symbol:     symbol,
+		Type:       "CLOSE",
+		Price:      price,
+		Quantity:   closedQty,
+		Timestamp:  at,
+		Signal:     "FILL",
+		Confidence: 100,
+		PnL:        &pnl,
+		ExitPrice:  &exitPrice,
+		HoldTime:   &holdTime,
+		StrategyID: strategyID,
+		Fee:        fee,
+	})
+	e.rollDay()
+	e.tradingState.TotalPnL += pnl
+	e.tradingState.DayPnL += pnl
+
+	if remaining := held - closedQty; remaining > held*1e-9 {
+		e.tradingState.Positions[positionIndex].Quantity = math.Copysign(remaining, position.Quantity)
+		e.tradingState.Positions[positionIndex].CurrentValue = remaining * price
+	} else {
+		e.tradingState.Positions = append(
+			e.tradingState.Positions[:positionIndex],
+			e.tradingState.Positions[positionIndex+1:]...)
+
+		e.timersMutex.Lock()
+		if timer, exists := e.positionTimers[symbol]; exists {
+			timer.Stop()
+			delete(e.positionTimers, symbol)
+		}
+		e.timersMutex.Unlock()
+
+		e.events.Publish(events.PositionClosed{
+			Header:   events.Header{At: e.clock.Now()},
+			Position: position,
+			Reason:   "FILL",
+		})
+	}
+	e.adjustAvailableBalance(closedQty*position.AvgBuyPrice + pnl - fee)
+	e.attribute(strategyID, closedQty*position.AvgBuyPrice+pnl-fee, pnl)
+
+	e.logger.WithFields(map[string]interface{}{
+		"symbol":     symbol,
+		"side":       side,
+		"quantity":   closedQty,
+		"exit_price": price,
+		"pnl":        pnl,
+		"fee":        fee,
+	}).Info("Applied closing fill from user data stream")
+
+	// The rest of a fill larger than the position opens one in the other direction, which
+	// for a long position is a short and needs short selling
+	if excess := quantity - closedQty; excess > held*1e-9 {
+		if position.Quantity > 0 && !e.config.Trading.ShortSelling {
+			e.logger.WithFields(map[string]interface{}{
+				"symbol":   symbol,
+				"side":     side,
+				"quantity": excess,
+			}).Warn("Ignored fill beyond the closed position, short selling is disabled")
+			return
+		}
+		e.applyFill(symbol, side, excess, price, 0, at, strategyID)
+	}
+}
+
+// handleAccountUpdate takes the quote asset balance reported by the exchange as authoritative.
+// The quote balance is the sum of the spot and the cross margin account, each reported on
+// its own stream.
+func (e *Engine) handleAccountUpdate(update models.AccountUpdate) {
+	for _, balance := range update.Balances {
+		if balance.Asset != e.config.Trading.QuoteAsset {
+			continue
+		}
+
+		e.stateMutex.Lock()
+		spotQuote := models.Balance{
+			Asset:  balance.Asset,
+			Free:   e.quoteBalance.Free - e.marginQuote.Free,
+			Locked: e.quoteBalance.Locked - e.marginQuote.Locked,
+		}
+		if update.Margin {
+			e.marginQuote = balance
+		} else {
+			spotQuote = balance
+		}
+		e.quoteBalance = models.Balance{
+			Asset:  balance.Asset,
+			Free:   spotQuote.Free + e.marginQuote.Free,
+			Locked: spotQuote.Locked + e.marginQuote.Locked,
+		}
+		e.tradingState.TradingBalance = e.quoteBalance.Free + e.quoteBalance.Locked
+		e.tradingState.AvailableBalance = e.quoteBalance.Free - e.shortCollateral()
+		e.stateMutex.Unlock()
+
+		e.logger.WithFields(map[string]interface{}{
+			"asset":  balance.Asset,
+			"free":   balance.Free,
+			"locked": balance.Locked,
+			"margin": update.Margin,
+		}).Debug("Updated balance from user data stream")
+	}
+}
+
+// newClientOrderID returns the client order ID an engine order is sent and tracked under,
+// unique across restarts through the prefix taken from the engine start time
+func (e *Engine) newClientOrderID() string {
+	return fmt.Sprintf("%s-%d", e.orderPrefix, atomic.AddInt64(&e.orderSeq, 1))
+}
+
+// submitOrder assigns a client order ID to an order about to be sent and tracks it with no
+// fills, so fills reported before the order request returns are applied
+func (e *Engine) submitOrder(request *models.OrderRequest, strategyID string) {
+	request.ClientOrderID = e.newClientOrderID()
+
+	e.stateMutex.Lock()
+	e.trackOrder(request.ClientOrderID, 0, strategyID)
+	e.stateMutex.Unlock()
+}
+
+// orderFailed settles the tracking of an order whose request failed. An order that may have
+// reached the exchange stays tracked, so fills reported for it on the user data stream are
+// applied, until unconfirmedOrderTTL passes without any.
+func (e *Engine) orderFailed(request *models.OrderRequest, err error, adding bool) {
+	if e.userData == nil {
+		return
+	}
+
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	id := request.ClientOrderID
+	if !orderOutcomeUnknown(err) {
+		e.untrackOrder(id)
+		return
+	}
+	if adding {
+		e.countEntry(request.Symbol)
+	}
+
+	e.clock.AfterFunc(unconfirmedOrderTTL, func() {
+		e.stateMutex.Lock()
+		defer e.stateMutex.Unlock()
+		if filled, tracked := e.orderFills[id]; tracked && filled == 0 {
+			e.untrackOrder(id)
+		}
+	})
+
+	e.logger.WithFields(map[string]interface{}{
+		"symbol":          request.Symbol,
+		"side":            request.Side,
+		"client_order_id": id,
+	}).Warn("Order outcome unknown, following its fills on the user data stream")
+}
+
+// orderOutcomeUnknown reports whether a failed order request may still have reached the exchange
+func orderOutcomeUnknown(err error) bool {
+	switch binance.Classify(err) {
+	case binance.ErrorClassNetwork, binance.ErrorClassServer:
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// trackOrder records the filled quantity of an engine order already reflected in the trading
+// state, so its later updates on the user data stream only apply new fills, and the strategy
+// instance the order was sent for. The caller must hold stateMutex.
+func (e *Engine) trackOrder(clientOrderID string, executedQty float64, strategyID string) {
+	if e.userData != nil {
+		e.orderFills[clientOrderID] = executedQty
+		e.orderStrategies[clientOrderID] = strategyID
+	}
+}
+
+// untrackOrder stops following the fills of an engine order, the caller must hold stateMutex
+func (e *Engine) untrackOrder(clientOrderID string) {
+	delete(e.orderFills, clientOrderID)
+	delete(e.orderStrategies, clientOrderID)
+}
+
+// adjustAvailableBalance applies a change to the available balance. With a user data stream the
+// exchange reports the balance, so it is recomputed from the last report instead. The caller
+// must hold stateMutex.
+func (e *Engine) adjustAvailableBalance(delta float64) {
+	if e.userData == nil {
+		e.tradingState.AvailableBalance += delta
+		return
+	}
+	e.tradingState.AvailableBalance = e.quoteBalance.Free - e.shortCollateral()
+}
+
+// shortCollateral returns the notional of open short positions held back as collateral,
+// the caller must hold stateMutex
+func (e *Engine) shortCollateral() float64 {
+	var collateral float64
+	for _, position := range e.tradingState.Positions {
+		if position.Quantity < 0 {
+			collateral += -position.Quantity * position.AvgBuyPrice
+		}
+	}
+	return collateral
+}
@@ -0,0 +1,242 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/clock"
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+// newTestEngine creates an engine routing orders to Binance, followed on the user data
+// stream. Nothing is sent as long as the engine is not started.
+func newTestEngine(t *testing.T, shortSelling bool) *Engine {
+	t.Helper()
+
+	log, err := logger.NewLogger("engine-test", logger.ERROR, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	cfg := &config.Config{
+		Binance: config.BinanceConfig{
+			APIBaseURL:       "http://127.0.0.1:0",
+			RateLimit:        6000,
+			RetryAttempts:    1,
+			BreakerThreshold: 5,
+			BreakerCooldown:  time.Minute,
+		},
+		Trading: config.TradingConfig{
+			Mode:            config.TradingModeTestnet,
+			QuoteAsset:      "USDT",
+			MaxPositions:    5,
+			PositionTimeout: 30,
+			PriceBufferSize: 1000,
+			KlineInterval:   "1m",
+			Strategy:        "signal",
+			ShortSelling:    shortSelling,
+		},
+	}
+	cfg.Trading.TechnicalPeriods.RSI = 14
+	cfg.Trading.TechnicalPeriods.EMA9 = 9
+	cfg.Trading.TechnicalPeriods.EMA21 = 21
+	cfg.Trading.TechnicalPeriods.EMA50 = 50
+	cfg.Trading.TechnicalPeriods.EMA200 = 200
+
+	e, err := NewEngineWithClock(cfg, log, clock.NewSimulated(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	return e
+}
+
+// filledReport returns the execution report of an order filled in one trade
+func filledReport(clientOrderID, side string, quantity, price float64) models.ExecutionReport {
+	return models.ExecutionReport{
+		Order: models.Order{
+			OrderID:       42,
+			ClientOrderID: clientOrderID,
+			Symbol:        "BTCUSDT",
+			Side:          side,
+			Type:          "MARKET",
+			Status:        "FILLED",
+			Quantity:      quantity,
+			ExecutedQty:   quantity,
+			AvgPrice:      price,
+		},
+		ExecutionType: "TRADE",
+		LastQty:       quantity,
+		LastPrice:     price,
+	}
+}
+
+func TestFillOfTimedOutOrderIsApplied(t *testing.T) {
+	e := newTestEngine(t, false)
+
+	request := &models.OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Type: "MARKET", Quantity: 0.5}
+	if !e.beginOrder(request.Symbol) {
+		t.Fatal("order already in progress")
+	}
+	e.submitOrder(request, defaultStrategyID)
+	if request.ClientOrderID == "" {
+		t.Fatal("order sent without a client order ID")
+	}
+
+	// The fill is reported while the request is still waiting for its response
+	e.handleExecutionReport(filledReport(request.ClientOrderID, "BUY", 0.5, 30000))
+	e.orderFailed(request, &binance.NetworkError{Err: errors.New("i/o timeout")}, false)
+	e.endOrder(request.Symbol)
+
+	position, open := e.positionFor("BTCUSDT")
+	if !open || position.Quantity != 0.5 || position.AvgBuyPrice != 30000 {
+		t.Fatalf("position = %+v, open %v, want 0.5 at 30000", position, open)
+	}
+	if _, tracked := e.orderFills[request.ClientOrderID]; tracked {
+		t.Error("filled order is still tracked")
+	}
+}
+
+func TestRejectedOrderIsNotTracked(t *testing.T) {
+	e := newTestEngine(t, false)
+
+	request := &models.OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Type: "MARKET", Quantity: 0.5}
+	e.submitOrder(request, defaultStrategyID)
+	e.orderFailed(request, &binance.APIError{StatusCode: 400, Code: -2010, Class: binance.ErrorClassRequest}, false)
+
+	if _, tracked := e.orderFills[request.ClientOrderID]; tracked {
+		t.Fatal("rejected order is still tracked")
+	}
+
+	// A report for the ID opens nothing, the engine does not own such an order
+	e.handleExecutionReport(filledReport(request.ClientOrderID, "BUY", 0.5, 30000))
+	if _, open := e.positionFor("BTCUSDT"); open {
+		t.Error("report of an untracked order opened a position")
+	}
+}
+
+func TestUnconfirmedOrderExpires(t *testing.T) {
+	e := newTestEngine(t, false)
+	sim := e.clock.(*clock.Simulated)
+
+	request := &models.OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Type: "MARKET", Quantity: 0.5}
+	e.submitOrder(request, defaultStrategyID)
+	e.orderFailed(request, &binance.NetworkError{Err: errors.New("connection reset")}, false)
+
+	sim.Advance(unconfirmedOrderTTL - time.Second)
+	if _, tracked := e.orderFills[request.ClientOrderID]; !tracked {
+		t.Fatal("order with an unknown outcome untracked before its TTL")
+	}
+
+	sim.Advance(time.Second)
+	if _, tracked := e.orderFills[request.ClientOrderID]; tracked {
+		t.Error("order without fills still tracked after its TTL")
+	}
+}
+
+func TestExcessClosingFill(t *testing.T) {
+	tests := []struct {
+		name         string
+		shortSelling bool
+		wantOpen     bool
+		wantQuantity float64
+	}{
+		{name: "ignored without short selling", shortSelling: false},
+		{name: "opens a short with short selling", shortSelling: true, wantOpen: true, wantQuantity: -0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t, tt.shortSelling)
+			at := e.clock.Now()
+
+			e.stateMutex.Lock()
+			e.applyFill("BTCUSDT", "BUY", 0.5, 30000, 0, at, defaultStrategyID)
+			e.applyFill("BTCUSDT", "SELL", 0.7, 31000, 0, at, defaultStrategyID)
+			e.stateMutex.Unlock()
+
+			position, open := e.positionFor("BTCUSDT")
+			if open != tt.wantOpen {
+				t.Fatalf("position open = %v, want %v", open, tt.wantOpen)
+			}
+			if open && math.Abs(position.Quantity-tt.wantQuantity) > 1e-9 {
+				t.Errorf("position quantity = %v, want %v", position.Quantity, tt.wantQuantity)
+			}
+		})
+	}
+}
+
+func TestFillOpensPositionWithStopMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		side           string
+		atr            float64
+		wantStopLoss   float64
+		wantTakeProfit float64
+	}{
+		{name: "percentages without an analysis", side: "BUY", wantStopLoss: 29400, wantTakeProfit: 31200},
+		{name: "ATR multiples for a long", side: "BUY", atr: 100, wantStopLoss: 29800, wantTakeProfit: 30300},
+		{name: "ATR multiples for a short", side: "SELL", atr: 100, wantStopLoss: 30200, wantTakeProfit: 29700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t, true)
+
+			e.stateMutex.Lock()
+			e.tradingState.Settings.StopMode = models.StopModeATR
+			e.tradingState.Settings.StopLossPercent = 2
+			e.tradingState.Settings.TakeProfitPercent = 4
+			e.tradingState.Settings.ATRStopMultiple = 2
+			e.tradingState.Settings.ATRTargetMultiple = 3
+			for i := range e.tradingState.Watchlist {
+				if tt.atr > 0 && e.tradingState.Watchlist[i].Symbol == "BTCUSDT" {
+					e.tradingState.Watchlist[i].Technical = &models.TechnicalAnalysis{ATR: tt.atr}
+				}
+			}
+			e.applyFill("BTCUSDT", tt.side, 0.5, 30000, 0, e.clock.Now(), defaultStrategyID)
+			e.stateMutex.Unlock()
+
+			position, open := e.positionFor("BTCUSDT")
+			if !open {
+				t.Fatal("fill opened no position")
+			}
+			if *position.StopLossPrice != tt.wantStopLoss || *position.TargetPrice != tt.wantTakeProfit {
+				t.Errorf("stop loss %v, take profit %v, want %v and %v",
+					*position.StopLossPrice, *position.TargetPrice, tt.wantStopLoss, tt.wantTakeProfit)
+			}
+		})
+	}
+}
+
+func TestFillAddingToPositionUsesStopMode(t *testing.T) {
+	e := newTestEngine(t, false)
+
+	e.stateMutex.Lock()
+	e.tradingState.Settings.StopMode = models.StopModeATR
+	e.tradingState.Settings.StopLossPercent = 2
+	e.tradingState.Settings.TakeProfitPercent = 4
+	e.tradingState.Settings.ATRStopMultiple = 2
+	e.tradingState.Settings.ATRTargetMultiple = 3
+	for i := range e.tradingState.Watchlist {
+		if e.tradingState.Watchlist[i].Symbol == "BTCUSDT" {
+			e.tradingState.Watchlist[i].Technical = &models.TechnicalAnalysis{ATR: 100}
+		}
+	}
+	e.applyFill("BTCUSDT", "BUY", 0.5, 30000, 0, e.clock.Now(), defaultStrategyID)
+	e.applyFill("BTCUSDT", "BUY", 0.5, 31000, 0, e.clock.Now(), defaultStrategyID)
+	e.stateMutex.Unlock()
+
+	// The exits move to the ATR multiples away from the average entry of 30500
+	position, open := e.positionFor("BTCUSDT")
+	if !open || position.Quantity != 1 {
+		t.Fatalf("position = %+v, open %v, want 1 held", position, open)
+	}
+	if *position.StopLossPrice != 30300 || *position.TargetPrice != 30800 {
+		t.Errorf("stop loss %v, take profit %v, want 30300 and 30800", *position.StopLossPrice, *position.TargetPrice)
+	}
+}
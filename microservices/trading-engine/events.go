@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"trading-engine/events"
+)
+
+// eventBufferSize is the number of engine events queued for each subscriber
+const eventBufferSize = 256
+
+// eventMetrics counts the engine events by type
+type eventMetrics struct {
+	counts map[events.Type]int64
+	last   map[events.Type]time.Time
+	mu     sync.RWMutex
+}
+
+// newEventMetrics creates empty event counters
+func newEventMetrics() *eventMetrics {
+	return &eventMetrics{
+		counts: make(map[events.Type]int64),
+		last:   make(map[events.Type]time.Time),
+	}
+}
+
+// record counts an event
+func (m *eventMetrics) record(event events.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[event.Type()]++
+	m.last[event.Type()] = event.Timestamp()
+}
+
+// snapshot returns the count and last time of each event type
+func (m *eventMetrics) snapshot() map[events.Type]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := make(map[events.Type]interface{}, len(m.counts))
+	for eventType, count := range m.counts {
+		snapshot[eventType] = map[string]interface{}{
+			"count": count,
+			"last":  m.last[eventType],
+		}
+	}
+	return snapshot
+}
+
+// startEventSubscribers connects persistence, WebSocket broadcast, notifications and metrics
+// to the engine events
+func (app *Application) startEventSubscribers(ctx context.Context) {
+	bus := app.engine.Events()
+
+	subscribe := func(handle func(events.Event), types ...events.Type) {
+		ch := make(chan events.Event, eventBufferSize)
+		bus.Subscribe(ch, types...)
+		go app.consumeEvents(ctx, ch, handle)
+	}
+
+	if app.database != nil {
+		// Persistence must see every event, it subscribes without a bound on its backlog
+		ch := make(chan events.Event, eventBufferSize)
+		bus.SubscribeDurable(ctx, ch,
+			events.TypeOrderFilled, events.TypePositionOpened, events.TypePositionClosed, events.TypeSettingsChanged)
+		go app.persistState(ctx, ch)
+	}
+	subscribe(app.broadcastEvent)
+
+	// Clients render the trading state, sent again whenever prices or positions change
+	snapshots := make(chan events.Event, eventBufferSize)
+	bus.Subscribe(snapshots, events.TypeCandleClosed, events.TypeOrderFilled,
+		events.TypePositionOpened, events.TypePositionClosed, events.TypeSettingsChanged)
+	go app.broadcastSnapshots(ctx, snapshots)
+	subscribe(app.notifyEvent, events.TypePositionOpened, events.TypePositionClosed, events.TypeRiskLimitHit)
+	subscribe(app.metrics.record)
+}
+
+// consumeEvents handles the events of a subscription until the application stops
+func (app *Application) consumeEvents(ctx context.Context, ch <-chan events.Event, handle func(events.Event)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-ch:
+			handle(event)
+		}
+	}
+}
+
+// persistEvent saves fills, positions and settings to the database
+func (app *Application) persistEvent(event events.Event) {
+	switch e := event.(type) {
+	case events.OrderFilled:
+		if err := app.database.SaveTrade(&e.Trade); err != nil {
+			app.logger.Error("Failed to save trade to database: %v", err)
+		}
+	case events.PositionOpened:
+		if err := app.database.SavePosition(&e.Position); err != nil {
+			app.logger.Error("Failed to save position to database: %v", err)
+		}
+	case events.PositionClosed:
+		if err := app.database.DeactivatePosition(e.Position.ID); err != nil {
+			app.logger.Error("Failed to deactivate position in database: %v", err)
+		}
+	case events.SettingsChanged:
+		if err := app.database.SaveTradingSettings(&e.Settings); err != nil {
+			app.logger.Error("Failed to save settings to database: %v", err)
+		}
+	}
+}
+
+// broadcastEvent pushes an event to the WebSocket clients as it happens
+func (app *Application) broadcastEvent(event events.Event) {
+	message := map[string]interface{}{
+		"type":      "event",
+		"event":     event.Type(),
+		"data":      event,
+		"timestamp": event.Timestamp(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		app.logger.Error("Failed to encode %s event: %v", event.Type(), err)
+		return
+	}
+
+	select {
+	case app.broadcast <- data:
+	default:
+		// Channel is full, skip this event
+	}
+}
+
+// broadcastSnapshots sends the trading state to WebSocket clients after the events that change
+// it. Events arriving together, such as the candles of several symbols closing, share a snapshot.
+func (app *Application) broadcastSnapshots(ctx context.Context, ch <-chan events.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-ch:
+			at := event.Timestamp()
+		drain:
+			for {
+				select {
+				case event := <-ch:
+					at = event.Timestamp()
+				default:
+					break drain
+				}
+			}
+			app.broadcastState(at)
+		}
+	}
+}
+
+// broadcastState sends the trading state to WebSocket clients
+func (app *Application) broadcastState(at time.Time) {
+	message := map[string]interface{}{
+		"type":      "update",
+		"data":      app.engine.GetTradingState(),
+		"timestamp": at,
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		app.logger.Error("Failed to encode trading state: %v", err)
+		return
+	}
+
+	select {
+	case app.broadcast <- data:
+	default:
+		// Channel is full, the next snapshot catches up
+	}
+}
+
+// notifyEvent reports the events an operator should hear about
+func (app *Application) notifyEvent(event events.Event) {
+	switch e := event.(type) {
+	case events.PositionOpened:
+		app.logger.WithFields(map[string]interface{}{
+			"symbol":   e.Position.Symbol,
+			"strategy": e.Position.StrategyID,
+			"quantity": e.Position.Quantity,
+			"price":    e.Position.AvgBuyPrice,
+		}).Info("Notification: position opened")
+	case events.PositionClosed:
+		app.logger.WithFields(map[string]interface{}{
+			"symbol":   e.Position.Symbol,
+			"strategy": e.Position.StrategyID,
+			"reason":   e.Reason,
+		}).Info("Notification: position closed")
+	case events.RiskLimitHit:
+		app.logger.WithFields(map[string]interface{}{
+			"strategy":  e.StrategyID,
+			"symbol":    e.Symbol,
+			"limit":     e.Limit,
+			"value":     e.Value,
+			"threshold": e.Threshold,
+		}).Warn("Notification: risk limit hit")
+	}
+}
+
+func (app *Application) getEventMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeJSONResponse(w, app.metrics.snapshot())
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"trading-engine/logger"
+)
+
+// Bus delivers engine events to subscribers in process
+type Bus struct {
+	logger      *logger.Logger
+	subscribers []subscriber
+	mu          sync.RWMutex
+}
+
+// subscriber is a channel with the event types it receives, every type when empty. Events
+// for a durable subscriber go through its queue.
+type subscriber struct {
+	ch    chan Event
+	types map[Type]bool
+	queue *queue
+}
+
+// queue holds the events of a durable subscriber until its channel takes them
+type queue struct {
+	events []Event
+	ready  chan struct{}
+	mu     sync.Mutex
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus(log *logger.Logger) *Bus {
+	return &Bus{logger: log}
+}
+
+// Subscribe adds a channel receiving the events of the given types, or of every type when
+// none are given
+func (b *Bus) Subscribe(ch chan Event, types ...Type) {
+	b.subscribe(subscriber{ch: ch, types: typeFilter(types)})
+}
+
+// SubscribeDurable adds a channel receiving every event of the given types, or of every type
+// when none are given, however far its consumer falls behind. Events wait in an unbounded
+// queue until the channel takes them, in the order they were published. The subscriber is
+// removed and its queue dropped once the context is done.
+func (b *Bus) SubscribeDurable(ctx context.Context, ch chan Event, types ...Type) {
+	q := &queue{ready: make(chan struct{}, 1)}
+	b.subscribe(subscriber{ch: ch, types: typeFilter(types), queue: q})
+	go func() {
+		q.forward(ctx, ch)
+		b.unsubscribe(q)
+	}()
+}
+
+// subscribe adds a subscriber
+func (b *Bus) subscribe(sub subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, sub)
+}
+
+// unsubscribe removes the durable subscriber with a queue
+func (b *Bus) unsubscribe(q *queue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, sub := range b.subscribers {
+		if sub.queue == q {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// typeFilter returns the set of the given event types
+func typeFilter(types []Type) map[Type]bool {
+	filter := make(map[Type]bool, len(types))
+	for _, eventType := range types {
+		filter[eventType] = true
+	}
+	return filter
+}
+
+// Publish sends an event to its subscribers without blocking. A subscriber whose channel is
+// full misses it, unless it is durable.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if len(sub.types) > 0 && !sub.types[event.Type()] {
+			continue
+		}
+		if sub.queue != nil {
+			sub.queue.push(event)
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			b.logger.Warn("Event channel full, dropping %s event", event.Type())
+		}
+	}
+}
+
+// push appends an event to the queue
+func (q *queue) push(event Event) {
+	q.mu.Lock()
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// forward moves the queued events to the channel, blocking while it is full, until the context
+// is done
+func (q *queue) forward(ctx context.Context, ch chan Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.ready:
+		}
+
+		for {
+			q.mu.Lock()
+			if len(q.events) == 0 {
+				q.mu.Unlock()
+				break
+			}
+			event := q.events[0]
+			q.events[0] = nil
+			q.events = q.events[1:]
+			q.mu.Unlock()
+
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"trading-engine/logger"
+)
+
+// newTestBus creates a bus logging to a temporary directory
+func newTestBus(t *testing.T) *Bus {
+	t.Helper()
+
+	log, err := logger.NewLogger("events-test", logger.ERROR, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	return NewBus(log)
+}
+
+// signal returns a signal event told apart by its price
+func signal(price float64) SignalGenerated {
+	return SignalGenerated{Symbol: "BTCUSDT", Signal: "BUY", Price: price}
+}
+
+func TestPublishDropsEventsOfFullChannel(t *testing.T) {
+	bus := newTestBus(t)
+	ch := make(chan Event, 1)
+	bus.Subscribe(ch)
+
+	bus.Publish(signal(1))
+	bus.Publish(signal(2))
+
+	if event := <-ch; event.(SignalGenerated).Price != 1 {
+		t.Errorf("received %+v, want the first event", event)
+	}
+	select {
+	case event := <-ch:
+		t.Errorf("received %+v, want the second event dropped", event)
+	default:
+	}
+}
+
+func TestDurableSubscriberReceivesEveryEvent(t *testing.T) {
+	bus := newTestBus(t)
+	ch := make(chan Event, 1)
+	bus.SubscribeDurable(context.Background(), ch, TypeSignalGenerated)
+
+	// Publishing never waits for the consumer
+	const count = 100
+	for i := 0; i < count; i++ {
+		bus.Publish(signal(float64(i)))
+	}
+
+	for i := 0; i < count; i++ {
+		select {
+		case event := <-ch:
+			if price := event.(SignalGenerated).Price; price != float64(i) {
+				t.Fatalf("event %d has price %v, want events in publish order", i, price)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+}
+
+func TestDurableSubscriberEndsWithContext(t *testing.T) {
+	bus := newTestBus(t)
+	ch := make(chan Event, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.SubscribeDurable(ctx, ch)
+
+	// The forwarder may be blocked on the full channel when the context is done
+	bus.Publish(signal(1))
+	bus.Publish(signal(2))
+	bus.Publish(signal(3))
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bus.mu.RLock()
+		subscribers := len(bus.subscribers)
+		bus.mu.RUnlock()
+		if subscribers == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("subscriber still registered after its context was done")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case <-ch:
+	default:
+	}
+	bus.Publish(signal(4))
+	select {
+	case event := <-ch:
+		t.Errorf("received %+v after the context was done", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
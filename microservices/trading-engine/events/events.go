@@ -0,0 +1,130 @@
+package events
+
+import (
+	"time"
+
+	"trading-engine/models"
+)
+
+// Type names a kind of engine event
+type Type string
+
+const (
+	TypeCandleClosed    Type = "CANDLE_CLOSED"
+	TypeSignalGenerated Type = "SIGNAL_GENERATED"
+	TypeOrderSubmitted  Type = "ORDER_SUBMITTED"
+	TypeOrderFilled     Type = "ORDER_FILLED"
+	TypePositionOpened  Type = "POSITION_OPENED"
+	TypePositionClosed  Type = "POSITION_CLOSED"
+	TypeSettingsChanged Type = "SETTINGS_CHANGED"
+	TypeRiskLimitHit    Type = "RISK_LIMIT_HIT"
+)
+
+// Limits reported by RiskLimitHit
+const (
+	LimitDailyLoss    = "DAILY_LOSS"
+	LimitMaxPositions = "MAX_POSITIONS"
+	LimitBalance      = "BALANCE"
+)
+
+// Event is something that happened in the engine
+type Event interface {
+	Type() Type
+	Timestamp() time.Time
+}
+
+// Header holds the engine time an event happened at
+type Header struct {
+	At time.Time `json:"timestamp"`
+}
+
+// Timestamp returns the time the event happened at
+func (h Header) Timestamp() time.Time {
+	return h.At
+}
+
+// CandleClosed is published when a candle of any timeframe closes
+type CandleClosed struct {
+	Header
+	Symbol   string        `json:"symbol"`
+	Interval string        `json:"interval"`
+	Candle   models.Candle `json:"candle"`
+}
+
+// Type returns TypeCandleClosed
+func (CandleClosed) Type() Type { return TypeCandleClosed }
+
+// SignalGenerated is published when the signal of a symbol on the signal timeframe changes
+type SignalGenerated struct {
+	Header
+	Symbol     string  `json:"symbol"`
+	Interval   string  `json:"interval"`
+	Signal     string  `json:"signal"`
+	Previous   string  `json:"previous,omitempty"`
+	Confidence int     `json:"confidence"`
+	Price      float64 `json:"price"`
+}
+
+// Type returns TypeSignalGenerated
+func (SignalGenerated) Type() Type { return TypeSignalGenerated }
+
+// OrderSubmitted is published before an order is sent to the execution venue
+type OrderSubmitted struct {
+	Header
+	StrategyID string              `json:"strategyId,omitempty"`
+	Venue      string              `json:"venue"`
+	Request    models.OrderRequest `json:"request"`
+}
+
+// Type returns TypeOrderSubmitted
+func (OrderSubmitted) Type() Type { return TypeOrderSubmitted }
+
+// OrderFilled is published with the trade recorded for each fill
+type OrderFilled struct {
+	Header
+	Trade models.Trade `json:"trade"`
+}
+
+// Type returns TypeOrderFilled
+func (OrderFilled) Type() Type { return TypeOrderFilled }
+
+// PositionOpened is published when a position is opened
+type PositionOpened struct {
+	Header
+	Position models.Position `json:"position"`
+}
+
+// Type returns TypePositionOpened
+func (PositionOpened) Type() Type { return TypePositionOpened }
+
+// PositionClosed is published when the last of a position is closed
+type PositionClosed struct {
+	Header
+	Position models.Position `json:"position"` // As it was before the closing fill
+	Reason   string          `json:"reason"`
+}
+
+// Type returns TypePositionClosed
+func (PositionClosed) Type() Type { return TypePositionClosed }
+
+// SettingsChanged is published when the trading settings are updated
+type SettingsChanged struct {
+	Header
+	Settings models.TradingSettings `json:"settings"`
+}
+
+// Type returns TypeSettingsChanged
+func (SettingsChanged) Type() Type { return TypeSettingsChanged }
+
+// RiskLimitHit is published when a risk limit starts blocking the entries of a strategy
+type RiskLimitHit struct {
+	Header
+	StrategyID string  `json:"strategyId"`
+	Symbol     string  `json:"symbol"`
+	Limit      string  `json:"limit"`
+	Value      float64 `json:"value"`
+	Threshold  float64 `json:"threshold"`
+}
+
+// Type returns TypeRiskLimitHit
+func (RiskLimitHit) Type() Type { return TypeRiskLimitHit }
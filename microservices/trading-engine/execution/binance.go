@@ -0,0 +1,135 @@
+package execution
+
+import (
+	"context"
+	"sync"
+
+	"trading-engine/binance"
+	"trading-engine/models"
+)
+
+// BinanceExecutor routes orders to Binance spot (testnet or live), and orders with a side
+// effect to the cross margin account
+type BinanceExecutor struct {
+	client       *binance.Client
+	name         string
+	margin       bool
+	marginOrders map[int64]bool // Resting orders placed on the cross margin account
+	mu           sync.Mutex
+}
+
+// NewBinanceExecutor creates a new Binance executor. With margin set, balances and open
+// orders of the cross margin account are included with those of the spot account.
+func NewBinanceExecutor(client *binance.Client, name string, margin bool) *BinanceExecutor {
+	return &BinanceExecutor{
+		client:       client,
+		name:         name,
+		margin:       margin,
+		marginOrders: make(map[int64]bool),
+	}
+}
+
+// Name returns the venue name
+func (b *BinanceExecutor) Name() string {
+	return b.name
+}
+
+// PlaceOrder submits an order to Binance
+func (b *BinanceExecutor) PlaceOrder(ctx context.Context, order *models.OrderRequest) (*models.Order, error) {
+	result, err := b.client.PlaceOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remember which account a resting order is on, so it is cancelled there
+	if order.SideEffectType != "" && !isFinalStatus(result.Status) {
+		b.mu.Lock()
+		b.marginOrders[result.OrderID] = true
+		b.mu.Unlock()
+	}
+	return result, nil
+}
+
+// CancelOrder cancels an order on the Binance account it was placed on
+func (b *BinanceExecutor) CancelOrder(ctx context.Context, symbol string, orderID int64) (*models.Order, error) {
+	b.mu.Lock()
+	margin := b.marginOrders[orderID]
+	b.mu.Unlock()
+
+	order, err := b.client.CancelOrder(ctx, symbol, orderID, margin)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	delete(b.marginOrders, orderID)
+	b.mu.Unlock()
+	return order, nil
+}
+
+// GetBalances returns the account balances held on Binance, summed over the spot and the
+// cross margin account
+func (b *BinanceExecutor) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	balances, err := b.client.GetAccountBalances(ctx)
+	if err != nil || !b.margin {
+		return balances, err
+	}
+
+	marginBalances, err := b.client.GetMarginBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mergeBalances(balances, marginBalances), nil
+}
+
+// GetOpenOrders returns open orders on Binance, including those of the cross margin account
+func (b *BinanceExecutor) GetOpenOrders(ctx context.Context, symbol string) ([]models.Order, error) {
+	orders, err := b.client.GetOpenOrders(ctx, symbol, false)
+	if err != nil || !b.margin {
+		return orders, err
+	}
+
+	marginOrders, err := b.client.GetOpenOrders(ctx, symbol, true)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	for _, order := range marginOrders {
+		b.marginOrders[order.OrderID] = true
+	}
+	b.mu.Unlock()
+
+	return append(orders, marginOrders...), nil
+}
+
+// isFinalStatus reports whether an order status can no longer change
+func isFinalStatus(status string) bool {
+	switch status {
+	case "FILLED", "CANCELED", "REJECTED", "EXPIRED", "EXPIRED_IN_MATCH":
+		return true
+	}
+	return false
+}
+
+// mergeBalances sums the balances of two accounts per asset
+func mergeBalances(a, b []models.Balance) []models.Balance {
+	merged := make([]models.Balance, len(a), len(a)+len(b))
+	copy(merged, a)
+
+	for _, balance := range b {
+		found := false
+		for i := range merged {
+			if merged[i].Asset == balance.Asset {
+				merged[i].Free += balance.Free
+				merged[i].Locked += balance.Locked
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, balance)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,92 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"trading-engine/binance"
+	"trading-engine/clock"
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+// Executor is a venue that orders are routed to
+type Executor interface {
+	// Name returns the venue name used in logs and API responses
+	Name() string
+	PlaceOrder(ctx context.Context, order *models.OrderRequest) (*models.Order, error)
+	CancelOrder(ctx context.Context, symbol string, orderID int64) (*models.Order, error)
+	GetBalances(ctx context.Context) ([]models.Balance, error)
+	GetOpenOrders(ctx context.Context, symbol string) ([]models.Order, error)
+}
+
+// BalanceRestorer is a venue that keeps the account balances itself, restored after a restart
+type BalanceRestorer interface {
+	RestoreBalances(balances []models.Balance)
+}
+
+// OrderNormalizer is a venue that adjusts orders to the exchange trading rules before placing
+// them. Normalize returns the order as it would be placed, or an error wrapping
+// ErrFilterViolation when it would be rejected.
+type OrderNormalizer interface {
+	Normalize(ctx context.Context, order *models.OrderRequest) (*models.OrderRequest, error)
+}
+
+// PriceSource provides the latest market data used to simulate fills
+type PriceSource interface {
+	LatestCandle(symbol string) (models.Candle, bool)
+}
+
+// NewExecutor creates the executor selected by the trading mode
+func NewExecutor(cfg *config.Config, client *binance.Client, prices PriceSource, clk clock.Clock, log *logger.Logger) (Executor, error) {
+	var executor Executor
+	switch cfg.Trading.Mode {
+	case config.TradingModePaper:
+		executor = NewPaperExecutor(&cfg.Trading.Paper, cfg.Trading.QuoteAsset, prices, clk, log)
+	case config.TradingModeTestnet, config.TradingModeLive:
+		executor = NewBinanceExecutor(client, cfg.Trading.Mode, cfg.Trading.ShortSelling)
+	default:
+		return nil, fmt.Errorf("unsupported trading mode: %s", cfg.Trading.Mode)
+	}
+
+	// Orders are shaped to the exchange trading rules before any venue sees them, so paper
+	// fills match what Binance would accept
+	if cfg.Trading.ExchangeFilters {
+		executor = NewFilteredExecutor(executor, client, prices, log)
+	}
+	return executor, nil
+}
+
+// FindBalance returns the balance of an asset, or a zero balance if none is held
+func FindBalance(balances []models.Balance, asset string) models.Balance {
+	for _, balance := range balances {
+		if balance.Asset == asset {
+			return balance
+		}
+	}
+	return models.Balance{Asset: asset}
+}
+
+// QuoteFee returns the commission of an order valued in the quote asset
+func QuoteFee(order *models.Order, quoteAsset string) float64 {
+	if order.Commission == 0 {
+		return 0
+	}
+
+	switch order.CommissionAsset {
+	case quoteAsset:
+		return order.Commission
+	case BaseAsset(order.Symbol, quoteAsset):
+		return order.Commission * order.AvgPrice
+	}
+
+	// Fees paid in a third asset (e.g. BNB) cannot be valued without another price
+	return 0
+}
+
+// BaseAsset strips the quote asset suffix from a symbol
+func BaseAsset(symbol, quoteAsset string) string {
+	return strings.TrimSuffix(symbol, quoteAsset)
+}
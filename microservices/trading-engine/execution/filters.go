@@ -0,0 +1,146 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trading-engine/logger"
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// ErrFilterViolation reports an order the exchange would reject under the symbol's trading rules
+var ErrFilterViolation = errors.New("order violates exchange filters")
+
+// FilterSource provides the trading rules of a symbol
+type FilterSource interface {
+	SymbolFilters(ctx context.Context, symbol string) (models.SymbolFilters, error)
+}
+
+// FilteredExecutor rounds orders to the symbol's tick and step size and rejects those
+// outside its quantity, price and notional limits before they reach the wrapped venue
+type FilteredExecutor struct {
+	Executor
+	filters FilterSource
+	prices  PriceSource
+	logger  *logger.Logger
+}
+
+// NewFilteredExecutor wraps an executor with exchange filter normalization.
+// Market orders are valued at the latest candle close for the notional checks.
+func NewFilteredExecutor(executor Executor, filters FilterSource, prices PriceSource, log *logger.Logger) *FilteredExecutor {
+	return &FilteredExecutor{
+		Executor: executor,
+		filters:  filters,
+		prices:   prices,
+		logger:   log,
+	}
+}
+
+// RestoreBalances restores the balances of the wrapped venue if it keeps them itself
+func (f *FilteredExecutor) RestoreBalances(balances []models.Balance) {
+	if restorer, ok := f.Executor.(BalanceRestorer); ok {
+		restorer.RestoreBalances(balances)
+	}
+}
+
+// PlaceOrder normalizes an order and submits it to the wrapped venue
+func (f *FilteredExecutor) PlaceOrder(ctx context.Context, order *models.OrderRequest) (*models.Order, error) {
+	normalized, err := f.Normalize(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	if normalized.Quantity != order.Quantity || normalized.Price != order.Price {
+		f.logger.WithFields(map[string]interface{}{
+			"symbol":         order.Symbol,
+			"quantity":       order.Quantity,
+			"price":          order.Price,
+			"adjusted_qty":   normalized.Quantity,
+			"adjusted_price": normalized.Price,
+		}).Debug("Order normalized to exchange filters")
+	}
+
+	return f.Executor.PlaceOrder(ctx, normalized)
+}
+
+// Normalize returns the order as PlaceOrder would submit it, valuing market orders at the
+// latest candle close
+func (f *FilteredExecutor) Normalize(ctx context.Context, order *models.OrderRequest) (*models.OrderRequest, error) {
+	filters, err := f.filters.SymbolFilters(ctx, order.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange filters for %s: %w", order.Symbol, err)
+	}
+
+	var refPrice float64
+	if candle, ok := f.prices.LatestCandle(order.Symbol); ok {
+		refPrice = candle.Close
+	}
+	return NormalizeOrder(order, filters, refPrice)
+}
+
+// NormalizeOrder returns a copy of the order with quantity rounded down to the step size and
+// limit prices rounded to the tick size in the order's favour, or an error wrapping
+// ErrFilterViolation if the order breaks a limit. refPrice values market orders; the
+// notional checks are skipped for market orders when it is zero.
+func NormalizeOrder(order *models.OrderRequest, filters models.SymbolFilters, refPrice float64) (*models.OrderRequest, error) {
+	if filters.Status != "" && filters.Status != "TRADING" {
+		return nil, fmt.Errorf("%w: %s is not trading (status %s)", ErrFilterViolation, order.Symbol, filters.Status)
+	}
+
+	normalized := *order
+	isMarket := order.Type == "MARKET"
+
+	// Quantity, market orders use MARKET_LOT_SIZE where it is set
+	stepSize, minQty, maxQty := filters.StepSize, filters.MinQty, filters.MaxQty
+	if isMarket {
+		if filters.MarketStepSize > 0 {
+			stepSize = filters.MarketStepSize
+		}
+		if filters.MarketMinQty > 0 {
+			minQty = filters.MarketMinQty
+		}
+		if filters.MarketMaxQty > 0 {
+			maxQty = filters.MarketMaxQty
+		}
+	}
+
+	normalized.Quantity = utils.FloorToStep(order.Quantity, stepSize)
+	if normalized.Quantity <= 0 || normalized.Quantity < minQty {
+		return nil, fmt.Errorf("%w: quantity %v of %s is below the minimum of %v", ErrFilterViolation, order.Quantity, order.Symbol, minQty)
+	}
+	if maxQty > 0 && normalized.Quantity > maxQty {
+		return nil, fmt.Errorf("%w: quantity %v of %s exceeds the maximum of %v", ErrFilterViolation, normalized.Quantity, order.Symbol, maxQty)
+	}
+
+	// Price, buys round down and sells round up so the limit is never worse than requested
+	price := refPrice
+	if !isMarket {
+		if order.Side == "BUY" {
+			normalized.Price = utils.FloorToStep(order.Price, filters.TickSize)
+		} else {
+			normalized.Price = utils.CeilToStep(order.Price, filters.TickSize)
+		}
+		if normalized.Price <= 0 || normalized.Price < filters.MinPrice {
+			return nil, fmt.Errorf("%w: price %v of %s is below the minimum of %v", ErrFilterViolation, order.Price, order.Symbol, filters.MinPrice)
+		}
+		if filters.MaxPrice > 0 && normalized.Price > filters.MaxPrice {
+			return nil, fmt.Errorf("%w: price %v of %s exceeds the maximum of %v", ErrFilterViolation, normalized.Price, order.Symbol, filters.MaxPrice)
+		}
+		price = normalized.Price
+	}
+
+	// Notional
+	if price > 0 {
+		notional := normalized.Quantity * price
+		if filters.MinNotional > 0 && (!isMarket || filters.MinNotionalMarket) && notional < filters.MinNotional {
+			return nil, fmt.Errorf("%w: notional %.8f of %s is below the minimum of %v", ErrFilterViolation, notional, order.Symbol, filters.MinNotional)
+		}
+		if filters.MaxNotional > 0 && (!isMarket || filters.MaxNotionalMarket) && notional > filters.MaxNotional {
+			return nil, fmt.Errorf("%w: notional %.8f of %s exceeds the maximum of %v", ErrFilterViolation, notional, order.Symbol, filters.MaxNotional)
+		}
+	}
+
+	return &normalized, nil
+}
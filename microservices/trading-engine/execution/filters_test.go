@@ -0,0 +1,159 @@
+package execution
+
+import (
+	"errors"
+	"testing"
+
+	"trading-engine/models"
+)
+
+// btcFilters are the trading rules of a BTCUSDT-like symbol
+var btcFilters = models.SymbolFilters{
+	Symbol:      "BTCUSDT",
+	Status:      "TRADING",
+	TickSize:    0.01,
+	MinPrice:    0.01,
+	MaxPrice:    1000000,
+	StepSize:    0.00001,
+	MinQty:      0.00001,
+	MaxQty:      9000,
+	MinNotional: 5,
+	MaxNotional: 9000000,
+}
+
+func TestNormalizeOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		order        models.OrderRequest
+		filters      func(f *models.SymbolFilters)
+		refPrice     float64
+		wantErr      error
+		wantQuantity float64
+		wantPrice    float64
+	}{
+		{
+			name:         "market quantity floored to the step size",
+			order:        models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 0.123456789},
+			refPrice:     30000,
+			wantQuantity: 0.12345,
+		},
+		{
+			name:         "quantity with float error",
+			order:        models.OrderRequest{Side: "SELL", Type: "MARKET", Quantity: 0.3},
+			filters:      func(f *models.SymbolFilters) { f.StepSize = 0.1; f.MinQty = 0.1 },
+			refPrice:     30000,
+			wantQuantity: 0.3,
+		},
+		{
+			name:         "limit buy rounded down to the tick size",
+			order:        models.OrderRequest{Side: "BUY", Type: "LIMIT", Quantity: 0.5, Price: 30000.019},
+			wantQuantity: 0.5,
+			wantPrice:    30000.01,
+		},
+		{
+			name:         "limit sell rounded up to the tick size",
+			order:        models.OrderRequest{Side: "SELL", Type: "LIMIT", Quantity: 0.5, Price: 30000.011},
+			wantQuantity: 0.5,
+			wantPrice:    30000.02,
+		},
+		{
+			name:     "quantity floored below the minimum",
+			order:    models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 0.000009},
+			refPrice: 30000,
+			wantErr:  ErrFilterViolation,
+		},
+		{
+			name:    "quantity above the maximum",
+			order:   models.OrderRequest{Side: "BUY", Type: "LIMIT", Quantity: 9001, Price: 1},
+			wantErr: ErrFilterViolation,
+		},
+		{
+			name:         "market orders use MARKET_LOT_SIZE",
+			order:        models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 0.123456},
+			filters:      func(f *models.SymbolFilters) { f.MarketStepSize = 0.001; f.MarketMinQty = 0.001; f.MarketMaxQty = 100 },
+			refPrice:     30000,
+			wantQuantity: 0.123,
+		},
+		{
+			name:    "MARKET_LOT_SIZE maximum",
+			order:   models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 150},
+			filters: func(f *models.SymbolFilters) { f.MarketMaxQty = 100 },
+			wantErr: ErrFilterViolation,
+		},
+		{
+			name:         "limit orders keep LOT_SIZE",
+			order:        models.OrderRequest{Side: "BUY", Type: "LIMIT", Quantity: 0.123456, Price: 30000},
+			filters:      func(f *models.SymbolFilters) { f.MarketStepSize = 0.001 },
+			wantQuantity: 0.12345,
+			wantPrice:    30000,
+		},
+		{
+			name:    "limit price below the minimum",
+			order:   models.OrderRequest{Side: "BUY", Type: "LIMIT", Quantity: 1, Price: 0.004},
+			wantErr: ErrFilterViolation,
+		},
+		{
+			name:    "limit notional below the minimum",
+			order:   models.OrderRequest{Side: "BUY", Type: "LIMIT", Quantity: 0.0001, Price: 30000},
+			wantErr: ErrFilterViolation,
+		},
+		{
+			name:         "market notional not checked without applyToMarket",
+			order:        models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 0.0001},
+			refPrice:     30000,
+			wantQuantity: 0.0001,
+		},
+		{
+			name:     "market notional below the minimum with applyToMarket",
+			order:    models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 0.0001},
+			filters:  func(f *models.SymbolFilters) { f.MinNotionalMarket = true },
+			refPrice: 30000,
+			wantErr:  ErrFilterViolation,
+		},
+		{
+			name:         "market notional unchecked without a reference price",
+			order:        models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 0.0001},
+			filters:      func(f *models.SymbolFilters) { f.MinNotionalMarket = true },
+			wantQuantity: 0.0001,
+		},
+		{
+			name:     "market notional above the maximum with applyToMarket",
+			order:    models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 400},
+			filters:  func(f *models.SymbolFilters) { f.MaxNotionalMarket = true },
+			refPrice: 30000,
+			wantErr:  ErrFilterViolation,
+		},
+		{
+			name:    "symbol not trading",
+			order:   models.OrderRequest{Side: "BUY", Type: "MARKET", Quantity: 0.5},
+			filters: func(f *models.SymbolFilters) { f.Status = "BREAK" },
+			wantErr: ErrFilterViolation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := btcFilters
+			if tt.filters != nil {
+				tt.filters(&filters)
+			}
+			order := tt.order
+			order.Symbol = "BTCUSDT"
+
+			normalized, err := NormalizeOrder(&order, filters, tt.refPrice)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NormalizeOrder error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if normalized.Quantity != tt.wantQuantity || normalized.Price != tt.wantPrice {
+				t.Errorf("normalized to %v at %v, want %v at %v",
+					normalized.Quantity, normalized.Price, tt.wantQuantity, tt.wantPrice)
+			}
+			if order.Quantity != tt.order.Quantity || order.Price != tt.order.Price {
+				t.Error("NormalizeOrder modified the order it was given")
+			}
+		})
+	}
+}
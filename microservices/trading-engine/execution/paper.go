@@ -0,0 +1,395 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"trading-engine/clock"
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+	"trading-engine/utils"
+)
+
+// PaperExecutor simulates order execution against the engine's market data.
+// Orders with a margin side effect borrow and repay the base asset against
+// quote collateral, so short positions can be simulated as well.
+type PaperExecutor struct {
+	config      *config.PaperConfig
+	quoteAsset  string
+	prices      PriceSource
+	clock       clock.Clock
+	logger      *logger.Logger
+	balances    map[string]*models.Balance
+	openOrders  map[int64]*models.Order
+	nextOrderID int64
+	mu          sync.Mutex
+}
+
+// NewPaperExecutor creates a new paper trading executor
+func NewPaperExecutor(cfg *config.PaperConfig, quoteAsset string, prices PriceSource, clk clock.Clock, log *logger.Logger) *PaperExecutor {
+	return &PaperExecutor{
+		config:     cfg,
+		quoteAsset: quoteAsset,
+		prices:     prices,
+		clock:      clk,
+		logger:     log,
+		balances: map[string]*models.Balance{
+			quoteAsset: {Asset: quoteAsset, Free: cfg.StartingBalance},
+		},
+		openOrders:  make(map[int64]*models.Order),
+		nextOrderID: 1,
+	}
+}
+
+// Name returns the venue name
+func (p *PaperExecutor) Name() string {
+	return config.TradingModePaper
+}
+
+// PlaceOrder simulates an order fill from the latest candle
+func (p *PaperExecutor) PlaceOrder(ctx context.Context, req *models.OrderRequest) (*models.Order, error) {
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("order quantity must be positive")
+	}
+	if req.Side != "BUY" && req.Side != "SELL" {
+		return nil, fmt.Errorf("unsupported order side: %s", req.Side)
+	}
+
+	candle, ok := p.prices.LatestCandle(req.Symbol)
+	if !ok {
+		return nil, fmt.Errorf("no market data for %s", req.Symbol)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.matchOpenOrders(req.Symbol, candle)
+
+	order := &models.Order{
+		OrderID:        p.nextOrderID,
+		ClientOrderID:  req.ClientOrderID,
+		Symbol:         req.Symbol,
+		Side:           req.Side,
+		Type:           req.Type,
+		Status:         "NEW",
+		Price:          req.Price,
+		Quantity:       req.Quantity,
+		SideEffectType: req.SideEffectType,
+		Timestamp:      p.clock.Now(),
+	}
+
+	switch req.Type {
+	case "MARKET":
+		fillPrice := p.slippedPrice(candle.Close, req.Side)
+		quantity := p.fillableQuantity(req.Quantity, candle)
+		if err := p.checkFunds(req, quantity, fillPrice); err != nil {
+			return nil, err
+		}
+		p.borrowShortfall(req, quantity)
+
+		p.fill(order, quantity, fillPrice)
+		if order.ExecutedQty < order.Quantity {
+			// Binance expires the unfilled remainder of a market order
+			order.Status = "EXPIRED"
+		}
+
+	case "LIMIT":
+		if err := p.checkFunds(req, req.Quantity, req.Price); err != nil {
+			return nil, err
+		}
+		p.borrowShortfall(req, req.Quantity)
+
+		if p.limitCrosses(order, candle.Close) {
+			p.fill(order, p.fillableQuantity(req.Quantity, candle), req.Price)
+		}
+		if order.Status != "FILLED" {
+			p.lock(order)
+			p.openOrders[order.OrderID] = order
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported order type: %s", req.Type)
+	}
+
+	p.nextOrderID++
+
+	p.logger.WithFields(map[string]interface{}{
+		"symbol":       order.Symbol,
+		"side":         order.Side,
+		"type":         order.Type,
+		"order_id":     order.OrderID,
+		"status":       order.Status,
+		"executed_qty": order.ExecutedQty,
+		"avg_price":    order.AvgPrice,
+		"commission":   order.Commission,
+	}).Info("Paper order executed")
+
+	result := *order
+	return &result, nil
+}
+
+// CancelOrder cancels a resting paper order and releases its locked funds
+func (p *PaperExecutor) CancelOrder(ctx context.Context, symbol string, orderID int64) (*models.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order, exists := p.openOrders[orderID]
+	if !exists || order.Symbol != symbol {
+		return nil, fmt.Errorf("order %d not found for symbol: %s", orderID, symbol)
+	}
+
+	p.unlock(order)
+	delete(p.openOrders, orderID)
+	order.Status = "CANCELED"
+
+	result := *order
+	return &result, nil
+}
+
+// RestoreBalances replaces the simulated account balances
+func (p *PaperExecutor) RestoreBalances(balances []models.Balance) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.balances = make(map[string]*models.Balance, len(balances))
+	for _, balance := range balances {
+		restored := balance
+		p.balances[balance.Asset] = &restored
+	}
+}
+
+// GetBalances returns the simulated account balances
+func (p *PaperExecutor) GetBalances(ctx context.Context) ([]models.Balance, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	balances := make([]models.Balance, 0, len(p.balances))
+	for _, balance := range p.balances {
+		if balance.Free == 0 && balance.Locked == 0 && balance.Borrowed == 0 {
+			continue
+		}
+		balances = append(balances, *balance)
+	}
+
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Asset < balances[j].Asset
+	})
+
+	return balances, nil
+}
+
+// GetOpenOrders returns resting paper orders, for all symbols when symbol is empty
+func (p *PaperExecutor) GetOpenOrders(ctx context.Context, symbol string) ([]models.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Let resting orders catch up with the market before reporting them
+	symbols := make(map[string]bool)
+	for _, order := range p.openOrders {
+		if symbol == "" || order.Symbol == symbol {
+			symbols[order.Symbol] = true
+		}
+	}
+	for s := range symbols {
+		if candle, ok := p.prices.LatestCandle(s); ok {
+			p.matchOpenOrders(s, candle)
+		}
+	}
+
+	orders := make([]models.Order, 0)
+	for _, order := range p.openOrders {
+		if symbol != "" && order.Symbol != symbol {
+			continue
+		}
+		orders = append(orders, *order)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OrderID < orders[j].OrderID
+	})
+
+	return orders, nil
+}
+
+// slippedPrice moves the reference price against the order side
+func (p *PaperExecutor) slippedPrice(price float64, side string) float64 {
+	slippage := price * p.config.SlippageBps / 10000
+	if side == "BUY" {
+		return price + slippage
+	}
+	return price - slippage
+}
+
+// fillableQuantity caps a fill at a share of the candle volume to simulate partial fills
+func (p *PaperExecutor) fillableQuantity(quantity float64, candle models.Candle) float64 {
+	if p.config.MaxVolumePct <= 0 || candle.Volume <= 0 {
+		return quantity
+	}
+
+	available := candle.Volume * p.config.MaxVolumePct / 100
+	if quantity > available {
+		return available
+	}
+	return quantity
+}
+
+// limitCrosses reports whether a limit order is marketable at the given price
+func (p *PaperExecutor) limitCrosses(order *models.Order, price float64) bool {
+	if order.Side == "BUY" {
+		return price <= order.Price
+	}
+	return price >= order.Price
+}
+
+// checkFunds verifies the account can settle an order
+func (p *PaperExecutor) checkFunds(req *models.OrderRequest, quantity, price float64) error {
+	quote := p.balance(p.quoteAsset)
+	if req.Side == "BUY" {
+		required := quantity * price * (1 + p.config.FeeRate)
+		if required > quote.Free {
+			return fmt.Errorf("insufficient %s balance: required %.8f, available %.8f", p.quoteAsset, required, quote.Free)
+		}
+		return nil
+	}
+
+	baseAsset := BaseAsset(req.Symbol, p.quoteAsset)
+	base := p.balance(baseAsset)
+	if req.SideEffectType != models.SideEffectMarginBuy {
+		if quantity > base.Free {
+			return fmt.Errorf("insufficient %s balance: required %.8f, available %.8f", baseAsset, quantity, base.Free)
+		}
+		return nil
+	}
+
+	// Borrowed assets must stay covered by the quote balance at the current price
+	shortfall := utils.MaxFloat64(0, quantity-base.Free)
+	required := (base.Borrowed + shortfall) * price
+	if required > quote.Free {
+		return fmt.Errorf("insufficient %s collateral to borrow %s: required %.8f, available %.8f", p.quoteAsset, baseAsset, required, quote.Free)
+	}
+	return nil
+}
+
+// borrowShortfall borrows the part of a margin sell not covered by the free base balance
+func (p *PaperExecutor) borrowShortfall(req *models.OrderRequest, quantity float64) {
+	if req.Side != "SELL" || req.SideEffectType != models.SideEffectMarginBuy {
+		return
+	}
+
+	base := p.balance(BaseAsset(req.Symbol, p.quoteAsset))
+	if shortfall := quantity - base.Free; shortfall > 0 {
+		base.Free += shortfall
+		base.Borrowed += shortfall
+	}
+}
+
+// repayBorrowed repays borrowed base asset out of the free balance
+func (p *PaperExecutor) repayBorrowed(base *models.Balance) {
+	repay := utils.MinFloat64(base.Free, base.Borrowed)
+	base.Free -= repay
+	base.Borrowed -= repay
+}
+
+// fill settles an additional fill of an order against the balances
+func (p *PaperExecutor) fill(order *models.Order, quantity, price float64) {
+	if quantity <= 0 {
+		return
+	}
+
+	quoteQty := quantity * price
+	fee := quoteQty * p.config.FeeRate
+
+	base := p.balance(BaseAsset(order.Symbol, p.quoteAsset))
+	quote := p.balance(p.quoteAsset)
+	if order.Side == "BUY" {
+		base.Free += quantity
+		quote.Free -= quoteQty + fee
+		if order.SideEffectType == models.SideEffectAutoRepay {
+			p.repayBorrowed(base)
+		}
+	} else {
+		base.Free -= quantity
+		quote.Free += quoteQty - fee
+	}
+
+	totalQuote := order.AvgPrice*order.ExecutedQty + quoteQty
+	order.ExecutedQty += quantity
+	order.AvgPrice = totalQuote / order.ExecutedQty
+	order.Commission += fee
+	order.CommissionAsset = p.quoteAsset
+	order.Timestamp = p.clock.Now()
+
+	if order.ExecutedQty >= order.Quantity {
+		order.Status = "FILLED"
+	} else {
+		order.Status = "PARTIALLY_FILLED"
+	}
+}
+
+// matchOpenOrders fills resting limit orders that the candle has traded through
+func (p *PaperExecutor) matchOpenOrders(symbol string, candle models.Candle) {
+	for id, order := range p.openOrders {
+		if order.Symbol != symbol {
+			continue
+		}
+
+		touched := (order.Side == "BUY" && candle.Low <= order.Price) ||
+			(order.Side == "SELL" && candle.High >= order.Price)
+		if !touched {
+			continue
+		}
+
+		p.unlock(order)
+		p.fill(order, p.fillableQuantity(order.Quantity-order.ExecutedQty, candle), order.Price)
+		if order.Status == "FILLED" {
+			delete(p.openOrders, id)
+			continue
+		}
+		p.lock(order)
+	}
+}
+
+// lock reserves the funds of the unfilled part of a resting order
+func (p *PaperExecutor) lock(order *models.Order) {
+	remaining := order.Quantity - order.ExecutedQty
+	if order.Side == "BUY" {
+		amount := remaining * order.Price * (1 + p.config.FeeRate)
+		quote := p.balance(p.quoteAsset)
+		quote.Free -= amount
+		quote.Locked += amount
+		return
+	}
+
+	base := p.balance(BaseAsset(order.Symbol, p.quoteAsset))
+	base.Free -= remaining
+	base.Locked += remaining
+}
+
+// unlock releases the funds reserved by lock
+func (p *PaperExecutor) unlock(order *models.Order) {
+	remaining := order.Quantity - order.ExecutedQty
+	if order.Side == "BUY" {
+		amount := remaining * order.Price * (1 + p.config.FeeRate)
+		quote := p.balance(p.quoteAsset)
+		quote.Free += amount
+		quote.Locked -= amount
+		return
+	}
+
+	base := p.balance(BaseAsset(order.Symbol, p.quoteAsset))
+	base.Free += remaining
+	base.Locked -= remaining
+}
+
+// balance returns the mutable balance of an asset, creating it if needed
+func (p *PaperExecutor) balance(asset string) *models.Balance {
+	balance, exists := p.balances[asset]
+	if !exists {
+		balance = &models.Balance{Asset: asset}
+		p.balances[asset] = balance
+	}
+	return balance
+}
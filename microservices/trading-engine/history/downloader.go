@@ -0,0 +1,219 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+// KlineSource fetches a page of candles opening within a time range
+type KlineSource interface {
+	FetchKlines(ctx context.Context, symbol, interval string, start, end time.Time, limit int) ([]models.Candle, error)
+}
+
+// Store persists candles and reports which ones are already stored
+type Store interface {
+	SaveMarketData(timeframe string, candles []models.Candle) error
+	GetMarketDataTimestamps(symbol, timeframe string, start, end time.Time) ([]time.Time, error)
+}
+
+// Gap is a run of consecutive candles missing from the stored history
+type Gap struct {
+	Start   time.Time `json:"start"` // Open time of the first missing candle
+	End     time.Time `json:"end"`   // Open time of the last missing candle
+	Candles int       `json:"candles"`
+}
+
+// Result summarizes a download
+type Result struct {
+	Symbol     string        `json:"symbol"`
+	Interval   string        `json:"interval"`
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	Expected   int           `json:"expected"`   // Candles that should exist in the range
+	Existing   int           `json:"existing"`   // Candles already stored before the download
+	Downloaded int           `json:"downloaded"` // Candles fetched and stored
+	Gaps       []Gap         `json:"gaps"`       // Candles the exchange did not return, e.g. during outages
+	Duration   time.Duration `json:"duration"`
+}
+
+// Downloader backfills the market_data table from the klines endpoint. Only candles missing
+// from the store are requested, and every page is saved as it arrives, so an interrupted
+// download resumes where it stopped when run again.
+type Downloader struct {
+	source KlineSource
+	store  Store
+	logger *logger.Logger
+}
+
+// NewDownloader creates a downloader fetching from source into store
+func NewDownloader(source KlineSource, store Store, log *logger.Logger) *Downloader {
+	return &Downloader{
+		source: source,
+		store:  store,
+		logger: log,
+	}
+}
+
+// Download stores every closed candle of a symbol and interval opening in [start, end)
+func (d *Downloader) Download(ctx context.Context, symbol, interval string, start, end time.Time) (*Result, error) {
+	step, err := binance.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Candles open on multiples of the interval, and only closed candles are kept
+	first := binance.IntervalOpen(start, step)
+	if first.Before(start) {
+		first = first.Add(step)
+	}
+	if now := time.Now().UTC(); end.After(now) {
+		end = now
+	}
+	if first.Add(step).After(end) {
+		return nil, fmt.Errorf("empty range: %s to %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	began := time.Now()
+	result := &Result{
+		Symbol:   symbol,
+		Interval: interval,
+		Start:    first,
+		End:      end,
+		Expected: int(end.Sub(first) / step),
+	}
+
+	stored, err := d.store.GetMarketDataTimestamps(symbol, interval, first, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored candles: %w", err)
+	}
+	have := make(map[int64]bool, len(stored))
+	for _, timestamp := range stored {
+		have[timestamp.Unix()] = true
+	}
+
+	missing := missingRanges(first, end, step, have)
+	result.Existing = result.Expected
+	for _, gap := range missing {
+		result.Existing -= gap.Candles
+	}
+
+	d.logger.WithFields(map[string]interface{}{
+		"symbol":   symbol,
+		"interval": interval,
+		"start":    first.Format(time.RFC3339),
+		"end":      end.Format(time.RFC3339),
+		"expected": result.Expected,
+		"existing": result.Existing,
+		"ranges":   len(missing),
+	}).Info("Starting kline download")
+
+	for _, gap := range missing {
+		received, err := d.downloadRange(ctx, symbol, interval, step, gap)
+		for _, timestamp := range received {
+			have[timestamp] = true
+		}
+		result.Downloaded += len(received)
+		if err != nil {
+			result.Duration = time.Since(began)
+			return result, err
+		}
+	}
+
+	// Whatever is still missing was not returned by the exchange
+	result.Gaps = missingRanges(first, end, step, have)
+	for _, gap := range result.Gaps {
+		d.logger.WithFields(map[string]interface{}{
+			"symbol":  symbol,
+			"start":   gap.Start.Format(time.RFC3339),
+			"end":     gap.End.Format(time.RFC3339),
+			"candles": gap.Candles,
+		}).Warn("Gap in exchange kline history")
+	}
+
+	result.Duration = time.Since(began)
+	d.logger.WithFields(map[string]interface{}{
+		"symbol":     symbol,
+		"interval":   interval,
+		"downloaded": result.Downloaded,
+		"gaps":       len(result.Gaps),
+		"duration":   result.Duration.String(),
+	}).Info("Kline download complete")
+
+	return result, nil
+}
+
+// downloadRange pages through a missing range, saving each page, and returns the open
+// times (unix seconds) of the candles stored
+func (d *Downloader) downloadRange(ctx context.Context, symbol, interval string, step time.Duration, gap Gap) ([]int64, error) {
+	var received []int64
+	seen := make(map[int64]bool)
+
+	for from := gap.Start; !from.After(gap.End); {
+		page, err := d.source.FetchKlines(ctx, symbol, interval, from, gap.End, binance.MaxKlinesPerRequest)
+		if err != nil {
+			return received, fmt.Errorf("failed to fetch %s klines from %s: %w", symbol, from.Format(time.RFC3339), err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		// Drop duplicates and candles outside the range, which a misaligned page may include
+		candles := make([]models.Candle, 0, len(page))
+		for _, candle := range page {
+			openTime := candle.Timestamp.Unix()
+			if seen[openTime] || candle.Timestamp.Before(gap.Start) || candle.Timestamp.After(gap.End) {
+				continue
+			}
+			seen[openTime] = true
+			candles = append(candles, candle)
+		}
+
+		if err := d.store.SaveMarketData(interval, candles); err != nil {
+			return received, fmt.Errorf("failed to save %s klines: %w", symbol, err)
+		}
+		for _, candle := range candles {
+			received = append(received, candle.Timestamp.Unix())
+		}
+
+		d.logger.WithFields(map[string]interface{}{
+			"symbol":  symbol,
+			"from":    page[0].Timestamp.UTC().Format(time.RFC3339),
+			"to":      page[len(page)-1].Timestamp.UTC().Format(time.RFC3339),
+			"candles": len(candles),
+		}).Debug("Saved kline page")
+
+		next := page[len(page)-1].Timestamp.Add(step)
+		if !next.After(from) {
+			break // The exchange returned nothing newer, avoid looping on the same page
+		}
+		from = next
+	}
+
+	return received, nil
+}
+
+// missingRanges returns the runs of closed candles in [first, end) whose open time is not in have
+func missingRanges(first, end time.Time, step time.Duration, have map[int64]bool) []Gap {
+	var gaps []Gap
+	var current *Gap
+
+	for t := first; !t.Add(step).After(end); t = t.Add(step) {
+		if have[t.Unix()] {
+			current = nil
+			continue
+		}
+		if current == nil {
+			gaps = append(gaps, Gap{Start: t})
+			current = &gaps[len(gaps)-1]
+		}
+		current.End = t
+		current.Candles++
+	}
+
+	return gaps
+}
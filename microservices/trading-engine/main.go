@@ -15,6 +15,8 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/rs/cors"
 
+	"trading-engine/backtest"
+	"trading-engine/binance"
 	"trading-engine/cache"
 	"trading-engine/config"
 	"trading-engine/database"
@@ -34,9 +36,18 @@ type Application struct {
 	clients      map[*websocket.Conn]bool
 	clientsMutex sync.RWMutex
 	broadcast    chan []byte
+	metrics      *eventMetrics
 }
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "backtest" {
+		os.Exit(runBacktestCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "download" {
+		os.Exit(runDownloadCommand(os.Args[2:]))
+	}
+
 	// Initialize configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -109,12 +120,22 @@ func main() {
 		},
 		clients:   make(map[*websocket.Conn]bool),
 		broadcast: make(chan []byte, 256),
+		metrics:   newEventMetrics(),
 	}
 
 	// Start the engine
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Resume open positions, balances and timers from before the last shutdown or crash
+	if err := app.restoreEngineState(); err != nil {
+		log.Error("Failed to restore engine state: %v", err)
+		os.Exit(1)
+	}
+
+	// Follow engine events from startup
+	app.startEventSubscribers(ctx)
+
 	if err := app.engine.Start(ctx); err != nil {
 		log.Error("Failed to start trading engine: %v", err)
 		os.Exit(1)
@@ -123,9 +144,6 @@ func main() {
 	// Start WebSocket broadcast handler
 	go app.handleWebSocketBroadcasts()
 
-	// Start periodic data broadcasting
-	go app.startDataBroadcasting(ctx)
-
 	// Setup HTTP server
 	router := app.setupRoutes()
 
@@ -166,6 +184,11 @@ func main() {
 		log.Error("Engine shutdown failed: %v", err)
 	}
 
+	// Save the final state for the next start
+	if app.database != nil {
+		app.saveEngineState(shutdownCtx)
+	}
+
 	log.Info("Trading engine shutdown complete")
 }
 
@@ -197,9 +220,14 @@ func (app *Application) setupRoutes() http.Handler {
 	// Market data
 	api.HandleFunc("/market-data", app.getMarketDataHandler).Methods("GET")
 	api.HandleFunc("/market-data/{symbol}", app.getSymbolDataHandler).Methods("GET")
+	api.HandleFunc("/orderbook/{symbol}", app.getOrderBookHandler).Methods("GET")
 
 	// Performance metrics
 	api.HandleFunc("/performance", app.getPerformanceHandler).Methods("GET")
+	api.HandleFunc("/metrics/events", app.getEventMetricsHandler).Methods("GET")
+
+	// Backtesting
+	api.HandleFunc("/backtest", app.runBacktestHandler).Methods("POST")
 
 	// Health check
 	api.HandleFunc("/health", app.healthCheckHandler).Methods("GET")
@@ -230,22 +258,6 @@ func (app *Application) getTradingStateHandler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	// Save to database
-	if app.database != nil {
-		// Save recent trades and positions
-		for _, trade := range state.Trades {
-			if err := app.database.SaveTrade(&trade); err != nil {
-				app.logger.Error("Failed to save trade to database: %v", err)
-			}
-		}
-
-		for _, position := range state.Positions {
-			if err := app.database.SavePosition(&position); err != nil {
-				app.logger.Error("Failed to save position to database: %v", err)
-			}
-		}
-	}
-
 	app.writeJSONResponse(w, state)
 }
 
@@ -262,6 +274,7 @@ func (app *Application) disableTradingHandler(w http.ResponseWriter, r *http.Req
 func (app *Application) getTradingStatusHandler(w http.ResponseWriter, r *http.Request) {
 	status := map[string]interface{}{
 		"enabled":   app.engine.IsTrading(),
+		"venue":     app.engine.Venue(),
 		"timestamp": time.Now(),
 	}
 	app.writeJSONResponse(w, status)
@@ -351,13 +364,6 @@ func (app *Application) updateSettingsHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Save to database
-	if app.database != nil {
-		if err := app.database.SaveTradingSettings(&settings); err != nil {
-			app.logger.Error("Failed to save settings to database: %v", err)
-		}
-	}
-
 	app.writeJSONResponse(w, map[string]string{"status": "updated"})
 }
 
@@ -381,6 +387,19 @@ func (app *Application) getSymbolDataHandler(w http.ResponseWriter, r *http.Requ
 	app.writeErrorResponse(w, http.StatusNotFound, "Symbol not found")
 }
 
+func (app *Application) getOrderBookHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	symbol := vars["symbol"]
+
+	summary, ok := app.engine.OrderBook(symbol)
+	if !ok {
+		app.writeErrorResponse(w, http.StatusNotFound, "Order book not available")
+		return
+	}
+
+	app.writeJSONResponse(w, summary)
+}
+
 func (app *Application) getPerformanceHandler(w http.ResponseWriter, r *http.Request) {
 	state := app.engine.GetTradingState()
 
@@ -391,12 +410,43 @@ func (app *Application) getPerformanceHandler(w http.ResponseWriter, r *http.Req
 		"availableBalance": state.AvailableBalance,
 		"totalTrades":      len(state.Trades),
 		"activePositions":  len(state.Positions),
+		"strategies":       app.engine.StrategyPerformance(),
 		"timestamp":        time.Now(),
 	}
 
 	app.writeJSONResponse(w, performance)
 }
 
+func (app *Application) runBacktestHandler(w http.ResponseWriter, r *http.Request) {
+	var req backtestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.writeErrorResponse(w, http.StatusBadRequest, "Invalid backtest request format")
+		return
+	}
+
+	if req.Symbol == "" {
+		app.writeErrorResponse(w, http.StatusBadRequest, "symbol is required")
+		return
+	}
+	if req.Interval == "" {
+		req.Interval = "5m"
+	}
+
+	candles, err := loadBacktestCandles(r.Context(), app.config, app.logger, app.database, &req)
+	if err != nil {
+		app.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := backtest.NewRunner(app.config, app.logger).Run(r.Context(), &req.Config, candles)
+	if err != nil {
+		app.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	app.writeJSONResponse(w, result)
+}
+
 func (app *Application) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	health := map[string]interface{}{
 		"status":    "healthy",
@@ -405,6 +455,22 @@ func (app *Application) healthCheckHandler(w http.ResponseWriter, r *http.Reques
 		"trading":   app.engine.IsTrading(),
 	}
 
+	// Check market data streams
+	streams := app.engine.StreamStatus()
+	for _, stream := range streams {
+		if stream.State != models.StreamConnected {
+			health["status"] = "degraded"
+		}
+	}
+	health["marketStreams"] = streams
+
+	// Check the Binance REST circuit breaker
+	exchange := app.engine.ExchangeStatus()
+	if exchange != binance.CircuitClosed {
+		health["status"] = "degraded"
+	}
+	health["exchangeCircuit"] = exchange
+
 	// Check database connectivity
 	if app.database != nil {
 		health["database"] = "connected"
@@ -495,33 +561,6 @@ func (app *Application) handleWebSocketBroadcasts() {
 	}
 }
 
-func (app *Application) startDataBroadcasting(ctx context.Context) {
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			state := app.engine.GetTradingState()
-			message := map[string]interface{}{
-				"type":      "update",
-				"data":      state,
-				"timestamp": time.Now(),
-			}
-
-			if data, err := json.Marshal(message); err == nil {
-				select {
-				case app.broadcast <- data:
-				default:
-					// Channel is full, skip this update
-				}
-			}
-		}
-	}
-}
-
 // Utility functions
 func (app *Application) writeJSONResponse(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -17,6 +17,8 @@ type Trade struct {
 	PnL        *float64  `json:"pnl,omitempty" db:"pnl"`
 	ExitPrice  *float64  `json:"exitPrice,omitempty" db:"exit_price"`
 	HoldTime   *int      `json:"holdTime,omitempty" db:"hold_time"`
+	StrategyID string    `json:"strategyId,omitempty" db:"strategy_id"`
+	Fee        float64   `json:"fee" db:"fee"` // Commission valued in the quote asset
 }
 
 // Position represents an active trading position
@@ -30,6 +32,29 @@ type Position struct {
 	EntryTime     time.Time `json:"entryTime" db:"entry_time"`
 	TargetPrice   *float64  `json:"targetPrice,omitempty" db:"target_price"`
 	StopLossPrice *float64  `json:"stopLossPrice,omitempty" db:"stop_loss_price"`
+	StrategyID    string    `json:"strategyId,omitempty" db:"strategy_id"`
+
+	// Exit management: the entry size scaled out by the take-profit ladder, the best price
+	// reached for trailing, how the stop was last moved and the ladder steps taken
+	InitialQuantity float64 `json:"initialQuantity" db:"initial_quantity"`
+	BestPrice       float64 `json:"bestPrice" db:"best_price"`
+	StopType        string  `json:"stopType,omitempty" db:"stop_type"`
+	LadderStep      int     `json:"ladderStep" db:"ladder_step"`
+
+	// Entry orders the position was built from, more than one when scaled into
+	Entries int `json:"entries" db:"entries"`
+}
+
+// Ways the stop loss of a position was moved after entry
+const (
+	StopTypeBreakEven = "BREAK_EVEN"
+	StopTypeTrailing  = "TRAILING"
+)
+
+// TakeProfitStep scales out part of a position once its profit reaches a level
+type TakeProfitStep struct {
+	ProfitPercent float64 `json:"profitPercent"` // Profit from the entry price
+	ClosePercent  float64 `json:"closePercent"`  // Share of the initial quantity to close
 }
 
 // TradingSettings holds trading configuration
@@ -41,11 +66,39 @@ type TradingSettings struct {
 	MaxPositions      int     `json:"maxPositions" db:"max_positions"`
 	StopLossPercent   float64 `json:"stopLossPercent" db:"stop_loss_percent"`
 	TakeProfitPercent float64 `json:"takeProfitPercent" db:"take_profit_percent"`
-	MaxHoldTime       int     `json:"maxHoldTime" db:"max_hold_time"`
-	ScalingFactor     int     `json:"scalingFactor" db:"scaling_factor"`
-	IsEnabled         bool    `json:"isEnabled" db:"is_enabled"`
+	StopMode          string  `json:"stopMode" db:"stop_mode"`
+	ATRStopMultiple   float64 `json:"atrStopMultiple" db:"atr_stop_multiple"`
+	ATRTargetMultiple float64 `json:"atrTargetMultiple" db:"atr_target_multiple"`
+
+	// Exit management of open positions
+	TrailingMode        string           `json:"trailingMode" db:"trailing_mode"`
+	TrailingPercent     float64          `json:"trailingPercent" db:"trailing_percent"`
+	TrailingATRMultiple float64          `json:"trailingAtrMultiple" db:"trailing_atr_multiple"`
+	BreakEvenPercent    float64          `json:"breakEvenPercent" db:"break_even_percent"` // Profit that moves the stop to entry, 0 disables
+	TakeProfitLadder    []TakeProfitStep `json:"takeProfitLadder,omitempty" db:"take_profit_ladder"`
+
+	// Pyramiding: entries a position may be built from, with the profit it needs before each add
+	ScalingFactor        int     `json:"scalingFactor" db:"scaling_factor"`
+	ScaleInProfitPercent float64 `json:"scaleInProfitPercent" db:"scale_in_profit_percent"`
+
+	MaxHoldTime int  `json:"maxHoldTime" db:"max_hold_time"`
+	IsEnabled   bool `json:"isEnabled" db:"is_enabled"`
 }
 
+// Ways of placing the stop loss and take profit of a new position
+const (
+	StopModePercent = "PERCENT" // StopLossPercent and TakeProfitPercent away from the entry
+	StopModeSwing   = "SWING"   // At the analysis price targets, beyond the recent swing levels
+	StopModeATR     = "ATR"     // ATRStopMultiple and ATRTargetMultiple ATRs away from the entry
+)
+
+// Ways of trailing the stop loss behind the best price of a position
+const (
+	TrailingModeOff     = "OFF"
+	TrailingModePercent = "PERCENT" // TrailingPercent below the best price
+	TrailingModeATR     = "ATR"     // TrailingATRMultiple ATRs below the best price
+)
+
 // WatchlistItem represents a symbol being monitored
 type WatchlistItem struct {
 	Symbol        string             `json:"symbol" db:"symbol"`
@@ -68,10 +121,30 @@ type TechnicalAnalysis struct {
 	EMA200     float64 `json:"ema200" db:"ema200"`
 	RSI        float64 `json:"rsi" db:"rsi"`
 	MACD       float64 `json:"macd" db:"macd"`
+	MACDSignal float64 `json:"macdSignal" db:"macd_signal"`
+	MACDHist   float64 `json:"macdHist" db:"macd_hist"`
 	VWAP       float64 `json:"vwap" db:"vwap"`
 	MA50       float64 `json:"ma50" db:"ma50"`
 	Signal     string  `json:"signal" db:"signal"`
 	Confidence int     `json:"confidence" db:"confidence"`
+
+	// Volatility, momentum and trend strength
+	ATR        float64 `json:"atr" db:"-"`
+	BBUpper    float64 `json:"bbUpper" db:"-"`
+	BBLower    float64 `json:"bbLower" db:"-"`
+	StochRSI   float64 `json:"stochRsi" db:"-"`
+	ADX        float64 `json:"adx" db:"-"`
+	OBV        float64 `json:"obv" db:"-"`
+	SuperTrend float64 `json:"superTrend" db:"-"`
+
+	// Swing levels and the price targets derived from them for the signal
+	SwingHigh  float64 `json:"swingHigh" db:"-"`
+	SwingLow   float64 `json:"swingLow" db:"-"`
+	StopLoss   float64 `json:"stopLoss" db:"-"`
+	TakeProfit float64 `json:"takeProfit" db:"-"`
+
+	// Trend direction per analyzed timeframe, e.g. "1h": "UPTREND"
+	Trends map[string]string `json:"trends,omitempty" db:"-"`
 }
 
 // TradingState represents the current state of the trading system
@@ -86,6 +159,56 @@ type TradingState struct {
 	Watchlist        []WatchlistItem `json:"watchlist"`
 }
 
+// StrategyInstance runs a registered strategy with its own capital budget, settings and symbols
+type StrategyInstance struct {
+	ID       string           `json:"id"`
+	Strategy string           `json:"strategy"`
+	Capital  float64          `json:"capital"`            // Budget from the trading balance, 0 shares the whole balance
+	Symbols  []string         `json:"symbols,omitempty"`  // Watchlist symbols traded, empty trades all of them
+	Settings *TradingSettings `json:"settings,omitempty"` // Nil follows the engine settings
+}
+
+// StrategyPerformance reports the results of one strategy instance
+type StrategyPerformance struct {
+	ID               string  `json:"id"`
+	Strategy         string  `json:"strategy"`
+	Capital          float64 `json:"capital"`
+	AvailableBalance float64 `json:"availableBalance"`
+	TotalPnL         float64 `json:"totalPnL"` // Realized, net of fees
+	TotalTrades      int     `json:"totalTrades"`
+	WinningTrades    int     `json:"winningTrades"`
+	LosingTrades     int     `json:"losingTrades"`
+	WinRate          float64 `json:"winRate"`
+	MaxDrawdown      float64 `json:"maxDrawdown"` // Largest fall of TotalPnL from its peak
+	OpenPositions    int     `json:"openPositions"`
+}
+
+// EngineState is what the engine needs to resume trading after a restart
+type EngineState struct {
+	Positions []Position       `json:"-"` // Kept in the positions table
+	Settings  *TradingSettings `json:"-"` // Kept in the trading_settings table, nil keeps the defaults
+
+	TotalPnL      float64              `json:"totalPnL"`
+	DayPnL        float64              `json:"dayPnL"`
+	Balances      []Balance            `json:"balances,omitempty"` // Paper venue balances, live venues report their own
+	HeldBack      float64              `json:"heldBack"`           // Free quote balance held back for open positions
+	LastTradeTime map[string]time.Time `json:"lastTradeTime"`
+	Strategies    []StrategyAccount    `json:"strategies"`
+	SavedAt       time.Time            `json:"savedAt"`
+}
+
+// StrategyAccount is the budget and results of a strategy instance carried across restarts
+type StrategyAccount struct {
+	ID          string  `json:"id"`
+	Available   float64 `json:"available"`
+	TotalPnL    float64 `json:"totalPnL"`
+	DayPnL      float64 `json:"dayPnL"` // Counted since midnight UTC of the day saved
+	PeakPnL     float64 `json:"peakPnL"`
+	MaxDrawdown float64 `json:"maxDrawdown"`
+	Wins        int     `json:"wins"`
+	Losses      int     `json:"losses"`
+}
+
 // Candle represents OHLCV data
 type Candle struct {
 	Open      float64   `json:"open" db:"open"`
@@ -153,7 +276,7 @@ type BinanceTickerResponse struct {
 type BinanceStreamTickerData struct {
 	Stream string `json:"stream"`
 	Data   struct {
-		E string `json:"E"` // Event time
+		E int64  `json:"E"` // Event time
 		S string `json:"s"` // Symbol
 		C string `json:"c"` // Close price
 		O string `json:"o"` // Open price
@@ -164,6 +287,169 @@ type BinanceStreamTickerData struct {
 	} `json:"data"`
 }
 
+// BinanceKlineEvent represents a kline stream event
+type BinanceKlineEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	Kline     struct {
+		OpenTime  int64  `json:"t"`
+		CloseTime int64  `json:"T"`
+		Symbol    string `json:"s"`
+		Interval  string `json:"i"`
+		Open      string `json:"o"`
+		Close     string `json:"c"`
+		High      string `json:"h"`
+		Low       string `json:"l"`
+		Volume    string `json:"v"`
+		IsClosed  bool   `json:"x"`
+	} `json:"k"`
+}
+
+// KlineUpdate is a candle received from a kline stream, which is final once closed
+type KlineUpdate struct {
+	Candle   Candle
+	IsClosed bool
+}
+
+// BinanceDepthSnapshot represents Binance order book snapshot response
+type BinanceDepthSnapshot struct {
+	LastUpdateID int64      `json:"lastUpdateId"`
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+}
+
+// BinanceDepthEvent represents a diff depth stream event
+type BinanceDepthEvent struct {
+	EventType     string     `json:"e"`
+	EventTime     int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateID int64      `json:"U"`
+	FinalUpdateID int64      `json:"u"`
+	Bids          [][]string `json:"b"`
+	Asks          [][]string `json:"a"`
+}
+
+// BinanceExecutionReport represents an executionReport user data event.
+// Unused upper and lower case pairs are declared so encoding/json does not fold them together.
+type BinanceExecutionReport struct {
+	EventType          string  `json:"e"`
+	EventTime          int64   `json:"E"`
+	Symbol             string  `json:"s"`
+	ClientOrderID      string  `json:"c"`
+	OrigClientOrderID  string  `json:"C"`
+	Side               string  `json:"S"`
+	OrderType          string  `json:"o"`
+	Quantity           string  `json:"q"`
+	QuoteOrderQty      string  `json:"Q"`
+	Price              string  `json:"p"`
+	StopPrice          string  `json:"P"`
+	ExecutionType      string  `json:"x"`
+	Status             string  `json:"X"`
+	RejectReason       string  `json:"r"`
+	OrderID            int64   `json:"i"`
+	Ignore             int64   `json:"I"`
+	LastQty            string  `json:"l"`
+	LastPrice          string  `json:"L"`
+	CumulativeQty      string  `json:"z"`
+	CumulativeQuoteQty string  `json:"Z"`
+	Commission         string  `json:"n"`
+	CommissionAsset    *string `json:"N"`
+	TransactTime       int64   `json:"T"`
+	TradeID            int64   `json:"t"`
+	OrderCreationTime  int64   `json:"O"`
+}
+
+// BinanceAccountPosition represents an outboundAccountPosition user data event
+type BinanceAccountPosition struct {
+	EventType  string `json:"e"`
+	EventTime  int64  `json:"E"`
+	LastUpdate int64  `json:"u"`
+	Balances   []struct {
+		Asset  string `json:"a"`
+		Free   string `json:"f"`
+		Locked string `json:"l"`
+	} `json:"B"`
+}
+
+// ExecutionReport is an order update received on the user data stream.
+// Order carries the cumulative state, the Last fields describe the fill that triggered it.
+type ExecutionReport struct {
+	Order           Order   `json:"order"`
+	ExecutionType   string  `json:"executionType"`
+	RejectReason    string  `json:"rejectReason,omitempty"`
+	TradeID         int64   `json:"tradeId,omitempty"`
+	LastQty         float64 `json:"lastQty"`
+	LastPrice       float64 `json:"lastPrice"`
+	LastCommission  float64 `json:"lastCommission"`
+	CommissionAsset string  `json:"commissionAsset,omitempty"`
+}
+
+// AccountUpdate carries the balances changed by an account event on the user data stream
+type AccountUpdate struct {
+	Balances  []Balance `json:"balances"`
+	Margin    bool      `json:"margin"` // Reported by the cross margin account
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PriceLevel is the quantity resting at a price, a zero quantity removes the level
+type PriceLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// DepthUpdate is an order book snapshot or diff covering update IDs FirstUpdateID to FinalUpdateID
+type DepthUpdate struct {
+	Symbol        string
+	FirstUpdateID int64
+	FinalUpdateID int64
+	Bids          []PriceLevel
+	Asks          []PriceLevel
+}
+
+// OrderBookSummary describes the liquidity of a local order book
+type OrderBookSummary struct {
+	Symbol       string    `json:"symbol"`
+	BestBid      float64   `json:"bestBid"`
+	BestAsk      float64   `json:"bestAsk"`
+	MidPrice     float64   `json:"midPrice"`
+	Spread       float64   `json:"spread"`
+	SpreadBps    float64   `json:"spreadBps"`
+	DepthBps     float64   `json:"depthBps"`
+	BidDepth     float64   `json:"bidDepth"`  // Quote value of bids within DepthBps of the mid price
+	AskDepth     float64   `json:"askDepth"`  // Quote value of asks within DepthBps of the mid price
+	Imbalance    float64   `json:"imbalance"` // (BidDepth - AskDepth) / (BidDepth + AskDepth), from -1 to 1
+	LastUpdateID int64     `json:"lastUpdateId"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// Market stream connection states
+const (
+	StreamConnected    = "CONNECTED"
+	StreamReconnecting = "RECONNECTING"
+	StreamDisconnected = "DISCONNECTED"
+)
+
+// StreamConnectionEvent reports a state change of a market stream connection
+type StreamConnectionEvent struct {
+	ConnectionID int       `json:"connectionId"`
+	State        string    `json:"state"`
+	Streams      int       `json:"streams"`
+	Attempt      int       `json:"attempt,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// StreamConnectionStatus is the current state of a market stream connection
+type StreamConnectionStatus struct {
+	ConnectionID int       `json:"connectionId"`
+	State        string    `json:"state"`
+	Streams      int       `json:"streams"`
+	ConnectedAt  time.Time `json:"connectedAt"`
+	Reconnects   int       `json:"reconnects"`
+	LastError    string    `json:"lastError,omitempty"`
+}
+
 // BinancePriceData represents processed price data from Binance
 type BinancePriceData struct {
 	LastPrice          float64
@@ -172,6 +458,141 @@ type BinancePriceData struct {
 	Volume             float64
 }
 
+// BinanceOrderResponse represents Binance order endpoint response
+type BinanceOrderResponse struct {
+	Symbol              string             `json:"symbol"`
+	OrderID             int64              `json:"orderId"`
+	ClientOrderID       string             `json:"clientOrderId"`
+	TransactTime        int64              `json:"transactTime"`
+	Time                int64              `json:"time"`
+	UpdateTime          int64              `json:"updateTime"`
+	Price               string             `json:"price"`
+	OrigQty             string             `json:"origQty"`
+	ExecutedQty         string             `json:"executedQty"`
+	CummulativeQuoteQty string             `json:"cummulativeQuoteQty"`
+	Status              string             `json:"status"`
+	TimeInForce         string             `json:"timeInForce"`
+	Type                string             `json:"type"`
+	Side                string             `json:"side"`
+	Fills               []BinanceOrderFill `json:"fills"`
+}
+
+// BinanceOrderFill represents a single fill of a Binance order
+type BinanceOrderFill struct {
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+}
+
+// BinanceAccountResponse represents Binance account information response
+type BinanceAccountResponse struct {
+	CanTrade    bool   `json:"canTrade"`
+	UpdateTime  int64  `json:"updateTime"`
+	AccountType string `json:"accountType"`
+	Balances    []struct {
+		Asset  string `json:"asset"`
+		Free   string `json:"free"`
+		Locked string `json:"locked"`
+	} `json:"balances"`
+}
+
+// BinanceMarginAccountResponse represents Binance cross margin account information response
+type BinanceMarginAccountResponse struct {
+	TradeEnabled bool `json:"tradeEnabled"`
+	UserAssets   []struct {
+		Asset    string `json:"asset"`
+		Free     string `json:"free"`
+		Locked   string `json:"locked"`
+		Borrowed string `json:"borrowed"`
+		Interest string `json:"interest"`
+		NetAsset string `json:"netAsset"`
+	} `json:"userAssets"`
+}
+
+// BinanceExchangeInfo represents Binance exchange information response
+type BinanceExchangeInfo struct {
+	Symbols []struct {
+		Symbol     string                   `json:"symbol"`
+		Status     string                   `json:"status"`
+		BaseAsset  string                   `json:"baseAsset"`
+		QuoteAsset string                   `json:"quoteAsset"`
+		Filters    []map[string]interface{} `json:"filters"`
+	} `json:"symbols"`
+}
+
+// SymbolFilters holds the trading rules Binance enforces on a symbol's orders.
+// Zero values mean the corresponding filter is not set.
+type SymbolFilters struct {
+	Symbol            string  `json:"symbol"`
+	Status            string  `json:"status"`
+	BaseAsset         string  `json:"baseAsset"`
+	QuoteAsset        string  `json:"quoteAsset"`
+	TickSize          float64 `json:"tickSize"`
+	MinPrice          float64 `json:"minPrice"`
+	MaxPrice          float64 `json:"maxPrice"`
+	StepSize          float64 `json:"stepSize"`
+	MinQty            float64 `json:"minQty"`
+	MaxQty            float64 `json:"maxQty"`
+	MarketStepSize    float64 `json:"marketStepSize"`
+	MarketMinQty      float64 `json:"marketMinQty"`
+	MarketMaxQty      float64 `json:"marketMaxQty"`
+	MinNotional       float64 `json:"minNotional"`
+	MinNotionalMarket bool    `json:"minNotionalMarket"` // Whether MinNotional applies to market orders
+	MaxNotional       float64 `json:"maxNotional"`
+	MaxNotionalMarket bool    `json:"maxNotionalMarket"` // Whether MaxNotional applies to market orders
+}
+
+// BinanceAPIError represents an error payload returned by Binance
+type BinanceAPIError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// Margin side effects of an order, matching Binance sideEffectType values
+const (
+	SideEffectMarginBuy = "MARGIN_BUY" // Borrow the asset being sold when the balance is short
+	SideEffectAutoRepay = "AUTO_REPAY" // Repay borrowed assets with the proceeds of the order
+)
+
+// OrderRequest represents an order to be sent to an exchange
+type OrderRequest struct {
+	Symbol         string  `json:"symbol"`
+	Side           string  `json:"side"` // BUY or SELL
+	Type           string  `json:"type"` // MARKET or LIMIT
+	Quantity       float64 `json:"quantity"`
+	Price          float64 `json:"price,omitempty"`
+	TimeInForce    string  `json:"timeInForce,omitempty"`
+	ClientOrderID  string  `json:"clientOrderId,omitempty"`
+	SideEffectType string  `json:"sideEffectType,omitempty"` // Empty for spot orders
+}
+
+// Order represents processed order state from an exchange
+type Order struct {
+	OrderID         int64     `json:"orderId"`
+	ClientOrderID   string    `json:"clientOrderId"`
+	Symbol          string    `json:"symbol"`
+	Side            string    `json:"side"`
+	Type            string    `json:"type"`
+	Status          string    `json:"status"`
+	Price           float64   `json:"price"`
+	Quantity        float64   `json:"quantity"`
+	ExecutedQty     float64   `json:"executedQty"`
+	AvgPrice        float64   `json:"avgPrice"`
+	Commission      float64   `json:"commission"`
+	CommissionAsset string    `json:"commissionAsset,omitempty"`
+	SideEffectType  string    `json:"sideEffectType,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// Balance represents an asset balance on an exchange account
+type Balance struct {
+	Asset    string  `json:"asset"`
+	Free     float64 `json:"free"`
+	Locked   float64 `json:"locked"`
+	Borrowed float64 `json:"borrowed,omitempty"`
+}
+
 // APIResponse represents a standard API response
 type APIResponse struct {
 	Success bool        `json:"success"`
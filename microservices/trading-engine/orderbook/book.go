@@ -0,0 +1,157 @@
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"trading-engine/models"
+)
+
+var (
+	// ErrSequenceGap reports a diff that does not continue from the last applied update
+	ErrSequenceGap = errors.New("order book update sequence gap")
+
+	// ErrNotSynced reports a diff received before the book was bootstrapped from a snapshot
+	ErrNotSynced = errors.New("order book is not synced")
+)
+
+// Book is the local order book of a symbol, bootstrapped from a depth snapshot
+// and kept current by applying diff updates in update ID order
+type Book struct {
+	symbol       string
+	bids         map[float64]float64
+	asks         map[float64]float64
+	lastUpdateID int64
+	synced       bool
+	updatedAt    time.Time
+	mu           sync.RWMutex
+}
+
+// NewBook creates an empty, unsynced order book
+func NewBook(symbol string) *Book {
+	return &Book{
+		symbol: symbol,
+		bids:   make(map[float64]float64),
+		asks:   make(map[float64]float64),
+	}
+}
+
+// Reset replaces the book with a snapshot and marks it synced
+func (b *Book) Reset(snapshot *models.DepthUpdate, at time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = make(map[float64]float64, len(snapshot.Bids))
+	b.asks = make(map[float64]float64, len(snapshot.Asks))
+	applyLevels(b.bids, snapshot.Bids)
+	applyLevels(b.asks, snapshot.Asks)
+	b.lastUpdateID = snapshot.FinalUpdateID
+	b.synced = true
+	b.updatedAt = at
+}
+
+// Apply applies a diff update. Diffs already contained in the book are ignored.
+// A diff that skips update IDs returns ErrSequenceGap and leaves the book unsynced
+// until the next Reset.
+func (b *Book) Apply(update models.DepthUpdate, at time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.synced {
+		return ErrNotSynced
+	}
+
+	// Events buffered while the snapshot was fetched may predate it
+	if update.FinalUpdateID <= b.lastUpdateID {
+		return nil
+	}
+
+	// The diff must cover the update right after the last one applied
+	if update.FirstUpdateID > b.lastUpdateID+1 {
+		b.synced = false
+		return fmt.Errorf("%w: expected update %d, got %d-%d", ErrSequenceGap, b.lastUpdateID+1, update.FirstUpdateID, update.FinalUpdateID)
+	}
+
+	applyLevels(b.bids, update.Bids)
+	applyLevels(b.asks, update.Asks)
+	b.lastUpdateID = update.FinalUpdateID
+	b.updatedAt = at
+
+	return nil
+}
+
+// applyLevels sets the quantity of each price level, removing levels with no quantity
+func applyLevels(side map[float64]float64, levels []models.PriceLevel) {
+	for _, level := range levels {
+		if level.Quantity == 0 {
+			delete(side, level.Price)
+		} else {
+			side[level.Price] = level.Quantity
+		}
+	}
+}
+
+// Synced reports whether the book reflects the exchange
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// Summary returns the top of book and the liquidity within depthBps of the mid price.
+// It returns false while the book is unsynced or either side is empty.
+func (b *Book) Summary(depthBps float64) (models.OrderBookSummary, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.synced || len(b.bids) == 0 || len(b.asks) == 0 {
+		return models.OrderBookSummary{}, false
+	}
+
+	var bestBid, bestAsk float64
+	for price := range b.bids {
+		if price > bestBid {
+			bestBid = price
+		}
+	}
+	for price := range b.asks {
+		if bestAsk == 0 || price < bestAsk {
+			bestAsk = price
+		}
+	}
+
+	mid := (bestBid + bestAsk) / 2
+	summary := models.OrderBookSummary{
+		Symbol:       b.symbol,
+		BestBid:      bestBid,
+		BestAsk:      bestAsk,
+		MidPrice:     mid,
+		Spread:       bestAsk - bestBid,
+		SpreadBps:    (bestAsk - bestBid) / mid * 10000,
+		DepthBps:     depthBps,
+		LastUpdateID: b.lastUpdateID,
+		Timestamp:    b.updatedAt,
+	}
+
+	// Sum the quote value resting within the band around the mid price
+	floor := mid * (1 - depthBps/10000)
+	ceiling := mid * (1 + depthBps/10000)
+	for price, quantity := range b.bids {
+		if price >= floor {
+			summary.BidDepth += price * quantity
+		}
+	}
+	for price, quantity := range b.asks {
+		if price <= ceiling {
+			summary.AskDepth += price * quantity
+		}
+	}
+
+	if total := summary.BidDepth + summary.AskDepth; total > 0 {
+		summary.Imbalance = (summary.BidDepth - summary.AskDepth) / total
+	}
+
+	return summary, true
+}
@@ -0,0 +1,153 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"trading-engine/models"
+)
+
+// diff returns a depth diff covering update IDs first to final that sets one bid level
+func diff(first, final int64, bidPrice, bidQty float64) models.DepthUpdate {
+	return models.DepthUpdate{
+		Symbol:        "BTCUSDT",
+		FirstUpdateID: first,
+		FinalUpdateID: final,
+		Bids:          []models.PriceLevel{{Price: bidPrice, Quantity: bidQty}},
+	}
+}
+
+// snapshot returns a depth snapshot as of update ID lastUpdateID
+func snapshot(lastUpdateID int64) *models.DepthUpdate {
+	return &models.DepthUpdate{
+		Symbol:        "BTCUSDT",
+		FinalUpdateID: lastUpdateID,
+		Bids:          []models.PriceLevel{{Price: 100, Quantity: 1}},
+		Asks:          []models.PriceLevel{{Price: 101, Quantity: 1}},
+	}
+}
+
+func TestBookApply(t *testing.T) {
+	tests := []struct {
+		name         string
+		updates      []models.DepthUpdate
+		wantErr      error
+		wantSynced   bool
+		wantUpdateID int64
+		wantBestBid  float64
+	}{
+		{
+			name:         "contiguous diffs",
+			updates:      []models.DepthUpdate{diff(11, 12, 100.5, 2), diff(13, 15, 100.8, 1)},
+			wantSynced:   true,
+			wantUpdateID: 15,
+			wantBestBid:  100.8,
+		},
+		{
+			name:         "first diff straddles the snapshot",
+			updates:      []models.DepthUpdate{diff(8, 12, 100.5, 2)},
+			wantSynced:   true,
+			wantUpdateID: 12,
+			wantBestBid:  100.5,
+		},
+		{
+			name:         "diffs older than the snapshot are ignored",
+			updates:      []models.DepthUpdate{diff(5, 9, 100.9, 1), diff(9, 10, 100.9, 1)},
+			wantSynced:   true,
+			wantUpdateID: 10,
+			wantBestBid:  100,
+		},
+		{
+			name:         "removed level",
+			updates:      []models.DepthUpdate{diff(11, 11, 100.5, 2), diff(12, 12, 100.5, 0)},
+			wantSynced:   true,
+			wantUpdateID: 12,
+			wantBestBid:  100,
+		},
+		{
+			name:         "gap after the snapshot",
+			updates:      []models.DepthUpdate{diff(12, 13, 100.5, 2)},
+			wantErr:      ErrSequenceGap,
+			wantUpdateID: 10,
+		},
+		{
+			name:         "gap between diffs",
+			updates:      []models.DepthUpdate{diff(11, 12, 100.5, 2), diff(14, 15, 100.8, 1)},
+			wantErr:      ErrSequenceGap,
+			wantUpdateID: 12,
+		},
+		{
+			name: "diffs after a gap are refused",
+			updates: []models.DepthUpdate{
+				diff(11, 12, 100.5, 2), diff(14, 15, 100.8, 1), diff(16, 17, 100.9, 1),
+			},
+			wantErr:      ErrNotSynced,
+			wantUpdateID: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := NewBook("BTCUSDT")
+			book.Reset(snapshot(10), time.Now())
+
+			// Only the error of the last diff is checked
+			var err error
+			for _, update := range tt.updates {
+				err = book.Apply(update, time.Now())
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("last Apply error = %v, want %v", err, tt.wantErr)
+			}
+			if book.Synced() != tt.wantSynced {
+				t.Errorf("Synced = %v, want %v", book.Synced(), tt.wantSynced)
+			}
+			if book.lastUpdateID != tt.wantUpdateID {
+				t.Errorf("last update ID = %d, want %d", book.lastUpdateID, tt.wantUpdateID)
+			}
+			if !tt.wantSynced {
+				return
+			}
+
+			summary, ok := book.Summary(50)
+			if !ok {
+				t.Fatal("Summary unavailable on a synced book")
+			}
+			if summary.BestBid != tt.wantBestBid {
+				t.Errorf("best bid = %v, want %v", summary.BestBid, tt.wantBestBid)
+			}
+		})
+	}
+}
+
+func TestBookApplyBeforeSnapshot(t *testing.T) {
+	book := NewBook("BTCUSDT")
+	if err := book.Apply(diff(1, 2, 100, 1), time.Now()); !errors.Is(err, ErrNotSynced) {
+		t.Fatalf("Apply error = %v, want %v", err, ErrNotSynced)
+	}
+	if _, ok := book.Summary(50); ok {
+		t.Error("Summary available before the snapshot")
+	}
+}
+
+func TestBookResetResyncsAfterGap(t *testing.T) {
+	book := NewBook("BTCUSDT")
+	book.Reset(snapshot(10), time.Now())
+
+	if err := book.Apply(diff(20, 21, 100.5, 2), time.Now()); !errors.Is(err, ErrSequenceGap) {
+		t.Fatalf("Apply error = %v, want %v", err, ErrSequenceGap)
+	}
+
+	// A fresh snapshot replaces the book, the diffs continuing from it apply again
+	book.Reset(snapshot(21), time.Now())
+	if err := book.Apply(diff(22, 23, 100.7, 1), time.Now()); err != nil {
+		t.Fatalf("Apply after resync failed: %v", err)
+	}
+
+	summary, ok := book.Summary(50)
+	if !ok || summary.BestBid != 100.7 || summary.LastUpdateID != 23 {
+		t.Errorf("summary = %+v, want best bid 100.7 as of update 23", summary)
+	}
+}
@@ -0,0 +1,157 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/clock"
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+const (
+	// depthUpdateBufferSize holds the diffs that arrive while a snapshot is fetched
+	depthUpdateBufferSize = 1000
+
+	// resyncDelay spaces snapshot requests, which carry a heavy request weight
+	resyncDelay = time.Second
+)
+
+// Manager maintains local order books for a set of symbols from depth snapshots and diff streams
+type Manager struct {
+	config   *config.OrderBookConfig
+	client   *binance.Client
+	wsClient *binance.WebSocketClient
+	clock    clock.Clock
+	logger   *logger.Logger
+	books    map[string]*Book
+	stopChan chan struct{}
+	stopOnce sync.Once
+	mu       sync.RWMutex
+}
+
+// NewManager creates an order book manager
+func NewManager(cfg *config.OrderBookConfig, client *binance.Client, wsClient *binance.WebSocketClient, clk clock.Clock, log *logger.Logger) *Manager {
+	return &Manager{
+		config:   cfg,
+		client:   client,
+		wsClient: wsClient,
+		clock:    clk,
+		logger:   log,
+		books:    make(map[string]*Book),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start subscribes to the depth streams of the symbols and keeps their books in sync until stopped
+func (m *Manager) Start(ctx context.Context, symbols []string) {
+	for _, symbol := range symbols {
+		m.mu.Lock()
+		if _, exists := m.books[symbol]; exists {
+			m.mu.Unlock()
+			continue
+		}
+		book := NewBook(symbol)
+		m.books[symbol] = book
+		m.mu.Unlock()
+
+		// Subscribe before the snapshot so no diff between the two is missed
+		updates := make(chan models.DepthUpdate, depthUpdateBufferSize)
+		if err := m.wsClient.SubscribeDepth(symbol, updates); err != nil {
+			m.logger.Error("Failed to subscribe to order book of %s: %v", symbol, err)
+			continue
+		}
+
+		go m.maintain(ctx, book, updates)
+	}
+
+	m.logger.WithFields(map[string]interface{}{
+		"symbols":   len(symbols),
+		"depth_bps": m.config.DepthBps,
+	}).Info("Started order book maintenance")
+}
+
+// Stop stops maintaining the order books
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+}
+
+// Summary returns the liquidity of a symbol's book within the configured depth band
+func (m *Manager) Summary(symbol string) (models.OrderBookSummary, bool) {
+	m.mu.RLock()
+	book, exists := m.books[symbol]
+	m.mu.RUnlock()
+
+	if !exists {
+		return models.OrderBookSummary{}, false
+	}
+	return book.Summary(m.config.DepthBps)
+}
+
+// maintain bootstraps a book from a snapshot and applies diffs, resyncing whenever the sequence breaks
+func (m *Manager) maintain(ctx context.Context, book *Book, updates <-chan models.DepthUpdate) {
+	for {
+		err := m.bootstrap(ctx, book)
+		if err == nil {
+			err = m.follow(ctx, book, updates)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		default:
+		}
+
+		m.logger.WithFields(map[string]interface{}{
+			"symbol": book.symbol,
+			"error":  err.Error(),
+		}).Warn("Order book out of sync, resyncing from snapshot")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-m.clock.After(resyncDelay):
+		}
+	}
+}
+
+// bootstrap resets a book from a fresh depth snapshot
+func (m *Manager) bootstrap(ctx context.Context, book *Book) error {
+	snapshot, err := m.client.FetchDepthSnapshot(ctx, book.symbol, m.config.SnapshotLimit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch snapshot: %w", err)
+	}
+
+	book.Reset(snapshot, m.clock.Now())
+
+	m.logger.WithFields(map[string]interface{}{
+		"symbol":         book.symbol,
+		"last_update_id": snapshot.FinalUpdateID,
+	}).Debug("Order book bootstrapped")
+
+	return nil
+}
+
+// follow applies diffs to a synced book until the sequence breaks or the manager stops
+func (m *Manager) follow(ctx context.Context, book *Book, updates <-chan models.DepthUpdate) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.stopChan:
+			return fmt.Errorf("order book manager stopped")
+		case update := <-updates:
+			if err := book.Apply(update, m.clock.Now()); err != nil {
+				return err
+			}
+		}
+	}
+}
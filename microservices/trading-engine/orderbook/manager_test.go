@@ -0,0 +1,87 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"trading-engine/binance"
+	"trading-engine/clock"
+	"trading-engine/config"
+	"trading-engine/logger"
+	"trading-engine/models"
+)
+
+func TestManagerResyncsFromSnapshotAfterGap(t *testing.T) {
+	// Each snapshot is 10 updates further along than the previous one
+	var mu sync.Mutex
+	snapshots := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/depth" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		mu.Lock()
+		snapshots++
+		lastUpdateID := snapshots * 10
+		mu.Unlock()
+		fmt.Fprintf(w, `{"lastUpdateId":%d,"bids":[["100","1"]],"asks":[["101","1"]]}`, lastUpdateID)
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger("orderbook-test", logger.ERROR, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	client := binance.NewClient(&config.BinanceConfig{
+		APIBaseURL:       server.URL,
+		RateLimit:        6000,
+		RetryAttempts:    1,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	}, log)
+
+	manager := NewManager(&config.OrderBookConfig{SnapshotLimit: 100, DepthBps: 50}, client, nil, clock.New(), log)
+	defer manager.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	book := NewBook("BTCUSDT")
+	updates := make(chan models.DepthUpdate, depthUpdateBufferSize)
+	go manager.maintain(ctx, book, updates)
+
+	waitFor(t, "the first snapshot", func() bool { return book.Synced() })
+
+	// Update 11 continues the first snapshot, 13 skips 12 and forces a new snapshot as of 20
+	updates <- diff(11, 11, 100.5, 1)
+	updates <- diff(13, 13, 100.6, 1)
+	waitFor(t, "the resync", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return snapshots == 2 && book.Synced()
+	})
+
+	updates <- diff(21, 22, 100.8, 1)
+	waitFor(t, "the diff after the resync", func() bool {
+		summary, ok := book.Summary(50)
+		return ok && summary.LastUpdateID == 22 && summary.BestBid == 100.8
+	})
+}
+
+// waitFor polls a condition until it holds or the test times out
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trading-engine/events"
+)
+
+// stateSaveInterval is how often the engine state is saved between trading events, so open
+// positions keep their moved stops and unrealized P&L across a crash
+const stateSaveInterval = 5 * time.Second
+
+// restoreEngineState resumes the engine from the state saved before the last shutdown or crash
+func (app *Application) restoreEngineState() error {
+	if app.database == nil {
+		return nil
+	}
+
+	state, err := app.database.GetEngineState()
+	if err != nil {
+		return fmt.Errorf("failed to load engine state: %w", err)
+	}
+	if state == nil {
+		app.logger.Info("No saved engine state, starting fresh")
+		return nil
+	}
+
+	if state.Positions, err = app.database.GetActivePositions(); err != nil {
+		return fmt.Errorf("failed to load active positions: %w", err)
+	}
+	if state.Settings, err = app.database.GetLatestTradingSettings(); err != nil {
+		return fmt.Errorf("failed to load trading settings: %w", err)
+	}
+
+	return app.engine.RestoreState(state)
+}
+
+// persistState saves fills, positions and settings as they happen and the whole engine state
+// after each of them and periodically. Both run in one goroutine so that a periodic save never
+// revives a position closed after it.
+func (app *Application) persistState(ctx context.Context, ch <-chan events.Event) {
+	// Record the settings the engine starts with, so that a restart finds them
+	settings := app.engine.GetTradingState().Settings
+	if err := app.database.SaveTradingSettings(&settings); err != nil {
+		app.logger.Error("Failed to save settings to database: %v", err)
+	}
+
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-ch:
+			app.persistEvent(event)
+			app.saveEngineState(ctx)
+		case <-ticker.C:
+			app.saveEngineState(ctx)
+		}
+	}
+}
+
+// saveEngineState saves the open positions and the rest of the engine state to the database,
+// and the trading state to the cache
+func (app *Application) saveEngineState(ctx context.Context) {
+	state, err := app.engine.State(ctx)
+	if err != nil {
+		app.logger.Error("Failed to read engine state: %v", err)
+		return
+	}
+
+	if err := app.database.SyncPositions(state.Positions); err != nil {
+		app.logger.Error("Failed to save positions to database: %v", err)
+	}
+	if err := app.database.SaveEngineState(state); err != nil {
+		app.logger.Error("Failed to save engine state to database: %v", err)
+	}
+
+	if app.cache != nil {
+		if err := app.cache.SetTradingState(ctx, app.engine.GetTradingState()); err != nil {
+			app.logger.Warn("Failed to cache trading state: %v", err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package strategy
+
+import "trading-engine/models"
+
+// SignalName is the name of the default strategy
+const SignalName = "signal"
+
+// Signal follows the overall signal of the technical analysis, combining the EMA crossovers,
+// RSI, MACD, VWAP and the other indicators. It enters when the signal is confident enough
+// and leaves positions to the engine's stop loss, take profit and hold-time exits.
+type Signal struct{}
+
+// NewSignal creates the signal strategy
+func NewSignal() *Signal {
+	return &Signal{}
+}
+
+// Name returns the name of the strategy
+func (s *Signal) Name() string {
+	return SignalName
+}
+
+// OnCandle opens a long on buy signals and a short on sell signals. While the settings allow
+// more entries, signals renewed in the direction of the position add to it.
+func (s *Signal) OnCandle(market Market) []Intent {
+	if market.Technical == nil {
+		return nil
+	}
+	if market.Position != nil && market.Position.Entries >= market.Settings.ScalingFactor {
+		return nil
+	}
+	if market.Technical.Confidence < market.Settings.MinConfidence {
+		return nil
+	}
+
+	switch market.Technical.Signal {
+	case "STRONG_BUY", "BUY":
+		if market.Position == nil || market.Position.Quantity > 0 {
+			return []Intent{{Action: ActionOpenLong, Symbol: market.Symbol, Reason: market.Technical.Signal}}
+		}
+	case "STRONG_SELL", "SELL":
+		if market.Position == nil || market.Position.Quantity < 0 {
+			return []Intent{{Action: ActionOpenShort, Symbol: market.Symbol, Reason: market.Technical.Signal}}
+		}
+	}
+	return nil
+}
+
+// OnTick leaves exits to the engine
+func (s *Signal) OnTick(tick Tick) []Intent {
+	return nil
+}
+
+// OnFill has nothing to do on fills
+func (s *Signal) OnFill(trade models.Trade) []Intent {
+	return nil
+}
@@ -0,0 +1,103 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"trading-engine/models"
+)
+
+// Action is what an intent asks the engine to do
+type Action string
+
+const (
+	ActionOpenLong  Action = "OPEN_LONG"
+	ActionOpenShort Action = "OPEN_SHORT"
+	ActionClose     Action = "CLOSE"
+)
+
+// Intent is a trading decision of a strategy. The engine sizes entries and applies its risk
+// limits, cooldowns and trend filter before acting on it.
+type Intent struct {
+	Action Action
+	Symbol string
+	Reason string // Recorded as the signal of the resulting trade
+}
+
+// Market is what a strategy sees of a symbol each trading cycle: the latest, possibly still
+// open, candle of the signal timeframe and its analysis
+type Market struct {
+	Symbol    string
+	Candle    models.Candle
+	Technical *models.TechnicalAnalysis
+	Position  *models.Position // Nil without an open position
+	Settings  models.TradingSettings
+}
+
+// Tick is the current price of a symbol with an open position, seen on each position check
+type Tick struct {
+	Symbol   string
+	Price    float64
+	Time     time.Time
+	Position models.Position
+}
+
+// Strategy decides when to enter and leave positions. The engine calls it from a single
+// goroutine at a time.
+type Strategy interface {
+	// Name returns the name the strategy is registered under
+	Name() string
+
+	// OnCandle is called for every active symbol with an analysis each trading cycle
+	OnCandle(market Market) []Intent
+
+	// OnTick is called for every open position each time exits are checked
+	OnTick(tick Tick) []Intent
+
+	// OnFill is called with the trade recorded for each filled entry or exit
+	OnFill(trade models.Trade) []Intent
+}
+
+// Factory creates a strategy
+type Factory func() Strategy
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string]Factory{
+		SignalName: func() Strategy { return NewSignal() },
+	}
+)
+
+// Register makes a strategy available by name, replacing any registered under the same name
+func Register(name string, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry[name] = factory
+}
+
+// New creates the strategy registered under name
+func New(name string) (Strategy, error) {
+	registryMutex.RLock()
+	factory, exists := registry[name]
+	registryMutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown strategy %q, registered strategies are %v", name, Names())
+	}
+	return factory(), nil
+}
+
+// Names returns the registered strategy names in order
+func Names() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -6,15 +6,24 @@ import (
 	"sync"
 	"time"
 
+	"trading-engine/clock"
 	"trading-engine/models"
 	"trading-engine/utils"
 )
 
 // Analyzer performs technical analysis calculations
 type Analyzer struct {
-	mu     sync.RWMutex
-	cache  map[string]*AnalysisResult
-	config *Config
+	mu         sync.RWMutex
+	cache      map[string]*AnalysisResult
+	states     map[string]*seriesState
+	config     *Config
+	clock      clock.Clock
+	orderBooks OrderBookSource
+}
+
+// OrderBookSource supplies the liquidity of a symbol's local order book
+type OrderBookSource interface {
+	Summary(symbol string) (models.OrderBookSummary, bool)
 }
 
 // Config holds technical analysis configuration
@@ -31,39 +40,61 @@ type Config struct {
 
 // AnalysisResult holds the result of technical analysis
 type AnalysisResult struct {
-	Symbol         string        `json:"symbol"`
-	Timestamp      time.Time     `json:"timestamp"`
-	Price          float64       `json:"price"`
-	Indicators     *Indicators   `json:"indicators"`
-	Signals        *Signals      `json:"signals"`
-	Confidence     int           `json:"confidence"`
-	TrendDirection string        `json:"trend_direction"`
-	SwingLevels    *SwingLevels  `json:"swing_levels"`
-	PriceTargets   *PriceTargets `json:"price_targets"`
+	Symbol         string                   `json:"symbol"`
+	Timeframe      string                   `json:"timeframe"`
+	Timestamp      time.Time                `json:"timestamp"`
+	Price          float64                  `json:"price"`
+	Indicators     *Indicators              `json:"indicators"`
+	Signals        *Signals                 `json:"signals"`
+	Confidence     int                      `json:"confidence"`
+	TrendDirection string                   `json:"trend_direction"`
+	SwingLevels    *SwingLevels             `json:"swing_levels"`
+	PriceTargets   *PriceTargets            `json:"price_targets"`
+	OrderBook      *models.OrderBookSummary `json:"order_book,omitempty"`
 }
 
 // Indicators holds all technical indicators
 type Indicators struct {
-	RSI        float64 `json:"rsi"`
-	EMA9       float64 `json:"ema9"`
-	EMA21      float64 `json:"ema21"`
-	EMA50      float64 `json:"ema50"`
-	EMA200     float64 `json:"ema200"`
-	VWAP       float64 `json:"vwap"`
-	MACD       float64 `json:"macd"`
-	MACDSignal float64 `json:"macd_signal"`
-	Volume     float64 `json:"volume"`
-	AvgVolume  float64 `json:"avg_volume"`
+	RSI          float64 `json:"rsi"`
+	EMA9         float64 `json:"ema9"`
+	EMA21        float64 `json:"ema21"`
+	EMA50        float64 `json:"ema50"`
+	EMA200       float64 `json:"ema200"`
+	VWAP         float64 `json:"vwap"`
+	MACD         float64 `json:"macd"`
+	MACDSignal   float64 `json:"macd_signal"`
+	MACDHist     float64 `json:"macd_hist"`
+	MACDPrevHist float64 `json:"macd_prev_hist"` // Histogram of the previous candle, for crossovers
+	Volume       float64 `json:"volume"`
+	AvgVolume    float64 `json:"avg_volume"`
+	ATR          float64 `json:"atr"`
+	BBUpper      float64 `json:"bb_upper"`
+	BBMiddle     float64 `json:"bb_middle"`
+	BBLower      float64 `json:"bb_lower"`
+	BBBandwidth  float64 `json:"bb_bandwidth"`
+	BBPercentB   float64 `json:"bb_percent_b"`
+	StochRSIK    float64 `json:"stoch_rsi_k"`
+	StochRSID    float64 `json:"stoch_rsi_d"`
+	ADX          float64 `json:"adx"`
+	PlusDI       float64 `json:"plus_di"`
+	MinusDI      float64 `json:"minus_di"`
+	OBV          float64 `json:"obv"`
+	SuperTrend   float64 `json:"super_trend"`
+	SuperTrendUp bool    `json:"super_trend_up"`
 }
 
 // Signals holds trading signals
 type Signals struct {
-	Overall string `json:"overall"`
-	RSI     string `json:"rsi"`
-	EMA     string `json:"ema"`
-	VWAP    string `json:"vwap"`
-	Volume  string `json:"volume"`
-	Trend   string `json:"trend"`
+	Overall    string `json:"overall"`
+	RSI        string `json:"rsi"`
+	EMA        string `json:"ema"`
+	VWAP       string `json:"vwap"`
+	Volume     string `json:"volume"`
+	Trend      string `json:"trend"`
+	MACD       string `json:"macd"`
+	Bollinger  string `json:"bollinger"`
+	StochRSI   string `json:"stoch_rsi"`
+	SuperTrend string `json:"super_trend"`
 }
 
 // SwingLevels holds swing high and low levels
@@ -81,6 +112,11 @@ type PriceTargets struct {
 
 // NewAnalyzer creates a new technical analyzer
 func NewAnalyzer(config *Config) *Analyzer {
+	return NewAnalyzerWithClock(config, clock.New())
+}
+
+// NewAnalyzerWithClock creates a new technical analyzer that timestamps and expires results with the given clock
+func NewAnalyzerWithClock(config *Config, clk clock.Clock) *Analyzer {
 	if config == nil {
 		config = &Config{
 			RSIPeriod:     14,
@@ -96,20 +132,30 @@ func NewAnalyzer(config *Config) *Analyzer {
 
 	return &Analyzer{
 		cache:  make(map[string]*AnalysisResult),
+		states: make(map[string]*seriesState),
 		config: config,
+		clock:  clk,
 	}
 }
 
-// Analyze performs technical analysis on candlestick data
-func (a *Analyzer) Analyze(ctx context.Context, symbol string, candles []models.Candle) (*AnalysisResult, error) {
+// SetOrderBookSource attaches order book liquidity to subsequent analysis results
+func (a *Analyzer) SetOrderBookSource(source OrderBookSource) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.orderBooks = source
+}
+
+// Analyze performs technical analysis on candlestick data of a symbol at the given timeframe
+func (a *Analyzer) Analyze(ctx context.Context, symbol, timeframe string, candles []models.Candle) (*AnalysisResult, error) {
 	if len(candles) == 0 {
 		return nil, fmt.Errorf("no candlestick data provided")
 	}
 
 	// Check cache first
+	key := cacheKey(symbol, timeframe)
 	a.mu.RLock()
-	if cached, exists := a.cache[symbol]; exists {
-		if time.Since(cached.Timestamp) < a.config.CacheDuration {
+	if cached, exists := a.cache[key]; exists {
+		if a.clock.Since(cached.Timestamp) < a.config.CacheDuration {
 			a.mu.RUnlock()
 			return cached, nil
 		}
@@ -117,45 +163,37 @@ func (a *Analyzer) Analyze(ctx context.Context, symbol string, candles []models.
 	a.mu.RUnlock()
 
 	// Perform analysis
-	result, err := a.performAnalysis(ctx, symbol, candles)
+	result, err := a.performAnalysis(ctx, symbol, key, candles)
 	if err != nil {
 		return nil, err
 	}
+	result.Timeframe = timeframe
 
 	// Cache result
 	a.mu.Lock()
-	a.cache[symbol] = result
+	a.cache[key] = result
 	a.mu.Unlock()
 
 	return result, nil
 }
 
-// performAnalysis performs the actual technical analysis
-func (a *Analyzer) performAnalysis(ctx context.Context, symbol string, candles []models.Candle) (*AnalysisResult, error) {
+// performAnalysis performs the actual technical analysis, updating the incremental
+// indicators kept under key
+func (a *Analyzer) performAnalysis(ctx context.Context, symbol, key string, candles []models.Candle) (*AnalysisResult, error) {
 	if len(candles) < a.config.EMA200Period {
 		return nil, fmt.Errorf("insufficient data for analysis: need at least %d candles, got %d", a.config.EMA200Period, len(candles))
 	}
 
 	currentCandle := candles[len(candles)-1]
-	closePrices := extractClosePrices(candles)
 	highPrices := extractHighPrices(candles)
 	lowPrices := extractLowPrices(candles)
 	volumes := extractVolumes(candles)
 
-	// Calculate indicators
-	indicators := &Indicators{
-		RSI:       a.calculateRSI(closePrices, a.config.RSIPeriod),
-		EMA9:      a.calculateEMA(closePrices, a.config.EMA9Period),
-		EMA21:     a.calculateEMA(closePrices, a.config.EMA21Period),
-		EMA50:     a.calculateEMA(closePrices, a.config.EMA50Period),
-		EMA200:    a.calculateEMA(closePrices, a.config.EMA200Period),
-		VWAP:      a.calculateVWAP(candles, a.config.VWAPPeriod),
-		Volume:    currentCandle.Volume,
-		AvgVolume: a.calculateAverage(volumes, 20),
-	}
-
-	// Calculate MACD
-	indicators.MACD, indicators.MACDSignal = a.calculateMACD(closePrices, 12, 26, 9)
+	// Indicators are updated incrementally, VWAP and average volume use a recent window
+	indicators := a.seriesIndicators(key, candles)
+	indicators.VWAP = a.calculateVWAP(candles, a.config.VWAPPeriod)
+	indicators.Volume = currentCandle.Volume
+	indicators.AvgVolume = a.calculateAverage(volumes, 20)
 
 	// Calculate swing levels
 	swingLevels := a.calculateSwingLevels(highPrices, lowPrices, 20)
@@ -172,9 +210,9 @@ func (a *Analyzer) performAnalysis(ctx context.Context, symbol string, candles [
 	// Calculate price targets
 	priceTargets := a.calculatePriceTargets(currentCandle.Close, signals.Overall, swingLevels, indicators)
 
-	return &AnalysisResult{
+	result := &AnalysisResult{
 		Symbol:         symbol,
-		Timestamp:      time.Now(),
+		Timestamp:      a.clock.Now(),
 		Price:          currentCandle.Close,
 		Indicators:     indicators,
 		Signals:        signals,
@@ -182,58 +220,19 @@ func (a *Analyzer) performAnalysis(ctx context.Context, symbol string, candles [
 		TrendDirection: trendDirection,
 		SwingLevels:    swingLevels,
 		PriceTargets:   priceTargets,
-	}, nil
-}
-
-// calculateRSI calculates the Relative Strength Index
-func (a *Analyzer) calculateRSI(prices []float64, period int) float64 {
-	if len(prices) < period+1 {
-		return 50.0 // Neutral RSI
 	}
 
-	gains := make([]float64, 0, len(prices)-1)
-	losses := make([]float64, 0, len(prices)-1)
-
-	for i := 1; i < len(prices); i++ {
-		change := prices[i] - prices[i-1]
-		if change > 0 {
-			gains = append(gains, change)
-			losses = append(losses, 0)
-		} else {
-			gains = append(gains, 0)
-			losses = append(losses, -change)
+	// Attach liquidity from the local order book when one is maintained
+	a.mu.RLock()
+	orderBooks := a.orderBooks
+	a.mu.RUnlock()
+	if orderBooks != nil {
+		if summary, ok := orderBooks.Summary(symbol); ok {
+			result.OrderBook = &summary
 		}
 	}
 
-	if len(gains) < period {
-		return 50.0
-	}
-
-	avgGain := a.calculateAverage(gains[len(gains)-period:], period)
-	avgLoss := a.calculateAverage(losses[len(losses)-period:], period)
-
-	if avgLoss == 0 {
-		return 100.0
-	}
-
-	rs := avgGain / avgLoss
-	return 100 - (100 / (1 + rs))
-}
-
-// calculateEMA calculates the Exponential Moving Average
-func (a *Analyzer) calculateEMA(prices []float64, period int) float64 {
-	if len(prices) < period {
-		return a.calculateAverage(prices, len(prices))
-	}
-
-	multiplier := 2.0 / (float64(period) + 1.0)
-	ema := a.calculateAverage(prices[:period], period) // Start with SMA
-
-	for i := period; i < len(prices); i++ {
-		ema = (prices[i] * multiplier) + (ema * (1 - multiplier))
-	}
-
-	return ema
+	return result, nil
 }
 
 // calculateVWAP calculates the Volume Weighted Average Price
@@ -260,23 +259,6 @@ func (a *Analyzer) calculateVWAP(candles []models.Candle, period int) float64 {
 	return totalVolumePrice / totalVolume
 }
 
-// calculateMACD calculates the MACD indicator
-func (a *Analyzer) calculateMACD(prices []float64, fastPeriod, slowPeriod, signalPeriod int) (float64, float64) {
-	if len(prices) < slowPeriod {
-		return 0, 0
-	}
-
-	fastEMA := a.calculateEMA(prices, fastPeriod)
-	slowEMA := a.calculateEMA(prices, slowPeriod)
-	macd := fastEMA - slowEMA
-
-	// For MACD signal, we need more sophisticated calculation
-	// This is a simplified version
-	macdSignal := macd * 0.9 // Simplified signal line
-
-	return macd, macdSignal
-}
-
 // calculateSwingLevels calculates swing high and low levels
 func (a *Analyzer) calculateSwingLevels(highs, lows []float64, lookback int) *SwingLevels {
 	if len(highs) < lookback || len(lows) < lookback {
@@ -302,8 +284,8 @@ func (a *Analyzer) calculateSwingLevels(highs, lows []float64, lookback int) *Sw
 	}
 
 	return &SwingLevels{
-		SwingHigh: utils.RoundToDecimals(swingHigh, 2),
-		SwingLow:  utils.RoundToDecimals(swingLow, 2),
+		SwingHigh: swingHigh,
+		SwingLow:  swingLow,
 	}
 }
 
@@ -361,6 +343,45 @@ func (a *Analyzer) generateSignals(currentPrice float64, indicators *Indicators,
 		signals.Trend = "SIDEWAYS"
 	}
 
+	// MACD signals, a crossover is the histogram changing sign on the latest candle
+	switch {
+	case indicators.MACDPrevHist <= 0 && indicators.MACDHist > 0:
+		signals.MACD = "BULLISH_CROSS"
+	case indicators.MACDPrevHist >= 0 && indicators.MACDHist < 0:
+		signals.MACD = "BEARISH_CROSS"
+	case indicators.MACDHist > 0:
+		signals.MACD = "BULLISH"
+	case indicators.MACDHist < 0:
+		signals.MACD = "BEARISH"
+	default:
+		signals.MACD = "NEUTRAL"
+	}
+
+	// Bollinger signals, closes outside the bands tend to revert like VWAP deviations
+	if indicators.BBPercentB < 0 {
+		signals.Bollinger = "BELOW"
+	} else if indicators.BBPercentB > 1 {
+		signals.Bollinger = "ABOVE"
+	} else {
+		signals.Bollinger = "INSIDE"
+	}
+
+	// Stochastic RSI signals
+	if indicators.StochRSIK < 20 {
+		signals.StochRSI = "OVERSOLD"
+	} else if indicators.StochRSIK > 80 {
+		signals.StochRSI = "OVERBOUGHT"
+	} else {
+		signals.StochRSI = "NEUTRAL"
+	}
+
+	// SuperTrend signals
+	if indicators.SuperTrendUp {
+		signals.SuperTrend = "UPTREND"
+	} else {
+		signals.SuperTrend = "DOWNTREND"
+	}
+
 	// Overall signal
 	signals.Overall = a.calculateOverallSignal(signals, indicators, currentPrice)
 
@@ -388,6 +409,12 @@ func (a *Analyzer) calculateOverallSignal(signals *Signals, indicators *Indicato
 	if signals.Volume == "HIGH" {
 		bullishCount++
 	}
+	if signals.MACD == "BULLISH_CROSS" {
+		bullishCount += 2 // A fresh crossover is weighted like the EMA alignment
+	}
+	if signals.Bollinger == "BELOW" {
+		bullishCount++
+	}
 
 	// Count bearish signals
 	if signals.RSI == "OVERBOUGHT" || signals.RSI == "BEARISH" {
@@ -405,6 +432,12 @@ func (a *Analyzer) calculateOverallSignal(signals *Signals, indicators *Indicato
 	if signals.Volume == "HIGH" {
 		bearishCount++
 	}
+	if signals.MACD == "BEARISH_CROSS" {
+		bearishCount += 2
+	}
+	if signals.Bollinger == "ABOVE" {
+		bearishCount++
+	}
 
 	// Determine overall signal
 	if bullishCount >= 4 {
@@ -450,6 +483,31 @@ func (a *Analyzer) calculateConfidence(signals *Signals, indicators *Indicators)
 		confidence += 5
 	}
 
+	// Adjust based on trend strength, ADX below 20 means there is no trend to follow
+	if indicators.ADX >= 25 && signals.Trend != "SIDEWAYS" {
+		confidence += 5
+	} else if indicators.ADX > 0 && indicators.ADX < 20 {
+		confidence -= 5
+	}
+
+	// Adjust based on SuperTrend and Stochastic RSI agreeing with the signal
+	switch signals.Overall {
+	case "STRONG_BUY", "BUY":
+		if signals.SuperTrend == "UPTREND" {
+			confidence += 5
+		}
+		if signals.StochRSI == "OVERSOLD" {
+			confidence += 5
+		}
+	case "STRONG_SELL", "SELL":
+		if signals.SuperTrend == "DOWNTREND" {
+			confidence += 5
+		}
+		if signals.StochRSI == "OVERBOUGHT" {
+			confidence += 5
+		}
+	}
+
 	return int(utils.ClampFloat64(float64(confidence), 0, 95))
 }
 
@@ -487,9 +545,10 @@ func (a *Analyzer) calculatePriceTargets(currentPrice float64, signal string, sw
 		takeProfit = currentPrice * 1.01
 	}
 
+	// Levels are kept unrounded as they place orders on symbols priced well below 1
 	return &PriceTargets{
-		StopLoss:   utils.RoundToDecimals(stopLoss, 2),
-		TakeProfit: utils.RoundToDecimals(takeProfit, 2),
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
 		RiskReward: riskReward,
 	}
 }
@@ -511,14 +570,6 @@ func (a *Analyzer) calculateAverage(values []float64, period int) float64 {
 	return sum / n
 }
 
-func extractClosePrices(candles []models.Candle) []float64 {
-	prices := make([]float64, len(candles))
-	for i, candle := range candles {
-		prices[i] = candle.Close
-	}
-	return prices
-}
-
 func extractHighPrices(candles []models.Candle) []float64 {
 	prices := make([]float64, len(candles))
 	for i, candle := range candles {
@@ -550,19 +601,24 @@ func (a *Analyzer) ClearCache() {
 	a.cache = make(map[string]*AnalysisResult)
 }
 
-// GetCachedAnalysis returns cached analysis if available
-func (a *Analyzer) GetCachedAnalysis(symbol string) (*AnalysisResult, bool) {
+// GetCachedAnalysis returns cached analysis of a symbol at the given timeframe if available
+func (a *Analyzer) GetCachedAnalysis(symbol, timeframe string) (*AnalysisResult, bool) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
-	result, exists := a.cache[symbol]
+	result, exists := a.cache[cacheKey(symbol, timeframe)]
 	if !exists {
 		return nil, false
 	}
 
-	if time.Since(result.Timestamp) > a.config.CacheDuration {
+	if a.clock.Since(result.Timestamp) > a.config.CacheDuration {
 		return nil, false
 	}
 
 	return result, true
 }
+
+// cacheKey returns the cache key of an analysis of a symbol at a timeframe
+func cacheKey(symbol, timeframe string) string {
+	return symbol + "@" + timeframe
+}
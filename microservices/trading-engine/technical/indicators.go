@@ -0,0 +1,192 @@
+package technical
+
+// EMA is an Exponential Moving Average updated one value at a time. It is seeded with the
+// SMA of its first period values, and reports the average of the values so far until then.
+type EMA struct {
+	period     int
+	multiplier float64
+	count      int
+	sum        float64
+	value      float64
+}
+
+// NewEMA creates an EMA over period values
+func NewEMA(period int) *EMA {
+	if period < 1 {
+		period = 1
+	}
+	return &EMA{
+		period:     period,
+		multiplier: 2.0 / (float64(period) + 1.0),
+	}
+}
+
+// Update adds the next value
+func (e *EMA) Update(value float64) {
+	e.value = e.Peek(value)
+	e.count++
+	if e.count <= e.period {
+		e.sum += value
+	}
+}
+
+// Peek returns the EMA as if value were the next one, without adding it
+func (e *EMA) Peek(value float64) float64 {
+	if e.count+1 <= e.period {
+		return (e.sum + value) / float64(e.count+1)
+	}
+	return value*e.multiplier + e.value*(1-e.multiplier)
+}
+
+// Value returns the current EMA
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+// Ready reports whether the EMA has been seeded with period values
+func (e *EMA) Ready() bool {
+	return e.count >= e.period
+}
+
+// RSI is the Relative Strength Index with Wilder smoothing, updated one price at a time.
+// The first average gain and loss are the SMA of the first period changes.
+type RSI struct {
+	period  int
+	last    float64
+	count   int // Prices seen
+	avgGain float64
+	avgLoss float64
+}
+
+// NewRSI creates an RSI over period price changes
+func NewRSI(period int) *RSI {
+	if period < 1 {
+		period = 1
+	}
+	return &RSI{period: period}
+}
+
+// Update adds the next price
+func (r *RSI) Update(price float64) {
+	r.avgGain, r.avgLoss = r.averages(price)
+	r.last = price
+	r.count++
+}
+
+// Peek returns the RSI as if price were the next one, without adding it
+func (r *RSI) Peek(price float64) float64 {
+	if r.count < r.period {
+		return 50.0 // Neutral RSI until period changes are known
+	}
+	avgGain, avgLoss := r.averages(price)
+	return rsiValue(avgGain, avgLoss)
+}
+
+// Value returns the current RSI
+func (r *RSI) Value() float64 {
+	if r.count <= r.period {
+		return 50.0
+	}
+	return rsiValue(r.avgGain, r.avgLoss)
+}
+
+// averages returns the average gain and loss after the change to price
+func (r *RSI) averages(price float64) (float64, float64) {
+	if r.count == 0 {
+		return 0, 0
+	}
+
+	var gain, loss float64
+	if change := price - r.last; change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	// Changes are summed until the first average, then smoothed
+	changes := r.count // Including this one
+	if changes <= r.period {
+		sumGain := r.avgGain*float64(changes-1) + gain
+		sumLoss := r.avgLoss*float64(changes-1) + loss
+		return sumGain / float64(changes), sumLoss / float64(changes)
+	}
+
+	period := float64(r.period)
+	return (r.avgGain*(period-1) + gain) / period, (r.avgLoss*(period-1) + loss) / period
+}
+
+// rsiValue converts average gain and loss into an RSI
+func rsiValue(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50.0
+		}
+		return 100.0
+	}
+	rs := avgGain / avgLoss
+	return 100 - (100 / (1 + rs))
+}
+
+// MACDValue holds the MACD line, its signal line and histogram
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// MACD is the Moving Average Convergence Divergence updated one price at a time. The signal
+// line is the EMA of the MACD line from the candle where the slow EMA is seeded.
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	value  MACDValue
+}
+
+// NewMACD creates a MACD with the given fast, slow and signal periods
+func NewMACD(fastPeriod, slowPeriod, signalPeriod int) *MACD {
+	return &MACD{
+		fast:   NewEMA(fastPeriod),
+		slow:   NewEMA(slowPeriod),
+		signal: NewEMA(signalPeriod),
+	}
+}
+
+// Update adds the next price
+func (m *MACD) Update(price float64) {
+	m.fast.Update(price)
+	m.slow.Update(price)
+	if !m.slow.Ready() {
+		return
+	}
+
+	macd := m.fast.Value() - m.slow.Value()
+	m.signal.Update(macd)
+	if m.signal.Ready() {
+		m.value = MACDValue{MACD: macd, Signal: m.signal.Value(), Histogram: macd - m.signal.Value()}
+	}
+}
+
+// Peek returns the MACD as if price were the next one, without adding it. It is zero until
+// the signal line is seeded.
+func (m *MACD) Peek(price float64) MACDValue {
+	if m.slow.count+1 < m.slow.period {
+		return MACDValue{}
+	}
+	macd := m.fast.Peek(price) - m.slow.Peek(price)
+	if m.signal.count+1 < m.signal.period {
+		return MACDValue{}
+	}
+	signal := m.signal.Peek(macd)
+	return MACDValue{MACD: macd, Signal: signal, Histogram: macd - signal}
+}
+
+// Value returns the current MACD
+func (m *MACD) Value() MACDValue {
+	return m.value
+}
+
+// Ready reports whether the signal line has been seeded
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
@@ -0,0 +1,224 @@
+package technical
+
+import (
+	"math"
+
+	"trading-engine/models"
+)
+
+// StochRSIValue holds the smoothed Stochastic RSI (%K) and its signal line (%D), from 0 to 100
+type StochRSIValue struct {
+	K float64
+	D float64
+}
+
+// StochRSI is the Stochastic oscillator applied to the RSI: where the RSI sits within its
+// range over a period, smoothed into %K and %D
+type StochRSI struct {
+	rsi  *RSI
+	rsis *window
+	raw  *window
+	k    *window
+}
+
+// NewStochRSI creates a Stochastic RSI over an RSI of rsiPeriod, a stochastic range of
+// period RSI values and kSmooth/dSmooth candle smoothing
+func NewStochRSI(rsiPeriod, period, kSmooth, dSmooth int) *StochRSI {
+	return &StochRSI{
+		rsi:  NewRSI(rsiPeriod),
+		rsis: newWindow(period),
+		raw:  newWindow(kSmooth),
+		k:    newWindow(dSmooth),
+	}
+}
+
+// Update adds the next price
+func (s *StochRSI) Update(price float64) {
+	s.next(price, true)
+}
+
+// Peek returns the Stochastic RSI as if price were the next one, without adding it
+func (s *StochRSI) Peek(price float64) StochRSIValue {
+	return s.next(price, false)
+}
+
+// next computes the value after price, adding it when commit is set. It is neutral until
+// the RSI range covers a full period.
+func (s *StochRSI) next(price float64, commit bool) StochRSIValue {
+	var rsi float64
+	if commit {
+		s.rsi.Update(price)
+		rsi = s.rsi.Value()
+	} else {
+		rsi = s.rsi.Peek(price)
+	}
+
+	// Only RSI values past its warm-up enter the range
+	ready := s.rsi.count > s.rsi.period
+	if !commit {
+		ready = s.rsi.count >= s.rsi.period
+	}
+	if !ready {
+		return StochRSIValue{K: 50, D: 50}
+	}
+
+	rsis := s.rsis.with(rsi)
+	if commit {
+		s.rsis.push(rsi)
+	}
+	if len(rsis) < s.rsis.size {
+		return StochRSIValue{K: 50, D: 50}
+	}
+
+	low, high := rsis[0], rsis[0]
+	for _, value := range rsis[1:] {
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+	raw := 50.0
+	if high > low {
+		raw = (rsi - low) / (high - low) * 100
+	}
+
+	k := mean(s.raw.with(raw))
+	d := mean(s.k.with(k))
+	if commit {
+		s.raw.push(raw)
+		s.k.push(k)
+	}
+	return StochRSIValue{K: k, D: d}
+}
+
+// DMIValue holds the Directional Movement Index lines and the ADX
+type DMIValue struct {
+	PlusDI  float64
+	MinusDI float64
+	ADX     float64
+}
+
+// DMI is Wilder's Directional Movement Index with the Average Directional Index, updated one
+// candle at a time. ADX measures trend strength regardless of direction.
+type DMI struct {
+	period   int
+	prev     models.Candle
+	count    int // Candles seen
+	trSum    float64
+	plusSum  float64
+	minusSum float64
+	dxCount  int
+	adx      float64
+	value    DMIValue
+}
+
+// NewDMI creates a DMI and ADX over period candles
+func NewDMI(period int) *DMI {
+	if period < 1 {
+		period = 1
+	}
+	return &DMI{period: period}
+}
+
+// Update adds the next candle
+func (d *DMI) Update(candle models.Candle) {
+	d.trSum, d.plusSum, d.minusSum, d.dxCount, d.adx, d.value = d.next(candle)
+	d.prev = candle
+	d.count++
+}
+
+// Peek returns the DMI as if candle were the next one, without adding it
+func (d *DMI) Peek(candle models.Candle) DMIValue {
+	_, _, _, _, _, value := d.next(candle)
+	return value
+}
+
+// next returns the smoothed sums, ADX state and value after candle
+func (d *DMI) next(candle models.Candle) (float64, float64, float64, int, float64, DMIValue) {
+	if d.count == 0 {
+		return 0, 0, 0, 0, 0, DMIValue{}
+	}
+
+	upMove := candle.High - d.prev.High
+	downMove := d.prev.Low - candle.Low
+	var plusDM, minusDM float64
+	if upMove > downMove && upMove > 0 {
+		plusDM = upMove
+	}
+	if downMove > upMove && downMove > 0 {
+		minusDM = downMove
+	}
+	tr := trueRange(candle, d.prev.Close, true)
+
+	// Movements are summed over the first period, then smoothed by Wilder's method
+	smoothing := float64(d.period)
+	trSum, plusSum, minusSum := d.trSum+tr, d.plusSum+plusDM, d.minusSum+minusDM
+	if d.count > d.period {
+		trSum = d.trSum - d.trSum/smoothing + tr
+		plusSum = d.plusSum - d.plusSum/smoothing + plusDM
+		minusSum = d.minusSum - d.minusSum/smoothing + minusDM
+	}
+	if d.count < d.period {
+		return trSum, plusSum, minusSum, 0, 0, DMIValue{}
+	}
+
+	var value DMIValue
+	if trSum > 0 {
+		value.PlusDI = 100 * plusSum / trSum
+		value.MinusDI = 100 * minusSum / trSum
+	}
+	var dx float64
+	if total := value.PlusDI + value.MinusDI; total > 0 {
+		dx = 100 * math.Abs(value.PlusDI-value.MinusDI) / total
+	}
+
+	// ADX averages the first period DX values, then smooths them
+	dxCount := d.dxCount + 1
+	adx := (d.adx*float64(dxCount-1) + dx) / float64(dxCount)
+	if dxCount > d.period {
+		dxCount = d.period + 1
+		adx = (d.adx*(smoothing-1) + dx) / smoothing
+	}
+	value.ADX = adx
+	return trSum, plusSum, minusSum, dxCount, adx, value
+}
+
+// Value returns the current DMI
+func (d *DMI) Value() DMIValue {
+	return d.value
+}
+
+// OBV is the On-Balance Volume, the running total of volume signed by the close direction
+type OBV struct {
+	prevClose float64
+	count     int
+	value     float64
+}
+
+// NewOBV creates an OBV starting at zero
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+// Update adds the next candle
+func (o *OBV) Update(candle models.Candle) {
+	o.value = o.Peek(candle)
+	o.prevClose = candle.Close
+	o.count++
+}
+
+// Peek returns the OBV as if candle were the next one, without adding it
+func (o *OBV) Peek(candle models.Candle) float64 {
+	switch {
+	case o.count == 0:
+		return o.value
+	case candle.Close > o.prevClose:
+		return o.value + candle.Volume
+	case candle.Close < o.prevClose:
+		return o.value - candle.Volume
+	}
+	return o.value
+}
+
+// Value returns the current OBV
+func (o *OBV) Value() float64 {
+	return o.value
+}
@@ -0,0 +1,65 @@
+package technical
+
+import (
+	"testing"
+
+	"trading-engine/models"
+)
+
+func TestDMI(t *testing.T) {
+	series := []models.Candle{
+		candle(10, 8, 9),
+		candle(12, 9, 11),  // +DM 2, TR 3
+		candle(13, 10, 12), // +DM 1, TR 3
+		candle(12, 8, 9),   // -DM 2, TR 4
+		candle(11, 6, 7),   // -DM 2, TR 5
+	}
+
+	tests := []struct {
+		name    string
+		candles int
+		want    DMIValue
+	}{
+		{name: "no movement from a single candle", candles: 1},
+		{name: "movements summed before period moves", candles: 2},
+		{
+			name:    "first DX seeds the ADX",
+			candles: 3,
+			want:    DMIValue{PlusDI: 50, MinusDI: 0, ADX: 100},
+		},
+		{
+			name:    "ADX averages the first period DX values",
+			candles: 4,
+			want:    DMIValue{PlusDI: 150.0 / 7, MinusDI: 200.0 / 7, ADX: 400.0 / 7},
+		},
+		{
+			name:    "Wilder smoothing of the sums and the ADX",
+			candles: 5,
+			want:    DMIValue{PlusDI: 75 / 8.5, MinusDI: 300 / 8.5, ADX: 410.0 / 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dmi := NewDMI(2)
+			for _, c := range series[:tt.candles-1] {
+				dmi.Update(c)
+			}
+
+			last := series[tt.candles-1]
+			before := dmi.Value()
+			peeked := dmi.Peek(last)
+			if dmi.Value() != before {
+				t.Errorf("Peek changed the value from %+v to %+v", before, dmi.Value())
+			}
+
+			dmi.Update(last)
+			for _, got := range []DMIValue{peeked, dmi.Value()} {
+				if !almostEqual(got.PlusDI, tt.want.PlusDI) || !almostEqual(got.MinusDI, tt.want.MinusDI) ||
+					!almostEqual(got.ADX, tt.want.ADX) {
+					t.Errorf("DMI = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
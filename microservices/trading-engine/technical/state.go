@@ -0,0 +1,156 @@
+package technical
+
+import (
+	"sort"
+	"sync"
+
+	"trading-engine/models"
+)
+
+// seriesState holds the incremental indicators of one symbol and timeframe. Closed candles
+// are added once, so each analysis only updates the indicators with the candles closed since
+// the previous one and peeks at the candle in progress.
+type seriesState struct {
+	mu       sync.Mutex
+	lastTime int64 // Open time of the last candle added
+	count    int
+	rsi      *RSI
+	ema9     *EMA
+	ema21    *EMA
+	ema50    *EMA
+	ema200   *EMA
+	macd     *MACD
+	atr      *ATR
+	bands    *Bollinger
+	stochRSI *StochRSI
+	dmi      *DMI
+	obv      *OBV
+	trend    *SuperTrend
+}
+
+// newSeriesState creates empty indicators with the configured periods
+func newSeriesState(config *Config) *seriesState {
+	state := &seriesState{}
+	state.reset(config)
+	return state
+}
+
+// reset empties the indicators
+func (s *seriesState) reset(config *Config) {
+	s.lastTime = 0
+	s.count = 0
+	s.rsi = NewRSI(config.RSIPeriod)
+	s.ema9 = NewEMA(config.EMA9Period)
+	s.ema21 = NewEMA(config.EMA21Period)
+	s.ema50 = NewEMA(config.EMA50Period)
+	s.ema200 = NewEMA(config.EMA200Period)
+	s.macd = NewMACD(12, 26, 9)
+	s.atr = NewATR(14)
+	s.bands = NewBollinger(20, 2)
+	s.stochRSI = NewStochRSI(14, 14, 3, 3)
+	s.dmi = NewDMI(14)
+	s.obv = NewOBV()
+	s.trend = NewSuperTrend(10, 3)
+}
+
+// add updates the indicators with a closed candle
+func (s *seriesState) add(candle models.Candle) {
+	s.rsi.Update(candle.Close)
+	s.ema9.Update(candle.Close)
+	s.ema21.Update(candle.Close)
+	s.ema50.Update(candle.Close)
+	s.ema200.Update(candle.Close)
+	s.macd.Update(candle.Close)
+	s.atr.Update(candle)
+	s.bands.Update(candle.Close)
+	s.stochRSI.Update(candle.Close)
+	s.dmi.Update(candle)
+	s.obv.Update(candle)
+	s.trend.Update(candle)
+	s.lastTime = candle.Time
+	s.count++
+}
+
+// pending returns the closed candles not added yet, or false when the candles no longer
+// continue the series, e.g. after the buffer was reloaded
+func (s *seriesState) pending(closed []models.Candle) ([]models.Candle, bool) {
+	if s.count == 0 {
+		return closed, true
+	}
+
+	next := sort.Search(len(closed), func(i int) bool {
+		return closed[i].Time > s.lastTime
+	})
+	if next == 0 || closed[next-1].Time != s.lastTime {
+		return nil, false
+	}
+	return closed[next:], true
+}
+
+// indicators returns the incremental indicators at the candle in progress
+func (s *seriesState) indicators(current models.Candle) *Indicators {
+	macd := s.macd.Peek(current.Close)
+	prevHist := s.macd.Value().Histogram
+	if !s.macd.Ready() {
+		prevHist = macd.Histogram // No crossover without an earlier candle
+	}
+
+	bands := s.bands.Peek(current.Close)
+	stochRSI := s.stochRSI.Peek(current.Close)
+	dmi := s.dmi.Peek(current)
+	trend := s.trend.Peek(current)
+
+	return &Indicators{
+		RSI:          s.rsi.Peek(current.Close),
+		EMA9:         s.ema9.Peek(current.Close),
+		EMA21:        s.ema21.Peek(current.Close),
+		EMA50:        s.ema50.Peek(current.Close),
+		EMA200:       s.ema200.Peek(current.Close),
+		MACD:         macd.MACD,
+		MACDSignal:   macd.Signal,
+		MACDHist:     macd.Histogram,
+		MACDPrevHist: prevHist,
+		ATR:          s.atr.Peek(current),
+		BBUpper:      bands.Upper,
+		BBMiddle:     bands.Middle,
+		BBLower:      bands.Lower,
+		BBBandwidth:  bands.Bandwidth,
+		BBPercentB:   bands.PercentB,
+		StochRSIK:    stochRSI.K,
+		StochRSID:    stochRSI.D,
+		ADX:          dmi.ADX,
+		PlusDI:       dmi.PlusDI,
+		MinusDI:      dmi.MinusDI,
+		OBV:          s.obv.Peek(current),
+		SuperTrend:   trend.Value,
+		SuperTrendUp: trend.Uptrend,
+	}
+}
+
+// seriesIndicators brings the state of a symbol and timeframe up to date with the candles,
+// all but the last of which are closed, and returns the indicators at the last candle
+func (a *Analyzer) seriesIndicators(key string, candles []models.Candle) *Indicators {
+	a.mu.Lock()
+	state, exists := a.states[key]
+	if !exists {
+		state = newSeriesState(a.config)
+		a.states[key] = state
+	}
+	a.mu.Unlock()
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	closed := candles[:len(candles)-1]
+	pending, ok := state.pending(closed)
+	if !ok {
+		// Rebuild from the whole buffer when it no longer continues the series
+		state.reset(a.config)
+		pending = closed
+	}
+	for _, candle := range pending {
+		state.add(candle)
+	}
+
+	return state.indicators(candles[len(candles)-1])
+}
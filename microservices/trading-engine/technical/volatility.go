@@ -0,0 +1,240 @@
+package technical
+
+import (
+	"math"
+
+	"trading-engine/models"
+)
+
+// window holds the latest values of a fixed size series
+type window struct {
+	size   int
+	values []float64
+}
+
+// newWindow creates a window of size values
+func newWindow(size int) *window {
+	if size < 1 {
+		size = 1
+	}
+	return &window{size: size, values: make([]float64, 0, size)}
+}
+
+// push adds a value, dropping the oldest one once the window is full
+func (w *window) push(value float64) {
+	if len(w.values) == w.size {
+		copy(w.values, w.values[1:])
+		w.values = w.values[:w.size-1]
+	}
+	w.values = append(w.values, value)
+}
+
+// with returns the values the window would hold after pushing value, without pushing it
+func (w *window) with(value float64) []float64 {
+	start := 0
+	if len(w.values) == w.size {
+		start = 1
+	}
+	values := make([]float64, 0, w.size)
+	values = append(values, w.values[start:]...)
+	return append(values, value)
+}
+
+// full reports whether the window holds size values
+func (w *window) full() bool {
+	return len(w.values) == w.size
+}
+
+// mean returns the average of values
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// trueRange returns the true range of a candle given the previous close, or its high-low
+// range for the first candle of a series
+func trueRange(candle models.Candle, prevClose float64, hasPrev bool) float64 {
+	tr := candle.High - candle.Low
+	if hasPrev {
+		tr = math.Max(tr, math.Max(math.Abs(candle.High-prevClose), math.Abs(candle.Low-prevClose)))
+	}
+	return tr
+}
+
+// ATR is the Average True Range with Wilder smoothing, updated one candle at a time. The
+// first value is the SMA of the first period true ranges.
+type ATR struct {
+	period    int
+	prevClose float64
+	count     int
+	value     float64
+}
+
+// NewATR creates an ATR over period candles
+func NewATR(period int) *ATR {
+	if period < 1 {
+		period = 1
+	}
+	return &ATR{period: period}
+}
+
+// Update adds the next candle
+func (a *ATR) Update(candle models.Candle) {
+	a.value = a.Peek(candle)
+	a.prevClose = candle.Close
+	a.count++
+}
+
+// Peek returns the ATR as if candle were the next one, without adding it
+func (a *ATR) Peek(candle models.Candle) float64 {
+	tr := trueRange(candle, a.prevClose, a.count > 0)
+	if n := a.count + 1; n <= a.period {
+		return (a.value*float64(n-1) + tr) / float64(n)
+	}
+	period := float64(a.period)
+	return (a.value*(period-1) + tr) / period
+}
+
+// Value returns the current ATR
+func (a *ATR) Value() float64 {
+	return a.value
+}
+
+// Ready reports whether the ATR covers period candles
+func (a *ATR) Ready() bool {
+	return a.count >= a.period
+}
+
+// BollingerValue holds the Bollinger Bands of a candle. Bandwidth is the distance between
+// the bands relative to the middle band, PercentB the position of the close between them.
+type BollingerValue struct {
+	Upper     float64
+	Middle    float64
+	Lower     float64
+	Bandwidth float64
+	PercentB  float64
+}
+
+// Bollinger computes Bollinger Bands, an SMA of closes with bands a multiple of their
+// standard deviation away
+type Bollinger struct {
+	multiplier float64
+	closes     *window
+}
+
+// NewBollinger creates Bollinger Bands over period closes, multiplier deviations wide
+func NewBollinger(period int, multiplier float64) *Bollinger {
+	return &Bollinger{
+		multiplier: multiplier,
+		closes:     newWindow(period),
+	}
+}
+
+// Update adds the next close
+func (b *Bollinger) Update(price float64) {
+	b.closes.push(price)
+}
+
+// Peek returns the bands as if price were the next close, without adding it
+func (b *Bollinger) Peek(price float64) BollingerValue {
+	closes := b.closes.with(price)
+	middle := mean(closes)
+
+	var variance float64
+	for _, value := range closes {
+		variance += (value - middle) * (value - middle)
+	}
+	deviation := math.Sqrt(variance / float64(len(closes)))
+
+	bands := BollingerValue{
+		Upper:  middle + b.multiplier*deviation,
+		Middle: middle,
+		Lower:  middle - b.multiplier*deviation,
+	}
+	if middle != 0 {
+		bands.Bandwidth = (bands.Upper - bands.Lower) / middle
+	}
+	bands.PercentB = 0.5 // A flat series sits in the middle
+	if width := bands.Upper - bands.Lower; width > 0 {
+		bands.PercentB = (price - bands.Lower) / width
+	}
+	return bands
+}
+
+// SuperTrendValue holds the SuperTrend line and the direction it follows
+type SuperTrendValue struct {
+	Value   float64
+	Uptrend bool
+}
+
+// SuperTrend trails the price with a band a multiple of the ATR away, below the price in an
+// uptrend and above it in a downtrend, flipping when the close crosses the band
+type SuperTrend struct {
+	multiplier float64
+	atr        *ATR
+	upper      float64
+	lower      float64
+	uptrend    bool
+	prevClose  float64
+	count      int
+}
+
+// NewSuperTrend creates a SuperTrend over an ATR of period candles, multiplier ATRs wide
+func NewSuperTrend(period int, multiplier float64) *SuperTrend {
+	return &SuperTrend{
+		multiplier: multiplier,
+		atr:        NewATR(period),
+		uptrend:    true,
+	}
+}
+
+// Update adds the next candle
+func (s *SuperTrend) Update(candle models.Candle) {
+	s.upper, s.lower, s.uptrend = s.next(candle)
+	s.atr.Update(candle)
+	s.prevClose = candle.Close
+	s.count++
+}
+
+// Peek returns the SuperTrend as if candle were the next one, without adding it
+func (s *SuperTrend) Peek(candle models.Candle) SuperTrendValue {
+	upper, lower, uptrend := s.next(candle)
+	if uptrend {
+		return SuperTrendValue{Value: lower, Uptrend: true}
+	}
+	return SuperTrendValue{Value: upper, Uptrend: false}
+}
+
+// next returns the final bands and direction after candle
+func (s *SuperTrend) next(candle models.Candle) (float64, float64, bool) {
+	atr := s.atr.Peek(candle)
+	middle := (candle.High + candle.Low) / 2
+	upper := middle + s.multiplier*atr
+	lower := middle - s.multiplier*atr
+
+	if s.count == 0 {
+		return upper, lower, candle.Close >= middle
+	}
+
+	// The bands only tighten, unless the previous close broke through them
+	if upper > s.upper && s.prevClose <= s.upper {
+		upper = s.upper
+	}
+	if lower < s.lower && s.prevClose >= s.lower {
+		lower = s.lower
+	}
+
+	uptrend := s.uptrend
+	if uptrend && candle.Close < lower {
+		uptrend = false
+	} else if !uptrend && candle.Close > upper {
+		uptrend = true
+	}
+	return upper, lower, uptrend
+}
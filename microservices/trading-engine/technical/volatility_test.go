@@ -0,0 +1,67 @@
+package technical
+
+import (
+	"math"
+	"testing"
+
+	"trading-engine/models"
+)
+
+// candle returns a candle with the given high, low and close
+func candle(high, low, closePrice float64) models.Candle {
+	return models.Candle{Open: closePrice, High: high, Low: low, Close: closePrice}
+}
+
+// almostEqual reports whether two indicator values match up to rounding
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestATR(t *testing.T) {
+	series := []models.Candle{
+		candle(10, 8, 9),   // TR 2, the high-low range of the first candle
+		candle(11, 9, 10),  // TR 2
+		candle(13, 10, 12), // TR 3
+		candle(12, 7, 8),   // TR 5
+		candle(20, 18, 19), // TR 12, the gap from the previous close
+	}
+
+	tests := []struct {
+		name      string
+		candles   int
+		want      float64
+		wantReady bool
+	}{
+		{name: "first candle", candles: 1, want: 2},
+		{name: "average before period candles", candles: 2, want: 2},
+		{name: "seeded with the average of period true ranges", candles: 3, want: 7.0 / 3, wantReady: true},
+		{name: "Wilder smoothing", candles: 4, want: 29.0 / 9, wantReady: true},
+		{name: "gap counts in the true range", candles: 5, want: 166.0 / 27, wantReady: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atr := NewATR(3)
+			for _, c := range series[:tt.candles-1] {
+				atr.Update(c)
+			}
+
+			last := series[tt.candles-1]
+			before := atr.Value()
+			if got := atr.Peek(last); !almostEqual(got, tt.want) {
+				t.Errorf("Peek = %v, want %v", got, tt.want)
+			}
+			if atr.Value() != before {
+				t.Errorf("Peek changed the value from %v to %v", before, atr.Value())
+			}
+
+			atr.Update(last)
+			if got := atr.Value(); !almostEqual(got, tt.want) {
+				t.Errorf("Value = %v, want %v", got, tt.want)
+			}
+			if atr.Ready() != tt.wantReady {
+				t.Errorf("Ready = %v, want %v", atr.Ready(), tt.wantReady)
+			}
+		})
+	}
+}
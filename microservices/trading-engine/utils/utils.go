@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/rand"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"trading-engine/clock"
 )
 
 // ParseFloat safely parses a string to float64 with error handling
@@ -38,6 +43,32 @@ func RoundToDecimals(value float64, decimals int) float64 {
 	return math.Round(value*multiplier) / multiplier
 }
 
+// StepPrecision returns the number of decimals in a tick or step size such as 0.001
+func StepPrecision(step float64) int {
+	formatted := strconv.FormatFloat(step, 'f', -1, 64)
+	if dot := strings.IndexByte(formatted, '.'); dot >= 0 {
+		return len(formatted) - dot - 1
+	}
+	return 0
+}
+
+// FloorToStep rounds a value down to a multiple of step
+func FloorToStep(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	// Rounding the ratio first absorbs float error such as 0.3/0.1 = 2.9999999999999996
+	return RoundToDecimals(math.Floor(RoundToDecimals(value/step, 8))*step, StepPrecision(step))
+}
+
+// CeilToStep rounds a value up to a multiple of step
+func CeilToStep(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	return RoundToDecimals(math.Ceil(RoundToDecimals(value/step, 8))*step, StepPrecision(step))
+}
+
 // CalculatePercentageChange calculates percentage change between two values
 func CalculatePercentageChange(oldValue, newValue float64) float64 {
 	if oldValue == 0 {
@@ -88,9 +119,28 @@ func IsValidSymbol(symbol string) bool {
 	return true
 }
 
+// lastTradeID guards against duplicate trade IDs when the clock does not move between calls
+var (
+	lastTradeID   int64
+	lastTradeIDMu sync.Mutex
+)
+
 // GenerateTradeID generates a unique trade ID
 func GenerateTradeID(symbol string) string {
-	return fmt.Sprintf("%s_%d", symbol, time.Now().UnixNano())
+	return GenerateTradeIDAt(symbol, time.Now())
+}
+
+// GenerateTradeIDAt generates a unique trade ID from the given time
+func GenerateTradeIDAt(symbol string, t time.Time) string {
+	lastTradeIDMu.Lock()
+	id := t.UnixNano()
+	if id <= lastTradeID {
+		id = lastTradeID + 1
+	}
+	lastTradeID = id
+	lastTradeIDMu.Unlock()
+
+	return fmt.Sprintf("%s_%d", symbol, id)
 }
 
 // TimeoutContext creates a context with timeout
@@ -100,6 +150,11 @@ func TimeoutContext(timeout time.Duration) (context.Context, context.CancelFunc)
 
 // RetryWithBackoff executes a function with exponential backoff retry
 func RetryWithBackoff(ctx context.Context, maxRetries int, baseDelay time.Duration, fn func() error) error {
+	return RetryWithBackoffClock(ctx, clock.New(), maxRetries, baseDelay, fn)
+}
+
+// RetryWithBackoffClock executes a function with exponential backoff retry, waiting on the given clock
+func RetryWithBackoffClock(ctx context.Context, clk clock.Clock, maxRetries int, baseDelay time.Duration, fn func() error) error {
 	var err error
 	for i := 0; i < maxRetries; i++ {
 		select {
@@ -117,14 +172,14 @@ func RetryWithBackoff(ctx context.Context, maxRetries int, baseDelay time.Durati
 			break
 		}
 
-		// Exponential backoff with jitter
+		// Exponential backoff with up to 10% jitter either way
 		delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(i)))
-		jitter := time.Duration(float64(delay) * 0.1 * float64(2*time.Now().UnixNano()%2-1))
+		jitter := time.Duration(float64(delay) * 0.1 * (rand.Float64()*2 - 1))
 
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(delay + jitter):
+		case <-clk.After(delay + jitter):
 		}
 	}
 	return err
@@ -198,8 +253,12 @@ func IsMarketOpen() bool {
 
 // GetTradingSession returns the current trading session
 func GetTradingSession() string {
-	now := time.Now().UTC()
-	hour := now.Hour()
+	return GetTradingSessionAt(time.Now())
+}
+
+// GetTradingSessionAt returns the trading session at the given time
+func GetTradingSessionAt(t time.Time) string {
+	hour := t.UTC().Hour()
 
 	switch {
 	case hour >= 0 && hour < 8:
@@ -229,7 +288,12 @@ func FormatPercentage(pct float64) string {
 
 // IsWeekend checks if current time is weekend (not relevant for crypto but useful for logging)
 func IsWeekend() bool {
-	weekday := time.Now().Weekday()
+	return IsWeekendAt(time.Now())
+}
+
+// IsWeekendAt checks if the given time falls on a weekend
+func IsWeekendAt(t time.Time) bool {
+	weekday := t.Weekday()
 	return weekday == time.Saturday || weekday == time.Sunday
 }
 
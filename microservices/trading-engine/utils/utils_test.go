@@ -0,0 +1,77 @@
+package utils
+
+import "testing"
+
+// Operands kept in variables, constant expressions such as 0.1 + 0.2 are evaluated exactly
+var (
+	tenth      = 0.1
+	nineTenths = 0.9
+)
+
+func TestFloorToStep(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		step  float64
+		want  float64
+	}{
+		{name: "ratio with float error", value: 0.3, step: 0.1, want: 0.3}, // 0.3/0.1 = 2.9999999999999996
+		{name: "float error below a multiple", value: 1 - nineTenths, step: 0.1, want: 0.1},
+		{name: "float error above a multiple", value: tenth + 0.2, step: 0.1, want: 0.3},
+		{name: "between multiples", value: 1.23456, step: 0.001, want: 1.234},
+		{name: "below the step", value: 0.0004, step: 0.001, want: 0},
+		{name: "integer step", value: 17.9, step: 5, want: 15},
+		{name: "fine step", value: 0.123456789, step: 0.00000001, want: 0.12345678},
+		{name: "no step", value: 1.23456, step: 0, want: 1.23456},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FloorToStep(tt.value, tt.step); got != tt.want {
+				t.Errorf("FloorToStep(%v, %v) = %v, want %v", tt.value, tt.step, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCeilToStep(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		step  float64
+		want  float64
+	}{
+		{name: "ratio with float error", value: 0.3, step: 0.1, want: 0.3},
+		{name: "float error above a multiple", value: tenth + 0.2, step: 0.1, want: 0.3}, // 0.30000000000000004
+		{name: "float error below a multiple", value: 1 - nineTenths, step: 0.1, want: 0.1},
+		{name: "between multiples", value: 1.23412, step: 0.001, want: 1.235},
+		{name: "tick size", value: 30000.015, step: 0.01, want: 30000.02},
+		{name: "no step", value: 1.23456, step: 0, want: 1.23456},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CeilToStep(tt.value, tt.step); got != tt.want {
+				t.Errorf("CeilToStep(%v, %v) = %v, want %v", tt.value, tt.step, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepPrecision(t *testing.T) {
+	tests := []struct {
+		step float64
+		want int
+	}{
+		{step: 1, want: 0},
+		{step: 0.1, want: 1},
+		{step: 0.001, want: 3},
+		{step: 0.00000001, want: 8},
+	}
+
+	for _, tt := range tests {
+		if got := StepPrecision(tt.step); got != tt.want {
+			t.Errorf("StepPrecision(%v) = %d, want %d", tt.step, got, tt.want)
+		}
+	}
+}